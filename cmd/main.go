@@ -3,14 +3,18 @@ package main
 import (
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/Alvarras/dompet-g0/internal/config"
 	"github.com/Alvarras/dompet-g0/internal/controllers"
-	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/mailer"
+	"github.com/Alvarras/dompet-g0/internal/migrations"
 	"github.com/Alvarras/dompet-g0/internal/repositories"
 	"github.com/Alvarras/dompet-g0/internal/routes"
 	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/Alvarras/dompet-g0/internal/webhook"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"gorm.io/driver/mysql"
@@ -41,8 +45,13 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Auto migrate database
-	if err := db.AutoMigrate(&models.User{}, &models.Budget{}, &models.Expense{}); err != nil {
+	// Keep every workspace-scoped query inside its workspace
+	if err := repositories.RegisterWorkspaceScope(db); err != nil {
+		log.Fatalf("Failed to register workspace scope: %v", err)
+	}
+
+	// Migrate database
+	if err := migrations.Run(db); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
@@ -50,20 +59,176 @@ func main() {
 	userRepo := repositories.NewUserRepository(db)
 	budgetRepo := repositories.NewBudgetRepository(db)
 	expenseRepo := repositories.NewExpenseRepository(db)
+	budgetHistoryRepo := repositories.NewBudgetHistoryRepository(db)
+	categoryRepo := repositories.NewCategoryRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
+	incomeRepo := repositories.NewIncomeRepository(db)
+	transferRepo := repositories.NewTransferRepository(db)
+	importRuleRepo := repositories.NewImportRuleRepository(db)
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(db)
+	revokedTokenRepo := repositories.NewRevokedTokenRepository(db)
+	userTokenRepo := repositories.NewUserTokenRepository(db)
+	recoveryCodeRepo := repositories.NewRecoveryCodeRepository(db)
+	loginAttemptRepo := repositories.NewLoginAttemptRepository(db)
+	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+	budgetMemberRepo := repositories.NewBudgetMemberRepository(db)
+	budgetInvitationRepo := repositories.NewBudgetInvitationRepository(db)
+	workspaceRepo := repositories.NewWorkspaceRepository(db)
+	workspaceMemberRepo := repositories.NewWorkspaceMemberRepository(db)
+	expenseApprovalRepo := repositories.NewExpenseApprovalRepository(db)
+	recurringExpenseRepo := repositories.NewRecurringExpenseRepository(db)
+	budgetAlertRepo := repositories.NewBudgetAlertRepository(db)
+
+	// Load JWT signing and verification keys
+	var jwtKeys *utils.KeySet
+	if cfg.JWT.Algorithm == utils.AlgorithmHS256 {
+		jwtKeys, err = utils.NewHMACKeySet(cfg.JWT.Secret)
+	} else {
+		jwtKeys, err = utils.LoadKeySet(cfg.JWT.Algorithm, cfg.JWT.SigningKeyFile, cfg.JWT.KeyID, strings.Split(cfg.JWT.VerificationKeyFiles, ","))
+	}
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
+	mailSender, err := mailer.New(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Failed to set up mailer: %v", err)
+	}
 
 	// Initialize services
-	jwtDuration, _ := time.ParseDuration(cfg.JWT.Expiration)
-	authService := services.NewAuthService(userRepo, cfg.JWT.Secret, jwtDuration)
-	budgetService := services.NewBudgetService(budgetRepo)
-	expenseService := services.NewExpenseService(expenseRepo, budgetRepo)
+	jwtDuration, err := time.ParseDuration(cfg.JWT.Expiration)
+	if err != nil {
+		log.Fatalf("Invalid JWT_EXPIRATION: %v", err)
+	}
+	refreshDuration, err := time.ParseDuration(cfg.JWT.RefreshExpiration)
+	if err != nil {
+		log.Fatalf("Invalid JWT_REFRESH_EXPIRATION: %v", err)
+	}
+	unverifiedPolicy := services.UnverifiedPolicy(cfg.Auth.UnverifiedPolicy)
+	if !unverifiedPolicy.Valid() {
+		log.Fatalf("Invalid AUTH_UNVERIFIED_POLICY: %q", cfg.Auth.UnverifiedPolicy)
+	}
+	loginPolicy := services.LoginPolicy{
+		MaxFailures: cfg.Auth.LoginMaxFailures,
+		IPThreshold: cfg.Auth.LoginIPThreshold,
+	}
+	if loginPolicy.LockoutDuration, err = time.ParseDuration(cfg.Auth.LoginLockoutDuration); err != nil {
+		log.Fatalf("Invalid AUTH_LOGIN_LOCKOUT_DURATION: %v", err)
+	}
+	if loginPolicy.Window, err = time.ParseDuration(cfg.Auth.LoginWindow); err != nil {
+		log.Fatalf("Invalid AUTH_LOGIN_WINDOW: %v", err)
+	}
+	if loginPolicy.BackoffBase, err = time.ParseDuration(cfg.Auth.LoginBackoffBase); err != nil {
+		log.Fatalf("Invalid AUTH_LOGIN_BACKOFF_BASE: %v", err)
+	}
+	if loginPolicy.BackoffMax, err = time.ParseDuration(cfg.Auth.LoginBackoffMax); err != nil {
+		log.Fatalf("Invalid AUTH_LOGIN_BACKOFF_MAX: %v", err)
+	}
+	webhookPolicy := services.WebhookPolicy{
+		MaxAttempts: cfg.Webhook.MaxAttempts,
+	}
+	if webhookPolicy.Timeout, err = time.ParseDuration(cfg.Webhook.Timeout); err != nil {
+		log.Fatalf("Invalid WEBHOOK_TIMEOUT: %v", err)
+	}
+	if webhookPolicy.BackoffBase, err = time.ParseDuration(cfg.Webhook.BackoffBase); err != nil {
+		log.Fatalf("Invalid WEBHOOK_BACKOFF_BASE: %v", err)
+	}
+	if webhookPolicy.BackoffMax, err = time.ParseDuration(cfg.Webhook.BackoffMax); err != nil {
+		log.Fatalf("Invalid WEBHOOK_BACKOFF_MAX: %v", err)
+	}
+	authService := services.NewAuthService(db, userRepo, refreshTokenRepo, revokedTokenRepo, userTokenRepo, recoveryCodeRepo, loginAttemptRepo, mailSender, jwtKeys, jwtDuration, refreshDuration, cfg.Mail.LinkBaseURL, unverifiedPolicy, loginPolicy)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	twoFactorService := services.NewTwoFactorService(db, userRepo, recoveryCodeRepo, authService, cfg.Auth.TOTPIssuer)
+	workspaceService := services.NewWorkspaceService(db, workspaceRepo, workspaceMemberRepo, budgetMemberRepo, userRepo)
+	budgetService := services.NewBudgetService(db, budgetRepo, expenseRepo, budgetHistoryRepo, budgetMemberRepo, budgetAlertRepo)
+	budgetMemberService := services.NewBudgetMemberService(db, budgetRepo, budgetMemberRepo, budgetInvitationRepo, userRepo, mailSender, cfg.Mail.LinkBaseURL)
+	expenseService := services.NewExpenseService(db, expenseRepo, budgetRepo, budgetHistoryRepo, categoryRepo, tagRepo, budgetMemberRepo, expenseApprovalRepo, budgetAlertRepo)
+	categoryService := services.NewCategoryService(db, categoryRepo, expenseRepo, tagRepo)
+	reportService := services.NewReportService(expenseRepo)
+	transactionService := services.NewTransactionService(db, expenseService, expenseRepo, budgetRepo, budgetHistoryRepo, incomeRepo, transferRepo, budgetMemberRepo, budgetAlertRepo)
+	profileService := services.NewProfileService(db, userRepo, userTokenRepo, loginAttemptRepo, workspaceRepo, workspaceMemberRepo, authService, transactionService, mailSender, cfg.Mail.LinkBaseURL)
+	importService := services.NewImportService(expenseService, expenseRepo, budgetRepo, categoryRepo, importRuleRepo, budgetMemberRepo)
+	exportService := services.NewExportService(budgetRepo, expenseRepo)
+	recurringExpenseService := services.NewRecurringExpenseService(db, recurringExpenseRepo, budgetRepo, categoryRepo, budgetMemberRepo, expenseService, workspaceService)
+	budgetAlertService := services.NewBudgetAlertService(db, budgetAlertRepo, budgetRepo, budgetMemberRepo, webhook.NewSender(webhookPolicy.Timeout), webhookPolicy)
+	backupService := services.NewBackupService(db, userRepo, budgetRepo, expenseRepo, budgetHistoryRepo, categoryRepo, tagRepo, incomeRepo, transferRepo, importRuleRepo, budgetAlertRepo)
+
+	// Roll periodic budgets over and purge expired tokens, invitations and old sign-ins in the background
+	rolloverInterval, err := time.ParseDuration(cfg.Budget.RolloverInterval)
+	if err != nil {
+		log.Fatalf("Invalid BUDGET_ROLLOVER_INTERVAL: %v", err)
+	}
+	go func() {
+		ticker := time.NewTicker(rolloverInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			if _, err := budgetService.RolloverDueBudgets(now); err != nil {
+				log.Printf("Failed to roll over budgets: %v", err)
+			}
+			if _, err := authService.PurgeExpiredTokens(now); err != nil {
+				log.Printf("Failed to purge expired tokens: %v", err)
+			}
+			if _, err := budgetMemberService.PurgeExpiredInvitations(now); err != nil {
+				log.Printf("Failed to purge expired invitations: %v", err)
+			}
+		}
+	}()
+
+	// Book due recurring expenses in the background
+	recurringInterval, err := time.ParseDuration(cfg.Budget.RecurringInterval)
+	if err != nil {
+		log.Fatalf("Invalid BUDGET_RECURRING_INTERVAL: %v", err)
+	}
+	go func() {
+		ticker := time.NewTicker(recurringInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			if _, err := recurringExpenseService.BookDueOccurrences(now); err != nil {
+				log.Printf("Failed to book recurring expenses: %v", err)
+			}
+		}
+	}()
+
+	// Send queued budget alert webhooks in the background
+	webhookInterval, err := time.ParseDuration(cfg.Webhook.Interval)
+	if err != nil {
+		log.Fatalf("Invalid WEBHOOK_INTERVAL: %v", err)
+	}
+	go func() {
+		ticker := time.NewTicker(webhookInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			if _, err := budgetAlertService.DeliverDueWebhooks(now); err != nil {
+				log.Printf("Failed to deliver webhooks: %v", err)
+			}
+		}
+	}()
 
 	// Initialize controllers
 	authController := controllers.NewAuthController(authService)
 	budgetController := controllers.NewBudgetController(budgetService)
 	expenseController := controllers.NewExpenseController(expenseService)
+	categoryController := controllers.NewCategoryController(categoryService)
+	transactionController := controllers.NewTransactionController(transactionService)
+	reportController := controllers.NewReportController(reportService)
+	importController := controllers.NewImportController(importService)
+	exportController := controllers.NewExportController(exportService)
+	backupController := controllers.NewBackupController(backupService)
+	profileController := controllers.NewProfileController(profileService)
+	twoFactorController := controllers.NewTwoFactorController(twoFactorService)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+	budgetMemberController := controllers.NewBudgetMemberController(budgetMemberService)
+	workspaceController := controllers.NewWorkspaceController(workspaceService)
+	recurringExpenseController := controllers.NewRecurringExpenseController(recurringExpenseService)
+	budgetAlertController := controllers.NewBudgetAlertController(budgetAlertService)
 
 	// Initialize Echo
 	e := echo.New()
+	if cfg.Server.TrustProxy {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	} else {
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
 
 	// Middleware
 	e.Use(middleware.Logger())
@@ -71,7 +236,7 @@ func main() {
 	e.Use(middleware.CORS())
 
 	// Setup routes
-	routes.SetupRoutes(e, cfg.JWT.Secret, authController, budgetController, expenseController)
+	routes.SetupRoutes(e, jwtKeys, authService, apiKeyService, workspaceService, authController, budgetController, expenseController, categoryController, transactionController, reportController, importController, exportController, backupController, profileController, twoFactorController, apiKeyController, budgetMemberController, workspaceController, recurringExpenseController, budgetAlertController)
 
 	// Start server
 	serverAddr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
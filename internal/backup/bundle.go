@@ -0,0 +1,112 @@
+// Package backup defines the portable JSON bundle holding all data of one
+// account. IDs in a bundle are only meaningful inside it; a restore gives
+// every row a new ID and rewrites the references.
+package backup
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
+
+// Version is the bundle format written by this build. Restoring a bundle
+// with a different version is refused.
+const Version = 1
+
+type Bundle struct {
+	Version         int             `json:"version"`
+	ExportedAt      time.Time       `json:"exported_at"`
+	User            User            `json:"user"`
+	Budgets         []Budget        `json:"budgets"`
+	BudgetHistories []BudgetHistory `json:"budget_histories"`
+	Categories      []Category      `json:"categories"`
+	Expenses        []Expense       `json:"expenses"`
+	Incomes         []Income        `json:"incomes"`
+	Transfers       []Transfer      `json:"transfers"`
+	ImportRules     []ImportRule    `json:"import_rules"`
+}
+
+type User struct {
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Budget struct {
+	ID                uuid.UUID             `json:"id"`
+	Name              string                `json:"name"`
+	Description       string                `json:"description"`
+	Amount            models.Money          `json:"amount"`
+	Spent             models.Money          `json:"spent"`
+	Period            models.BudgetPeriod   `json:"period"`
+	PeriodAnchor      *time.Time            `json:"period_anchor"`
+	PeriodDays        int                   `json:"period_days"`
+	PeriodStart       *time.Time            `json:"period_start"`
+	PeriodEnd         *time.Time            `json:"period_end"`
+	RolloverPolicy    models.RolloverPolicy `json:"rollover_policy"`
+	CarryOver         models.Money          `json:"carry_over"`
+	ApprovalThreshold models.Money          `json:"approval_threshold"`
+	CreatedAt         time.Time             `json:"created_at"`
+	DeletedAt         *time.Time            `json:"deleted_at"`
+}
+
+type BudgetHistory struct {
+	BudgetID    uuid.UUID    `json:"budget_id"`
+	PeriodStart time.Time    `json:"period_start"`
+	PeriodEnd   time.Time    `json:"period_end"`
+	Amount      models.Money `json:"amount"`
+	CarryIn     models.Money `json:"carry_in"`
+	Spent       models.Money `json:"spent"`
+	CarryOut    models.Money `json:"carry_out"`
+}
+
+type Category struct {
+	ID          uuid.UUID  `json:"id"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+}
+
+type Expense struct {
+	ID          uuid.UUID    `json:"id"`
+	BudgetID    uuid.UUID    `json:"budget_id"`
+	CategoryID  *uuid.UUID   `json:"category_id"`
+	Amount      models.Money `json:"amount"`
+	Description string       `json:"description"`
+	Date        time.Time    `json:"date"`
+	// Status is empty in bundles written before expense approval existed
+	Status    models.ExpenseStatus `json:"status,omitempty"`
+	Tags      []string             `json:"tags"`
+	CreatedAt time.Time            `json:"created_at"`
+	DeletedAt *time.Time           `json:"deleted_at"`
+}
+
+type Income struct {
+	BudgetID    *uuid.UUID   `json:"budget_id"`
+	Amount      models.Money `json:"amount"`
+	Description string       `json:"description"`
+	Date        time.Time    `json:"date"`
+	TopUpBudget bool         `json:"top_up_budget"`
+	CreatedAt   time.Time    `json:"created_at"`
+	DeletedAt   *time.Time   `json:"deleted_at"`
+}
+
+type Transfer struct {
+	FromBudgetID uuid.UUID    `json:"from_budget_id"`
+	ToBudgetID   uuid.UUID    `json:"to_budget_id"`
+	Amount       models.Money `json:"amount"`
+	Description  string       `json:"description"`
+	Date         time.Time    `json:"date"`
+	CreatedAt    time.Time    `json:"created_at"`
+	DeletedAt    *time.Time   `json:"deleted_at"`
+}
+
+type ImportRule struct {
+	Pattern    string     `json:"pattern"`
+	BudgetID   uuid.UUID  `json:"budget_id"`
+	CategoryID *uuid.UUID `json:"category_id"`
+	Priority   int        `json:"priority"`
+}
@@ -12,11 +12,18 @@ type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	JWT      JWTConfig
+	Budget   BudgetConfig
+	Mail     MailConfig
+	Auth     AuthConfig
+	Webhook  WebhookConfig
 }
 
 type ServerConfig struct {
 	Port string
 	Host string
+	// TrustProxy takes the client IP from X-Forwarded-For; only enable it
+	// behind a reverse proxy that sets the header
+	TrustProxy bool
 }
 
 type DatabaseConfig struct {
@@ -31,8 +38,56 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret     string
-	Expiration string
+	Algorithm            string
+	Secret               string
+	SigningKeyFile       string
+	KeyID                string
+	VerificationKeyFiles string
+	Expiration           string
+	RefreshExpiration    string
+}
+
+type BudgetConfig struct {
+	RolloverInterval string
+	// RecurringInterval is how often due recurring expenses are booked
+	RecurringInterval string
+}
+
+type MailConfig struct {
+	// Driver is one of log, file or smtp
+	Driver string
+	From   string
+	// LinkBaseURL is the frontend address confirmation links point to
+	LinkBaseURL  string
+	FileDir      string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+type WebhookConfig struct {
+	// Interval is how often queued webhooks are sent
+	Interval string
+	// Delivery retries, see services.WebhookPolicy
+	Timeout     string
+	MaxAttempts int
+	BackoffBase string
+	BackoffMax  string
+}
+
+type AuthConfig struct {
+	// UnverifiedPolicy is one of allow, limit or block
+	UnverifiedPolicy string
+	// TOTPIssuer is the account label shown in authenticator apps
+	TOTPIssuer string
+	// Login throttling, see services.LoginPolicy
+	LoginMaxFailures     int
+	LoginLockoutDuration string
+	LoginWindow          string
+	LoginBackoffBase     string
+	LoginBackoffMax      string
+	LoginIPThreshold     int
 }
 
 func LoadConfig() (*Config, error) {
@@ -43,8 +98,9 @@ func LoadConfig() (*Config, error) {
 
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Host: getEnv("SERVER_HOST", "localhost"),
+			Port:       getEnv("SERVER_PORT", "8080"),
+			Host:       getEnv("SERVER_HOST", "localhost"),
+			TrustProxy: getEnv("SERVER_TRUST_PROXY", "false") == "true",
 		},
 		Database: DatabaseConfig{
 			Host:      getEnv("DB_HOST", "localhost"),
@@ -57,8 +113,44 @@ func LoadConfig() (*Config, error) {
 			Loc:       getEnv("DB_LOC", "Local"),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "your-secret-key"),
-			Expiration: getEnv("JWT_EXPIRATION", "24h"),
+			Algorithm:            getEnv("JWT_ALGORITHM", "HS256"),
+			Secret:               os.Getenv("JWT_SECRET"),
+			SigningKeyFile:       os.Getenv("JWT_SIGNING_KEY_FILE"),
+			KeyID:                os.Getenv("JWT_KEY_ID"),
+			VerificationKeyFiles: os.Getenv("JWT_VERIFICATION_KEY_FILES"),
+			Expiration:           getEnv("JWT_EXPIRATION", "15m"),
+			RefreshExpiration:    getEnv("JWT_REFRESH_EXPIRATION", "720h"),
+		},
+		Budget: BudgetConfig{
+			RolloverInterval:  getEnv("BUDGET_ROLLOVER_INTERVAL", "1h"),
+			RecurringInterval: getEnv("BUDGET_RECURRING_INTERVAL", "5m"),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "Dompet <no-reply@localhost>"),
+			LinkBaseURL:  getEnv("MAIL_LINK_BASE_URL", "http://localhost:3000"),
+			FileDir:      getEnv("MAIL_FILE_DIR", "tmp/mail"),
+			SMTPHost:     os.Getenv("SMTP_HOST"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: os.Getenv("SMTP_USERNAME"),
+			SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		},
+		Auth: AuthConfig{
+			UnverifiedPolicy:     getEnv("AUTH_UNVERIFIED_POLICY", "limit"),
+			TOTPIssuer:           getEnv("AUTH_TOTP_ISSUER", "Dompet"),
+			LoginMaxFailures:     getEnvAsInt("AUTH_LOGIN_MAX_FAILURES", 5),
+			LoginLockoutDuration: getEnv("AUTH_LOGIN_LOCKOUT_DURATION", "15m"),
+			LoginWindow:          getEnv("AUTH_LOGIN_WINDOW", "15m"),
+			LoginBackoffBase:     getEnv("AUTH_LOGIN_BACKOFF_BASE", "1s"),
+			LoginBackoffMax:      getEnv("AUTH_LOGIN_BACKOFF_MAX", "5m"),
+			LoginIPThreshold:     getEnvAsInt("AUTH_LOGIN_IP_THRESHOLD", 20),
+		},
+		Webhook: WebhookConfig{
+			Interval:    getEnv("WEBHOOK_INTERVAL", "30s"),
+			Timeout:     getEnv("WEBHOOK_TIMEOUT", "10s"),
+			MaxAttempts: getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			BackoffBase: getEnv("WEBHOOK_BACKOFF_BASE", "1m"),
+			BackoffMax:  getEnv("WEBHOOK_BACKOFF_MAX", "6h"),
 		},
 	}, nil
 }
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyController struct {
+	apiKeyService *services.APIKeyService
+	validate      *validator.Validate
+}
+
+func NewAPIKeyController(apiKeyService *services.APIKeyService) *APIKeyController {
+	return &APIKeyController{
+		apiKeyService: apiKeyService,
+		validate:      validator.New(),
+	}
+}
+
+func (c *APIKeyController) CreateAPIKey(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.CreateAPIKeyRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "APIKEY_001"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "APIKEY_002"))
+	}
+
+	response, err := c.apiKeyService.CreateAPIKey(userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "invalid scope", "expiry must be in the future":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "APIKEY_003"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "APIKEY_004"))
+		}
+	}
+
+	return ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(response))
+}
+
+func (c *APIKeyController) GetAPIKeys(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	response, err := c.apiKeyService.GetAPIKeys(userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "APIKEY_005"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *APIKeyController) RevokeAPIKey(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	keyID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid api key id", "APIKEY_006"))
+	}
+
+	if err := c.apiKeyService.RevokeAPIKey(userID, keyID); err != nil {
+		if err.Error() == "api key not found" {
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "APIKEY_007"))
+		}
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "APIKEY_008"))
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -1,12 +1,17 @@
 package controllers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
 	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
 	"github.com/Alvarras/dompet-g0/internal/services"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
@@ -50,10 +55,187 @@ func (c *AuthController) Login(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_005"))
 	}
 
-	response, err := c.authService.Login(&req)
+	response, err := c.authService.Login(&req, ctx.RealIP(), ctx.Request().UserAgent())
 	if err != nil {
-		return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Email atau password salah", "AUTH_006"))
+		var blocked *services.LoginBlockedError
+		switch {
+		case errors.As(err, &blocked):
+			return loginBlocked(ctx, blocked)
+		case err.Error() == "email not verified":
+			return ctx.JSON(http.StatusForbidden, responses.NewErrorResponse(err.Error(), "AUTH_022"))
+		case err.Error() == "invalid credentials":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Email atau password salah", "AUTH_006"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "AUTH_037"))
+		}
 	}
 
 	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
 }
+
+func (c *AuthController) LoginMFA(ctx echo.Context) error {
+	var req requests.MFALoginRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_030"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_031"))
+	}
+
+	response, err := c.authService.LoginMFA(&req, ctx.RealIP(), ctx.Request().UserAgent())
+	if err != nil {
+		var blocked *services.LoginBlockedError
+		if errors.As(err, &blocked) {
+			return loginBlocked(ctx, blocked)
+		}
+		switch err.Error() {
+		case "invalid mfa token":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "AUTH_032"))
+		case "invalid code":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "AUTH_033"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "AUTH_034"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+// loginBlocked answers a throttled sign-in or one to a locked account,
+// telling the client when to try again.
+func loginBlocked(ctx echo.Context, blocked *services.LoginBlockedError) error {
+	seconds := int(math.Ceil(blocked.RetryAfter.Seconds()))
+	ctx.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	if blocked.Reason == "account temporarily locked" {
+		return ctx.JSON(http.StatusLocked, responses.NewErrorResponse(blocked.Reason, "AUTH_036"))
+	}
+	return ctx.JSON(http.StatusTooManyRequests, responses.NewErrorResponse(blocked.Reason, "AUTH_035"))
+}
+
+func (c *AuthController) Refresh(ctx echo.Context) error {
+	var req requests.RefreshTokenRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_007"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_008"))
+	}
+
+	response, err := c.authService.Refresh(&req)
+	if err != nil {
+		switch err.Error() {
+		case "invalid refresh token", "refresh token expired":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "AUTH_009"))
+		case "refresh token reuse detected":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "AUTH_010"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "AUTH_011"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *AuthController) Logout(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	jti := ctx.Get("jti").(string)
+	expiresAt := ctx.Get("token_expires_at").(time.Time)
+
+	var req requests.LogoutRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_012"))
+	}
+
+	if err := c.authService.Logout(userID, jti, expiresAt, &req); err != nil {
+		if err.Error() == "invalid refresh token" {
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_013"))
+		}
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "AUTH_014"))
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (c *AuthController) ForgotPassword(ctx echo.Context) error {
+	var req requests.ForgotPasswordRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_015"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_016"))
+	}
+
+	if err := c.authService.RequestPasswordReset(&req); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "AUTH_017"))
+	}
+
+	// Same answer whether or not the address is registered
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+func (c *AuthController) ResetPassword(ctx echo.Context) error {
+	var req requests.ResetPasswordRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_018"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_019"))
+	}
+
+	if err := c.authService.ResetPassword(&req); err != nil {
+		if err.Error() == "invalid or expired token" {
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_020"))
+		}
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "AUTH_021"))
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (c *AuthController) VerifyEmail(ctx echo.Context) error {
+	var req requests.VerifyEmailRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_023"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_024"))
+	}
+
+	response, err := c.authService.VerifyEmail(&req)
+	if err != nil {
+		if err.Error() == "invalid or expired token" {
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_025"))
+		}
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "AUTH_026"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *AuthController) ResendVerification(ctx echo.Context) error {
+	var req requests.ResendVerificationRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_027"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "AUTH_028"))
+	}
+
+	if err := c.authService.ResendVerification(&req); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "AUTH_029"))
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+func (c *AuthController) JWKS(ctx echo.Context) error {
+	// Rotated keys are picked up within minutes
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return ctx.JSON(http.StatusOK, c.authService.JWKS())
+}
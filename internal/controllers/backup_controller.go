@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/backup"
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type BackupController struct {
+	backupService *services.BackupService
+	validate      *validator.Validate
+}
+
+func NewBackupController(backupService *services.BackupService) *BackupController {
+	return &BackupController{
+		backupService: backupService,
+		validate:      validator.New(),
+	}
+}
+
+func (c *BackupController) Backup(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	bundle, err := c.backupService.InWorkspace(workspaceScope(ctx)).Backup(userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "BACKUP_001"))
+		}
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "BACKUP_002"))
+	}
+
+	filename := fmt.Sprintf("dompet-backup-%s.json", time.Now().Format("20060102"))
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return ctx.JSON(http.StatusOK, bundle)
+}
+
+func (c *BackupController) Restore(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	// Bind only binds the query string for GET and DELETE requests
+	var query requests.RestoreBackupQuery
+	if err := (&echo.DefaultBinder{}).BindQueryParams(ctx, &query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "BACKUP_003"))
+	}
+
+	if err := c.validate.Struct(query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "BACKUP_004"))
+	}
+
+	var bundle backup.Bundle
+	if err := ctx.Bind(&bundle); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "BACKUP_005"))
+	}
+
+	response, err := c.backupService.InWorkspace(workspaceScope(ctx)).Restore(userID, &bundle, query.Conflict)
+	if err != nil {
+		switch err.Error() {
+		case "unsupported backup version":
+			return ctx.JSON(http.StatusUnprocessableEntity, responses.NewErrorResponse(err.Error(), "BACKUP_006"))
+		case "invalid restore conflict":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "BACKUP_009"))
+		case "invalid budget period", "invalid rollover policy", "invalid expense status":
+			return ctx.JSON(http.StatusUnprocessableEntity, responses.NewErrorResponse(err.Error(), "BACKUP_010"))
+		case "user not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "BACKUP_001"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "BACKUP_008"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "BACKUP_007"))
+		}
+	}
+
+	return ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(response))
+}
@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type BudgetAlertController struct {
+	alertService *services.BudgetAlertService
+	validate     *validator.Validate
+}
+
+func NewBudgetAlertController(alertService *services.BudgetAlertService) *BudgetAlertController {
+	return &BudgetAlertController{
+		alertService: alertService,
+		validate:     validator.New(),
+	}
+}
+
+func (c *BudgetAlertController) CreateAlert(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	budgetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget id", "ALERT_001"))
+	}
+
+	var req requests.CreateBudgetAlertRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "ALERT_002"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "ALERT_003"))
+	}
+
+	response, err := c.alertService.InWorkspace(workspaceScope(ctx)).CreateAlert(userID, budgetID, &req)
+	if err != nil {
+		return c.alertError(ctx, err, "ALERT_004", "ALERT_005", "ALERT_006")
+	}
+
+	return ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(response))
+}
+
+func (c *BudgetAlertController) GetAlerts(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	budgetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget id", "ALERT_007"))
+	}
+
+	response, err := c.alertService.InWorkspace(workspaceScope(ctx)).GetAlerts(userID, budgetID)
+	if err != nil {
+		return c.alertError(ctx, err, "ALERT_008", "ALERT_009", "ALERT_010")
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *BudgetAlertController) UpdateAlert(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	budgetID, alertID, ok := parseAlertIDs(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget or alert id", "ALERT_011"))
+	}
+
+	var req requests.UpdateBudgetAlertRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "ALERT_012"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "ALERT_013"))
+	}
+
+	response, err := c.alertService.InWorkspace(workspaceScope(ctx)).UpdateAlert(userID, budgetID, alertID, &req)
+	if err != nil {
+		return c.alertError(ctx, err, "ALERT_014", "ALERT_015", "ALERT_016")
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *BudgetAlertController) DeleteAlert(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	budgetID, alertID, ok := parseAlertIDs(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget or alert id", "ALERT_017"))
+	}
+
+	if err := c.alertService.InWorkspace(workspaceScope(ctx)).DeleteAlert(userID, budgetID, alertID); err != nil {
+		return c.alertError(ctx, err, "ALERT_018", "ALERT_019", "ALERT_020")
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (c *BudgetAlertController) TestAlert(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	budgetID, alertID, ok := parseAlertIDs(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget or alert id", "ALERT_021"))
+	}
+
+	response, err := c.alertService.InWorkspace(workspaceScope(ctx)).TestAlert(userID, budgetID, alertID)
+	if err != nil {
+		return c.alertError(ctx, err, "ALERT_022", "ALERT_023", "ALERT_024")
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *BudgetAlertController) GetDeliveries(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	budgetID, alertID, ok := parseAlertIDs(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget or alert id", "ALERT_025"))
+	}
+
+	var query requests.WebhookDeliveryListQuery
+	if err := ctx.Bind(&query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "ALERT_026"))
+	}
+
+	if err := c.validate.Struct(query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "ALERT_027"))
+	}
+
+	response, err := c.alertService.InWorkspace(workspaceScope(ctx)).GetDeliveries(userID, budgetID, alertID, &query)
+	if err != nil {
+		return c.alertError(ctx, err, "ALERT_028", "ALERT_029", "ALERT_030")
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *BudgetAlertController) alertError(ctx echo.Context, err error, notFound, unauthorized, internal string) error {
+	switch err.Error() {
+	case "budget not found", "alert not found":
+		return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), notFound))
+	case "unauthorized":
+		return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), unauthorized))
+	case "webhook url not allowed":
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "ALERT_031"))
+	default:
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), internal))
+	}
+}
+
+func parseAlertIDs(ctx echo.Context) (uuid.UUID, uuid.UUID, bool) {
+	budgetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	alertID, err := uuid.Parse(ctx.Param("alert_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return budgetID, alertID, true
+}
@@ -35,8 +35,11 @@ func (c *BudgetController) CreateBudget(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "BUDGET_002"))
 	}
 
-	response, err := c.budgetService.CreateBudget(userID, &req)
+	response, err := c.budgetService.InWorkspace(workspaceScope(ctx)).CreateBudget(userID, &req)
 	if err != nil {
+		if err.Error() == "unauthorized" {
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "BUDGET_011"))
+		}
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "BUDGET_003"))
 	}
 
@@ -46,7 +49,7 @@ func (c *BudgetController) CreateBudget(ctx echo.Context) error {
 func (c *BudgetController) GetBudgets(ctx echo.Context) error {
 	userID := ctx.Get("user_id").(uuid.UUID)
 
-	response, err := c.budgetService.GetBudgets(userID)
+	response, err := c.budgetService.InWorkspace(workspaceScope(ctx)).GetBudgets(userID)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "BUDGET_004"))
 	}
@@ -70,7 +73,7 @@ func (c *BudgetController) UpdateBudget(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "BUDGET_007"))
 	}
 
-	response, err := c.budgetService.UpdateBudget(userID, budgetID, &req)
+	response, err := c.budgetService.InWorkspace(workspaceScope(ctx)).UpdateBudget(userID, budgetID, &req)
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "BUDGET_008"))
 	}
@@ -85,7 +88,7 @@ func (c *BudgetController) DeleteBudget(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget id", "BUDGET_009"))
 	}
 
-	if err := c.budgetService.DeleteBudget(userID, budgetID); err != nil {
+	if err := c.budgetService.InWorkspace(workspaceScope(ctx)).DeleteBudget(userID, budgetID); err != nil {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "BUDGET_010"))
 	}
 
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type BudgetMemberController struct {
+	memberService *services.BudgetMemberService
+	validate      *validator.Validate
+}
+
+func NewBudgetMemberController(memberService *services.BudgetMemberService) *BudgetMemberController {
+	return &BudgetMemberController{
+		memberService: memberService,
+		validate:      validator.New(),
+	}
+}
+
+func (c *BudgetMemberController) InviteMember(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	budgetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget id", "MEMBER_001"))
+	}
+
+	var req requests.InviteBudgetMemberRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MEMBER_002"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MEMBER_003"))
+	}
+
+	response, err := c.memberService.InWorkspace(workspaceScope(ctx)).InviteMember(userID, budgetID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "budget not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "MEMBER_004"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "MEMBER_005"))
+		case "already a member":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "MEMBER_006"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "MEMBER_007"))
+		}
+	}
+
+	return ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(response))
+}
+
+func (c *BudgetMemberController) GetMembers(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	budgetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget id", "MEMBER_008"))
+	}
+
+	response, err := c.memberService.InWorkspace(workspaceScope(ctx)).GetMembers(userID, budgetID)
+	if err != nil {
+		switch err.Error() {
+		case "budget not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "MEMBER_009"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "MEMBER_010"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "MEMBER_011"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *BudgetMemberController) UpdateMemberRole(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	budgetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget id", "MEMBER_012"))
+	}
+	memberID, err := uuid.Parse(ctx.Param("user_id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid user id", "MEMBER_013"))
+	}
+
+	var req requests.UpdateBudgetMemberRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MEMBER_014"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MEMBER_015"))
+	}
+
+	response, err := c.memberService.InWorkspace(workspaceScope(ctx)).UpdateMemberRole(userID, budgetID, memberID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "budget not found", "member not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "MEMBER_016"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "MEMBER_017"))
+		case "cannot change the owner":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MEMBER_018"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "MEMBER_019"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *BudgetMemberController) RemoveMember(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	budgetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget id", "MEMBER_020"))
+	}
+	memberID, err := uuid.Parse(ctx.Param("user_id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid user id", "MEMBER_021"))
+	}
+
+	if err := c.memberService.InWorkspace(workspaceScope(ctx)).RemoveMember(userID, budgetID, memberID); err != nil {
+		switch err.Error() {
+		case "budget not found", "member not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "MEMBER_022"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "MEMBER_023"))
+		case "cannot remove the owner":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MEMBER_024"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "MEMBER_025"))
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (c *BudgetMemberController) RevokeInvitation(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	budgetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget id", "MEMBER_026"))
+	}
+	invitationID, err := uuid.Parse(ctx.Param("invitation_id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid invitation id", "MEMBER_027"))
+	}
+
+	if err := c.memberService.InWorkspace(workspaceScope(ctx)).RevokeInvitation(userID, budgetID, invitationID); err != nil {
+		switch err.Error() {
+		case "budget not found", "invitation not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "MEMBER_028"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "MEMBER_029"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "MEMBER_030"))
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (c *BudgetMemberController) AcceptInvitation(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.AcceptInvitationRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MEMBER_031"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MEMBER_032"))
+	}
+
+	response, err := c.memberService.AcceptInvitation(userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "invalid or expired token":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MEMBER_033"))
+		case "invitation was sent to another email":
+			return ctx.JSON(http.StatusForbidden, responses.NewErrorResponse(err.Error(), "MEMBER_034"))
+		case "already a member":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "MEMBER_035"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "MEMBER_036"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type CategoryController struct {
+	categoryService *services.CategoryService
+	validate        *validator.Validate
+}
+
+func NewCategoryController(categoryService *services.CategoryService) *CategoryController {
+	return &CategoryController{
+		categoryService: categoryService,
+		validate:        validator.New(),
+	}
+}
+
+func (c *CategoryController) CreateCategory(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.CreateCategoryRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "CATEGORY_001"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "CATEGORY_002"))
+	}
+
+	response, err := c.categoryService.InWorkspace(workspaceScope(ctx)).CreateCategory(userID, &req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "CATEGORY_003"))
+	}
+
+	return ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(response))
+}
+
+func (c *CategoryController) GetCategories(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	response, err := c.categoryService.InWorkspace(workspaceScope(ctx)).GetCategories(userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "CATEGORY_004"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *CategoryController) UpdateCategory(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	categoryID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid category id", "CATEGORY_005"))
+	}
+
+	var req requests.UpdateCategoryRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "CATEGORY_006"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "CATEGORY_007"))
+	}
+
+	response, err := c.categoryService.InWorkspace(workspaceScope(ctx)).UpdateCategory(userID, categoryID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "category not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "CATEGORY_008"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "CATEGORY_009"))
+		default:
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "CATEGORY_010"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *CategoryController) DeleteCategory(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	categoryID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid category id", "CATEGORY_011"))
+	}
+
+	if err := c.categoryService.InWorkspace(workspaceScope(ctx)).DeleteCategory(userID, categoryID); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "CATEGORY_012"))
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (c *CategoryController) GetTags(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	response, err := c.categoryService.InWorkspace(workspaceScope(ctx)).GetTags(userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "CATEGORY_013"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
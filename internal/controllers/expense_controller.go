@@ -35,10 +35,10 @@ func (c *ExpenseController) CreateExpense(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_002"))
 	}
 
-	response, err := c.expenseService.CreateExpense(userID, &req)
+	response, err := c.expenseService.InWorkspace(workspaceScope(ctx)).CreateExpense(userID, &req)
 	if err != nil {
 		switch err.Error() {
-		case "budget not found":
+		case "budget not found", "category not found":
 			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "EXPENSE_002"))
 		case "unauthorized":
 			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "EXPENSE_004"))
@@ -55,9 +55,25 @@ func (c *ExpenseController) CreateExpense(ctx echo.Context) error {
 func (c *ExpenseController) GetExpenses(ctx echo.Context) error {
 	userID := ctx.Get("user_id").(uuid.UUID)
 
-	response, err := c.expenseService.GetExpenses(userID)
+	var query requests.ExpenseListQuery
+	if err := ctx.Bind(&query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_017"))
+	}
+
+	if err := c.validate.Struct(query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_019"))
+	}
+
+	response, err := c.expenseService.InWorkspace(workspaceScope(ctx)).GetExpenses(userID, &query)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "EXPENSE_004"))
+		switch err.Error() {
+		case "category not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "EXPENSE_018"))
+		case "invalid cursor":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_020"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "EXPENSE_004"))
+		}
 	}
 
 	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
@@ -70,7 +86,16 @@ func (c *ExpenseController) GetExpensesByBudget(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid budget id", "EXPENSE_005"))
 	}
 
-	response, err := c.expenseService.GetExpensesByBudget(userID, budgetID)
+	var query requests.ExpenseListQuery
+	if err := ctx.Bind(&query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_021"))
+	}
+
+	if err := c.validate.Struct(query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_022"))
+	}
+
+	response, err := c.expenseService.InWorkspace(workspaceScope(ctx)).GetExpensesByBudget(userID, budgetID, &query)
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_006"))
 	}
@@ -85,7 +110,7 @@ func (c *ExpenseController) DeleteExpense(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid expense id", "EXPENSE_007"))
 	}
 
-	if err := c.expenseService.DeleteExpense(userID, expenseID); err != nil {
+	if err := c.expenseService.InWorkspace(workspaceScope(ctx)).DeleteExpense(userID, expenseID); err != nil {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_008"))
 	}
 
@@ -108,12 +133,12 @@ func (c *ExpenseController) UpdateExpense(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_011"))
 	}
 
-	response, err := c.expenseService.UpdateExpense(userID, expenseID, &req)
+	response, err := c.expenseService.InWorkspace(workspaceScope(ctx)).UpdateExpense(userID, expenseID, &req)
 	if err != nil {
 		switch err.Error() {
 		case "expense not found":
 			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "EXPENSE_012"))
-		case "budget not found":
+		case "budget not found", "category not found":
 			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "EXPENSE_013"))
 		case "unauthorized":
 			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "EXPENSE_014"))
@@ -126,3 +151,120 @@ func (c *ExpenseController) UpdateExpense(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
 }
+
+func (c *ExpenseController) GetPendingApprovals(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var query requests.ExpenseListQuery
+	if err := ctx.Bind(&query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_043"))
+	}
+
+	if err := c.validate.Struct(query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_044"))
+	}
+
+	response, err := c.expenseService.InWorkspace(workspaceScope(ctx)).GetPendingApprovals(userID, &query)
+	if err != nil {
+		switch err.Error() {
+		case "category not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "EXPENSE_045"))
+		case "invalid cursor":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_046"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "EXPENSE_023"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *ExpenseController) ApproveExpense(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	expenseID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid expense id", "EXPENSE_024"))
+	}
+
+	var req requests.ApproveExpenseRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_025"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_026"))
+	}
+
+	response, err := c.expenseService.InWorkspace(workspaceScope(ctx)).ApproveExpense(userID, expenseID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "expense not found", "budget not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "EXPENSE_027"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "EXPENSE_028"))
+		case "expense is not pending":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "EXPENSE_029"))
+		case "insufficient budget":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_030"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "EXPENSE_031"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *ExpenseController) RejectExpense(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	expenseID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid expense id", "EXPENSE_032"))
+	}
+
+	var req requests.RejectExpenseRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_033"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPENSE_034"))
+	}
+
+	response, err := c.expenseService.InWorkspace(workspaceScope(ctx)).RejectExpense(userID, expenseID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "expense not found", "budget not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "EXPENSE_035"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "EXPENSE_036"))
+		case "expense is not pending":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "EXPENSE_037"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "EXPENSE_038"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *ExpenseController) GetApprovalHistory(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	expenseID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid expense id", "EXPENSE_039"))
+	}
+
+	response, err := c.expenseService.InWorkspace(workspaceScope(ctx)).GetApprovalHistory(userID, expenseID)
+	if err != nil {
+		switch err.Error() {
+		case "expense not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "EXPENSE_040"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "EXPENSE_041"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "EXPENSE_042"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
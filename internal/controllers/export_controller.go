@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/exporters"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ExportController struct {
+	exportService *services.ExportService
+	validate      *validator.Validate
+}
+
+func NewExportController(exportService *services.ExportService) *ExportController {
+	return &ExportController{
+		exportService: exportService,
+		validate:      validator.New(),
+	}
+}
+
+func (c *ExportController) Export(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var query requests.ExportQuery
+	if err := ctx.Bind(&query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPORT_001"))
+	}
+
+	if err := c.validate.Struct(query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "EXPORT_002"))
+	}
+
+	format := exporters.Format(query.Format)
+	if format == "" {
+		format = exporters.FormatCSV
+	}
+
+	var from, to time.Time
+	if query.From != nil {
+		from = *query.From
+	}
+	if query.To != nil {
+		to = *query.To
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid date range", "EXPORT_003"))
+	}
+
+	filename := fmt.Sprintf("dompet-export-%s.%s", time.Now().Format("20060102"), format)
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, exporters.ContentType(format))
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	res.WriteHeader(http.StatusOK)
+
+	// The status is already sent, so a failure can only cut the download short
+	if err := c.exportService.InWorkspace(workspaceScope(ctx)).Export(userID, format, from, to, res); err != nil {
+		log.Printf("Export for user %s failed: %v", userID, err)
+	}
+	return nil
+}
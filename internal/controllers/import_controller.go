@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// maxImportFileSize caps the size of an uploaded statement
+const maxImportFileSize = 5 << 20
+
+type ImportController struct {
+	importService *services.ImportService
+	validate      *validator.Validate
+}
+
+func NewImportController(importService *services.ImportService) *ImportController {
+	return &ImportController{
+		importService: importService,
+		validate:      validator.New(),
+	}
+}
+
+func (c *ImportController) PreviewImport(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.ImportPreviewRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "IMPORT_001"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "IMPORT_002"))
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("file is required", "IMPORT_003"))
+	}
+	if fileHeader.Size > maxImportFileSize {
+		return ctx.JSON(http.StatusRequestEntityTooLarge, responses.NewErrorResponse("file is too large", "IMPORT_004"))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "IMPORT_003"))
+	}
+	defer file.Close()
+
+	response, err := c.importService.InWorkspace(workspaceScope(ctx)).PreviewImport(userID, file, fileHeader.Filename, &req)
+	if err != nil {
+		if err.Error() == "budget not found" {
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "IMPORT_005"))
+		}
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "IMPORT_006"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *ImportController) CommitImport(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.ImportCommitRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "IMPORT_007"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "IMPORT_008"))
+	}
+
+	response, err := c.importService.InWorkspace(workspaceScope(ctx)).CommitImport(userID, &req)
+	if err != nil {
+		if err.Error() == "budget not found" {
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "IMPORT_009"))
+		}
+		// Row errors such as "row 3: insufficient budget" roll back the whole batch
+		return ctx.JSON(http.StatusUnprocessableEntity, responses.NewErrorResponse(err.Error(), "IMPORT_010"))
+	}
+
+	return ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(response))
+}
+
+func (c *ImportController) CreateRule(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.CreateImportRuleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "IMPORT_011"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "IMPORT_012"))
+	}
+
+	response, err := c.importService.InWorkspace(workspaceScope(ctx)).CreateRule(userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "budget not found", "category not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "IMPORT_013"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "IMPORT_014"))
+		}
+	}
+
+	return ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(response))
+}
+
+func (c *ImportController) GetRules(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	response, err := c.importService.InWorkspace(workspaceScope(ctx)).GetRules(userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "IMPORT_015"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *ImportController) DeleteRule(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	ruleID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid rule id", "IMPORT_016"))
+	}
+
+	if err := c.importService.InWorkspace(workspaceScope(ctx)).DeleteRule(userID, ruleID); err != nil {
+		switch err.Error() {
+		case "rule not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "IMPORT_017"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "IMPORT_018"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "IMPORT_019"))
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ProfileController struct {
+	profileService *services.ProfileService
+	validate       *validator.Validate
+}
+
+func NewProfileController(profileService *services.ProfileService) *ProfileController {
+	return &ProfileController{
+		profileService: profileService,
+		validate:       validator.New(),
+	}
+}
+
+func (c *ProfileController) GetProfile(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	response, err := c.profileService.GetProfile(userID)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "PROFILE_001"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *ProfileController) UpdateProfile(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.UpdateProfileRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_002"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_003"))
+	}
+
+	response, err := c.profileService.UpdateProfile(userID, &req)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "PROFILE_001"))
+		}
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "PROFILE_004"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *ProfileController) GetSignIns(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var query requests.SignInListQuery
+	if err := ctx.Bind(&query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_020"))
+	}
+
+	if err := c.validate.Struct(query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_021"))
+	}
+
+	response, err := c.profileService.GetSignIns(userID, &query)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "PROFILE_022"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *ProfileController) ChangePassword(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.ChangePasswordRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_005"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_006"))
+	}
+
+	response, err := c.profileService.ChangePassword(userID, &req, ctx.RealIP(), ctx.Request().UserAgent())
+	if err != nil {
+		var blocked *services.LoginBlockedError
+		if errors.As(err, &blocked) {
+			return loginBlocked(ctx, blocked)
+		}
+		switch err.Error() {
+		case "user not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "PROFILE_001"))
+		case "invalid password":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "PROFILE_007"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "PROFILE_008"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *ProfileController) RequestEmailChange(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.ChangeEmailRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_009"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_010"))
+	}
+
+	if err := c.profileService.RequestEmailChange(userID, &req, ctx.RealIP(), ctx.Request().UserAgent()); err != nil {
+		var blocked *services.LoginBlockedError
+		if errors.As(err, &blocked) {
+			return loginBlocked(ctx, blocked)
+		}
+		switch err.Error() {
+		case "user not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "PROFILE_001"))
+		case "invalid password":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "PROFILE_007"))
+		case "email is unchanged", "email already in use":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "PROFILE_011"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "PROFILE_012"))
+		}
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+func (c *ProfileController) ConfirmEmailChange(ctx echo.Context) error {
+	var req requests.ConfirmEmailRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_013"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_014"))
+	}
+
+	response, err := c.profileService.ConfirmEmailChange(&req)
+	if err != nil {
+		switch err.Error() {
+		case "invalid or expired token":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_015"))
+		case "email already in use":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "PROFILE_011"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "PROFILE_016"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *ProfileController) DeleteAccount(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.DeleteAccountRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_017"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "PROFILE_018"))
+	}
+
+	if err := c.profileService.DeleteAccount(userID, &req, ctx.RealIP(), ctx.Request().UserAgent()); err != nil {
+		var blocked *services.LoginBlockedError
+		if errors.As(err, &blocked) {
+			return loginBlocked(ctx, blocked)
+		}
+		switch err.Error() {
+		case "user not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "PROFILE_001"))
+		case "invalid password":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "PROFILE_007"))
+		case "transfer workspace ownership first":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "PROFILE_023"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "PROFILE_019"))
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
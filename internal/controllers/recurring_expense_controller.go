@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type RecurringExpenseController struct {
+	recurringService *services.RecurringExpenseService
+	validate         *validator.Validate
+}
+
+func NewRecurringExpenseController(recurringService *services.RecurringExpenseService) *RecurringExpenseController {
+	return &RecurringExpenseController{
+		recurringService: recurringService,
+		validate:         validator.New(),
+	}
+}
+
+func (c *RecurringExpenseController) CreateRecurringExpense(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.CreateRecurringExpenseRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "RECURRING_001"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "RECURRING_002"))
+	}
+
+	response, err := c.recurringService.InWorkspace(workspaceScope(ctx)).CreateRecurringExpense(userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "budget not found", "category not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "RECURRING_003"))
+		case "end date is before start date":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "RECURRING_004"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "RECURRING_005"))
+		}
+	}
+
+	return ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(response))
+}
+
+func (c *RecurringExpenseController) GetRecurringExpenses(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	response, err := c.recurringService.InWorkspace(workspaceScope(ctx)).GetRecurringExpenses(userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "RECURRING_006"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *RecurringExpenseController) UpdateRecurringExpense(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	recurringID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid recurring expense id", "RECURRING_007"))
+	}
+
+	var req requests.UpdateRecurringExpenseRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "RECURRING_008"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "RECURRING_009"))
+	}
+
+	response, err := c.recurringService.InWorkspace(workspaceScope(ctx)).UpdateRecurringExpense(userID, recurringID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "recurring expense not found", "budget not found", "category not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "RECURRING_010"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "RECURRING_011"))
+		case "end date is before start date":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "RECURRING_012"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "RECURRING_013"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *RecurringExpenseController) DeleteRecurringExpense(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	recurringID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid recurring expense id", "RECURRING_014"))
+	}
+
+	if err := c.recurringService.InWorkspace(workspaceScope(ctx)).DeleteRecurringExpense(userID, recurringID); err != nil {
+		switch err.Error() {
+		case "recurring expense not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "RECURRING_015"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "RECURRING_016"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "RECURRING_017"))
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (c *RecurringExpenseController) GetUpcomingOccurrences(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var query requests.UpcomingOccurrencesQuery
+	if err := ctx.Bind(&query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "RECURRING_018"))
+	}
+
+	if err := c.validate.Struct(query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "RECURRING_019"))
+	}
+
+	response, err := c.recurringService.InWorkspace(workspaceScope(ctx)).GetUpcomingOccurrences(userID, &query, time.Now())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "RECURRING_020"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *RecurringExpenseController) SkipOccurrence(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	recurringID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid recurring expense id", "RECURRING_021"))
+	}
+	day, err := time.Parse("2006-01-02", ctx.Param("date"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid date", "RECURRING_022"))
+	}
+
+	response, err := c.recurringService.InWorkspace(workspaceScope(ctx)).SkipOccurrence(userID, recurringID, day)
+	if err != nil {
+		return c.occurrenceError(ctx, err, "RECURRING_023", "RECURRING_024", "RECURRING_025")
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *RecurringExpenseController) UpdateOccurrence(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	recurringID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid recurring expense id", "RECURRING_026"))
+	}
+	day, err := time.Parse("2006-01-02", ctx.Param("date"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid date", "RECURRING_027"))
+	}
+
+	var req requests.UpdateOccurrenceRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "RECURRING_028"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "RECURRING_029"))
+	}
+
+	response, err := c.recurringService.InWorkspace(workspaceScope(ctx)).UpdateOccurrence(userID, recurringID, day, &req)
+	if err != nil {
+		return c.occurrenceError(ctx, err, "RECURRING_030", "RECURRING_031", "RECURRING_032")
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *RecurringExpenseController) occurrenceError(ctx echo.Context, err error, notFound, unauthorized, internal string) error {
+	switch err.Error() {
+	case "recurring expense not found", "occurrence not found":
+		return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), notFound))
+	case "unauthorized":
+		return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), unauthorized))
+	default:
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), internal))
+	}
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ReportController struct {
+	reportService *services.ReportService
+	validate      *validator.Validate
+}
+
+func NewReportController(reportService *services.ReportService) *ReportController {
+	return &ReportController{
+		reportService: reportService,
+		validate:      validator.New(),
+	}
+}
+
+func (c *ReportController) GetSummary(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var query requests.ReportSummaryQuery
+	if err := ctx.Bind(&query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "REPORT_001"))
+	}
+
+	if err := c.validate.Struct(query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "REPORT_002"))
+	}
+
+	response, err := c.reportService.InWorkspace(workspaceScope(ctx)).GetSummary(userID, &query, time.Now())
+	if err != nil {
+		if err.Error() == "invalid date range" {
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "REPORT_003"))
+		}
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "REPORT_004"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
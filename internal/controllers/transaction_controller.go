@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type TransactionController struct {
+	transactionService *services.TransactionService
+	validate           *validator.Validate
+}
+
+func NewTransactionController(transactionService *services.TransactionService) *TransactionController {
+	return &TransactionController{
+		transactionService: transactionService,
+		validate:           validator.New(),
+	}
+}
+
+func (c *TransactionController) CreateTransaction(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.CreateTransactionRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "TRANSACTION_001"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "TRANSACTION_002"))
+	}
+
+	response, err := c.transactionService.InWorkspace(workspaceScope(ctx)).CreateTransaction(userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "budget not found", "category not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "TRANSACTION_003"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "TRANSACTION_004"))
+		case "insufficient budget", "budget_id is required", "budget_id is required to top up a budget", "transfer needs two different budgets":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "TRANSACTION_005"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "TRANSACTION_006"))
+		}
+	}
+
+	return ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(response))
+}
+
+func (c *TransactionController) GetTransactions(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var query requests.TransactionListQuery
+	if err := ctx.Bind(&query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "TRANSACTION_007"))
+	}
+
+	if err := c.validate.Struct(query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "TRANSACTION_008"))
+	}
+
+	response, err := c.transactionService.InWorkspace(workspaceScope(ctx)).GetLedger(userID, &query)
+	if err != nil {
+		switch err.Error() {
+		case "invalid cursor":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "TRANSACTION_014"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "TRANSACTION_009"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *TransactionController) DeleteTransaction(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	transactionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid transaction id", "TRANSACTION_010"))
+	}
+
+	if err := c.transactionService.InWorkspace(workspaceScope(ctx)).DeleteTransaction(userID, transactionID); err != nil {
+		switch err.Error() {
+		case "transaction not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "TRANSACTION_011"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "TRANSACTION_012"))
+		default:
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "TRANSACTION_013"))
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
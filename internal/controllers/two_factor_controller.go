@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type TwoFactorController struct {
+	twoFactorService *services.TwoFactorService
+	validate         *validator.Validate
+}
+
+func NewTwoFactorController(twoFactorService *services.TwoFactorService) *TwoFactorController {
+	return &TwoFactorController{
+		twoFactorService: twoFactorService,
+		validate:         validator.New(),
+	}
+}
+
+func (c *TwoFactorController) GetStatus(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	response, err := c.twoFactorService.GetStatus(userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "MFA_001"))
+		}
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "MFA_002"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *TwoFactorController) Setup(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	response, err := c.twoFactorService.Setup(userID)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "MFA_001"))
+		case "two-factor authentication already enabled":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "MFA_003"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "MFA_004"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *TwoFactorController) Enable(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.EnableTwoFactorRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MFA_005"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MFA_006"))
+	}
+
+	response, err := c.twoFactorService.Enable(userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "MFA_001"))
+		case "two-factor authentication already enabled":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "MFA_003"))
+		case "two-factor setup not started":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "MFA_007"))
+		case "invalid code":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "MFA_008"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "MFA_009"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *TwoFactorController) Disable(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.DisableTwoFactorRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MFA_010"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MFA_011"))
+	}
+
+	if err := c.twoFactorService.Disable(userID, &req, ctx.RealIP(), ctx.Request().UserAgent()); err != nil {
+		var blocked *services.LoginBlockedError
+		if errors.As(err, &blocked) {
+			return loginBlocked(ctx, blocked)
+		}
+		switch err.Error() {
+		case "user not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "MFA_001"))
+		case "two-factor authentication not enabled":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "MFA_012"))
+		case "invalid password", "invalid code":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "MFA_008"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "MFA_013"))
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (c *TwoFactorController) RegenerateRecoveryCodes(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.RegenerateRecoveryCodesRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MFA_014"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "MFA_015"))
+	}
+
+	response, err := c.twoFactorService.RegenerateRecoveryCodes(userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "MFA_001"))
+		case "two-factor authentication not enabled":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "MFA_012"))
+		case "invalid code":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "MFA_008"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "MFA_016"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
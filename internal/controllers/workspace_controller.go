@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// workspaceScope returns the workspace resolved by RequireWorkspace.
+func workspaceScope(ctx echo.Context) services.WorkspaceScope {
+	return services.WorkspaceScope{
+		ID:   ctx.Get("workspace_id").(uuid.UUID),
+		Role: ctx.Get("workspace_role").(models.WorkspaceRole),
+	}
+}
+
+type WorkspaceController struct {
+	workspaceService *services.WorkspaceService
+	validate         *validator.Validate
+}
+
+func NewWorkspaceController(workspaceService *services.WorkspaceService) *WorkspaceController {
+	return &WorkspaceController{
+		workspaceService: workspaceService,
+		validate:         validator.New(),
+	}
+}
+
+func (c *WorkspaceController) CreateWorkspace(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	var req requests.CreateWorkspaceRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_001"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_002"))
+	}
+
+	response, err := c.workspaceService.CreateWorkspace(userID, &req)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "WORKSPACE_003"))
+	}
+
+	return ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(response))
+}
+
+func (c *WorkspaceController) GetWorkspaces(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+
+	response, err := c.workspaceService.GetWorkspaces(userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "WORKSPACE_004"))
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *WorkspaceController) UpdateWorkspace(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	workspaceID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid workspace id", "WORKSPACE_005"))
+	}
+
+	var req requests.UpdateWorkspaceRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_006"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_007"))
+	}
+
+	response, err := c.workspaceService.UpdateWorkspace(userID, workspaceID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "workspace not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "WORKSPACE_008"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "WORKSPACE_009"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "WORKSPACE_010"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *WorkspaceController) DeleteWorkspace(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	workspaceID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid workspace id", "WORKSPACE_011"))
+	}
+
+	if err := c.workspaceService.DeleteWorkspace(userID, workspaceID); err != nil {
+		switch err.Error() {
+		case "workspace not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "WORKSPACE_012"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "WORKSPACE_013"))
+		case "cannot delete a personal workspace":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_014"))
+		case "workspace is not empty":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "WORKSPACE_015"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "WORKSPACE_016"))
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (c *WorkspaceController) GetMembers(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	workspaceID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid workspace id", "WORKSPACE_017"))
+	}
+
+	response, err := c.workspaceService.GetMembers(userID, workspaceID)
+	if err != nil {
+		switch err.Error() {
+		case "workspace not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "WORKSPACE_018"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "WORKSPACE_019"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *WorkspaceController) AddMember(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	workspaceID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid workspace id", "WORKSPACE_020"))
+	}
+
+	var req requests.AddWorkspaceMemberRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_021"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_022"))
+	}
+
+	response, err := c.workspaceService.AddMember(userID, workspaceID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "workspace not found", "user not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "WORKSPACE_023"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "WORKSPACE_024"))
+		case "personal workspaces cannot be shared":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_025"))
+		case "already a member":
+			return ctx.JSON(http.StatusConflict, responses.NewErrorResponse(err.Error(), "WORKSPACE_026"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "WORKSPACE_027"))
+		}
+	}
+
+	return ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(response))
+}
+
+func (c *WorkspaceController) UpdateMemberRole(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	workspaceID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid workspace id", "WORKSPACE_028"))
+	}
+	memberID, err := uuid.Parse(ctx.Param("user_id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid user id", "WORKSPACE_029"))
+	}
+
+	var req requests.UpdateWorkspaceMemberRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_030"))
+	}
+
+	if err := c.validate.Struct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_031"))
+	}
+
+	response, err := c.workspaceService.UpdateMemberRole(userID, workspaceID, memberID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "workspace not found", "member not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "WORKSPACE_032"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "WORKSPACE_033"))
+		case "workspace needs an owner":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_034"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "WORKSPACE_035"))
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewSuccessResponse(response))
+}
+
+func (c *WorkspaceController) RemoveMember(ctx echo.Context) error {
+	userID := ctx.Get("user_id").(uuid.UUID)
+	workspaceID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid workspace id", "WORKSPACE_036"))
+	}
+	memberID, err := uuid.Parse(ctx.Param("user_id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("invalid user id", "WORKSPACE_037"))
+	}
+
+	if err := c.workspaceService.RemoveMember(userID, workspaceID, memberID); err != nil {
+		switch err.Error() {
+		case "workspace not found", "member not found":
+			return ctx.JSON(http.StatusNotFound, responses.NewErrorResponse(err.Error(), "WORKSPACE_038"))
+		case "unauthorized":
+			return ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse(err.Error(), "WORKSPACE_039"))
+		case "workspace needs an owner":
+			return ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(err.Error(), "WORKSPACE_040"))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse(err.Error(), "WORKSPACE_041"))
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
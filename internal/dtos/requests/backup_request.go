@@ -0,0 +1,8 @@
+package requests
+
+// RestoreBackupQuery decides what happens to a budget in the backup whose
+// name matches an existing budget: rename it (default), skip it together
+// with its expenses, or merge its expenses into the existing budget.
+type RestoreBackupQuery struct {
+	Conflict string `query:"conflict" validate:"omitempty,oneof=rename skip merge"`
+}
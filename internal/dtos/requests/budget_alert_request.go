@@ -0,0 +1,18 @@
+package requests
+
+type CreateBudgetAlertRequest struct {
+	Threshold int    `json:"threshold" validate:"required,min=1,max=1000"`
+	URL       string `json:"url" validate:"required,http_url,max=2048"`
+	Active    *bool  `json:"active"`
+}
+
+type UpdateBudgetAlertRequest struct {
+	Threshold int    `json:"threshold" validate:"required,min=1,max=1000"`
+	URL       string `json:"url" validate:"required,http_url,max=2048"`
+	Active    *bool  `json:"active"`
+}
+
+// WebhookDeliveryListQuery holds the paging of GET /budgets/:id/alerts/:alert_id/deliveries
+type WebhookDeliveryListQuery struct {
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,16 @@
+package requests
+
+import "github.com/Alvarras/dompet-g0/internal/models"
+
+type InviteBudgetMemberRequest struct {
+	Email string            `json:"email" validate:"required,email"`
+	Role  models.BudgetRole `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type UpdateBudgetMemberRequest struct {
+	Role models.BudgetRole `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
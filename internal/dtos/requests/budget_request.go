@@ -1,13 +1,29 @@
 package requests
 
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+)
+
 type CreateBudgetRequest struct {
-	Name        string  `json:"name" validate:"required"`
-	Amount      float64 `json:"amount" validate:"required,gt=0"`
-	Description string  `json:"description"`
+	Name              string                `json:"name" validate:"required"`
+	Amount            models.Money          `json:"amount" validate:"required,gt=0"`
+	Description       string                `json:"description"`
+	Period            models.BudgetPeriod   `json:"period" validate:"omitempty,oneof=none weekly monthly yearly custom"`
+	StartDate         *time.Time            `json:"start_date"`
+	EndDate           *time.Time            `json:"end_date" validate:"required_if=Period custom"`
+	RolloverPolicy    models.RolloverPolicy `json:"rollover_policy" validate:"omitempty,oneof=reset carry_surplus carry_deficit"`
+	ApprovalThreshold models.Money          `json:"approval_threshold" validate:"gte=0"`
 }
 
 type UpdateBudgetRequest struct {
-	Name        string  `json:"name" validate:"required"`
-	Amount      float64 `json:"amount" validate:"required,gt=0"`
-	Description string  `json:"description"`
+	Name              string                `json:"name" validate:"required"`
+	Amount            models.Money          `json:"amount" validate:"required,gt=0"`
+	Description       string                `json:"description"`
+	Period            models.BudgetPeriod   `json:"period" validate:"omitempty,oneof=none weekly monthly yearly custom"`
+	StartDate         *time.Time            `json:"start_date"`
+	EndDate           *time.Time            `json:"end_date"`
+	RolloverPolicy    models.RolloverPolicy `json:"rollover_policy" validate:"omitempty,oneof=reset carry_surplus carry_deficit"`
+	ApprovalThreshold *models.Money         `json:"approval_threshold" validate:"omitempty,gte=0"`
 }
@@ -0,0 +1,15 @@
+package requests
+
+import "github.com/google/uuid"
+
+type CreateCategoryRequest struct {
+	Name        string     `json:"name" validate:"required,max=100"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Description string     `json:"description"`
+}
+
+type UpdateCategoryRequest struct {
+	Name        string     `json:"name" validate:"required,max=100"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Description string     `json:"description"`
+}
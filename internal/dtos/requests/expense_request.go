@@ -3,19 +3,50 @@ package requests
 import (
 	"time"
 
+	"github.com/Alvarras/dompet-g0/internal/models"
 	"github.com/google/uuid"
 )
 
 type CreateExpenseRequest struct {
-	BudgetID    uuid.UUID `json:"budget_id" validate:"required"`
-	Amount      float64   `json:"amount" validate:"required,gt=0"`
-	Description string    `json:"description"`
-	Date        time.Time `json:"date,omitempty"`
+	BudgetID    uuid.UUID    `json:"budget_id" validate:"required"`
+	CategoryID  *uuid.UUID   `json:"category_id"`
+	Amount      models.Money `json:"amount" validate:"required,gt=0"`
+	Description string       `json:"description"`
+	Date        time.Time    `json:"date,omitempty"`
+	Tags        []string     `json:"tags" validate:"omitempty,max=20,dive,required,max=50"`
 }
 
 type UpdateExpenseRequest struct {
-	BudgetID    uuid.UUID `json:"budget_id" validate:"required"`
-	Amount      float64   `json:"amount" validate:"required,gt=0"`
-	Description string    `json:"description"`
-	Date        time.Time `json:"date,omitempty"`
+	BudgetID    uuid.UUID    `json:"budget_id" validate:"required"`
+	CategoryID  *uuid.UUID   `json:"category_id"`
+	Amount      models.Money `json:"amount" validate:"required,gt=0"`
+	Description string       `json:"description"`
+	Date        time.Time    `json:"date,omitempty"`
+	Tags        []string     `json:"tags" validate:"omitempty,max=20,dive,required,max=50"`
+}
+
+// ApproveExpenseRequest is used for POST /expenses/:id/approve
+type ApproveExpenseRequest struct {
+	Comment string `json:"comment" validate:"max=500"`
+}
+
+// RejectExpenseRequest is used for POST /expenses/:id/reject
+type RejectExpenseRequest struct {
+	Comment string `json:"comment" validate:"required,max=500"`
+}
+
+// ExpenseListQuery holds the query string filters, sort order and paging of
+// GET /expenses and GET /expenses/budget/:budget_id
+type ExpenseListQuery struct {
+	CategoryID *uuid.UUID           `query:"category_id"`
+	Tag        string               `query:"tag"`
+	From       *time.Time           `query:"from"`
+	To         *time.Time           `query:"to"`
+	MinAmount  *models.Money        `query:"min_amount"`
+	MaxAmount  *models.Money        `query:"max_amount"`
+	Search     string               `query:"q" validate:"max=100"`
+	Status     models.ExpenseStatus `query:"status" validate:"omitempty,oneof=approved pending rejected"`
+	Sort       string               `query:"sort" validate:"omitempty,oneof=date -date amount -amount"`
+	Limit      int                  `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor     string               `query:"cursor"`
 }
@@ -0,0 +1,10 @@
+package requests
+
+import "time"
+
+// ExportQuery holds the query string of GET /export; to is exclusive
+type ExportQuery struct {
+	Format string     `query:"format" validate:"omitempty,oneof=csv json ofx"`
+	From   *time.Time `query:"from"`
+	To     *time.Time `query:"to"`
+}
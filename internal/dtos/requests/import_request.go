@@ -0,0 +1,44 @@
+package requests
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
+
+// ImportPreviewRequest holds the form fields sent next to the statement file.
+// Column names refer to the CSV header and DateFormat is a Go time layout.
+type ImportPreviewRequest struct {
+	Format            string     `form:"format" validate:"omitempty,oneof=csv ofx qif"`
+	DateColumn        string     `form:"date_column"`
+	AmountColumn      string     `form:"amount_column"`
+	DescriptionColumn string     `form:"description_column"`
+	DateFormat        string     `form:"date_format"`
+	Delimiter         string     `form:"delimiter" validate:"omitempty,len=1"`
+	DecimalComma      bool       `form:"decimal_comma"`
+	NegativeExpenses  bool       `form:"negative_expenses"`
+	DefaultBudgetID   *uuid.UUID `form:"default_budget_id"`
+}
+
+type ImportRowRequest struct {
+	Date        time.Time    `json:"date" validate:"required"`
+	Amount      models.Money `json:"amount" validate:"required,gt=0"`
+	Description string       `json:"description"`
+	BudgetID    uuid.UUID    `json:"budget_id" validate:"required"`
+	CategoryID  *uuid.UUID   `json:"category_id"`
+	Tags        []string     `json:"tags" validate:"omitempty,max=20,dive,required,max=50"`
+}
+
+// ImportCommitRequest carries the previewed rows the user wants to keep
+type ImportCommitRequest struct {
+	Rows           []ImportRowRequest `json:"rows" validate:"required,min=1,max=1000,dive"`
+	SkipDuplicates bool               `json:"skip_duplicates"`
+}
+
+type CreateImportRuleRequest struct {
+	Pattern    string     `json:"pattern" validate:"required,max=100"`
+	BudgetID   uuid.UUID  `json:"budget_id" validate:"required"`
+	CategoryID *uuid.UUID `json:"category_id"`
+	Priority   int        `json:"priority"`
+}
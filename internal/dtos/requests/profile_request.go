@@ -0,0 +1,28 @@
+package requests
+
+type UpdateProfileRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ConfirmEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// SignInListQuery holds the paging of GET /me/sessions
+type SignInListQuery struct {
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
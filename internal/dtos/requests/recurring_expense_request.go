@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
+
+type CreateRecurringExpenseRequest struct {
+	BudgetID    uuid.UUID                  `json:"budget_id" validate:"required"`
+	CategoryID  *uuid.UUID                 `json:"category_id"`
+	Amount      models.Money               `json:"amount" validate:"required,gt=0"`
+	Description string                     `json:"description"`
+	Tags        []string                   `json:"tags" validate:"omitempty,max=20,dive,required,max=50"`
+	Frequency   models.RecurrenceFrequency `json:"frequency" validate:"required,oneof=daily weekly monthly yearly"`
+	Interval    int                        `json:"interval" validate:"omitempty,min=1,max=366"`
+	StartDate   time.Time                  `json:"start_date" validate:"required"`
+	EndDate     *time.Time                 `json:"end_date"`
+	Count       int                        `json:"count" validate:"omitempty,min=1,max=1000"`
+}
+
+type UpdateRecurringExpenseRequest struct {
+	BudgetID    uuid.UUID                  `json:"budget_id" validate:"required"`
+	CategoryID  *uuid.UUID                 `json:"category_id"`
+	Amount      models.Money               `json:"amount" validate:"required,gt=0"`
+	Description string                     `json:"description"`
+	Tags        []string                   `json:"tags" validate:"omitempty,max=20,dive,required,max=50"`
+	Frequency   models.RecurrenceFrequency `json:"frequency" validate:"required,oneof=daily weekly monthly yearly"`
+	Interval    int                        `json:"interval" validate:"omitempty,min=1,max=366"`
+	StartDate   time.Time                  `json:"start_date" validate:"required"`
+	EndDate     *time.Time                 `json:"end_date"`
+	Count       int                        `json:"count" validate:"omitempty,min=1,max=1000"`
+}
+
+// UpdateOccurrenceRequest changes a single upcoming occurrence. Fields left
+// out keep the value of the recurring expense.
+type UpdateOccurrenceRequest struct {
+	Amount      *models.Money `json:"amount" validate:"omitempty,gt=0"`
+	Description *string       `json:"description" validate:"omitempty,max=255"`
+}
+
+// UpcomingOccurrencesQuery holds the query string of GET /recurring-expenses/upcoming
+type UpcomingOccurrencesQuery struct {
+	Days int `query:"days" validate:"omitempty,min=1,max=366"`
+}
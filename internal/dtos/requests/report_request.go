@@ -0,0 +1,11 @@
+package requests
+
+import "time"
+
+// ReportSummaryQuery holds the query string of GET /reports/summary. Without
+// from and to the current calendar month is reported; to is exclusive.
+type ReportSummaryQuery struct {
+	From    *time.Time `query:"from"`
+	To      *time.Time `query:"to"`
+	GroupBy string     `query:"group_by" validate:"omitempty,oneof=day week month budget category"`
+}
@@ -0,0 +1,32 @@
+package requests
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
+
+// CreateTransactionRequest records an income, expense or transfer. BudgetID
+// is the budget an expense is charged to, the budget an income is credited
+// to, or the source budget of a transfer.
+type CreateTransactionRequest struct {
+	Type        models.TransactionType `json:"type" validate:"required,oneof=income expense transfer"`
+	Amount      models.Money           `json:"amount" validate:"required,gt=0"`
+	Description string                 `json:"description"`
+	Date        time.Time              `json:"date,omitempty"`
+	BudgetID    *uuid.UUID             `json:"budget_id"`
+	ToBudgetID  *uuid.UUID             `json:"to_budget_id"`
+	TopUpBudget bool                   `json:"top_up_budget"`
+	CategoryID  *uuid.UUID             `json:"category_id"`
+	Tags        []string               `json:"tags" validate:"omitempty,max=20,dive,required,max=50"`
+}
+
+// TransactionListQuery holds the query string filters and paging of GET /transactions
+type TransactionListQuery struct {
+	Type   models.TransactionType `query:"type" validate:"omitempty,oneof=income expense transfer"`
+	From   *time.Time             `query:"from"`
+	To     *time.Time             `query:"to"`
+	Limit  int                    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string                 `query:"cursor"`
+}
@@ -0,0 +1,15 @@
+package requests
+
+type EnableTwoFactorRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	// Code is a TOTP code or a recovery code
+	Code string `json:"code" validate:"required"`
+}
+
+type RegenerateRecoveryCodesRequest struct {
+	Code string `json:"code" validate:"required"`
+}
@@ -0,0 +1,20 @@
+package requests
+
+import "github.com/Alvarras/dompet-g0/internal/models"
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type UpdateWorkspaceRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type AddWorkspaceMemberRequest struct {
+	Email string               `json:"email" validate:"required,email"`
+	Role  models.WorkspaceRole `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+type UpdateWorkspaceMemberRequest struct {
+	Role models.WorkspaceRole `json:"role" validate:"required,oneof=owner editor viewer"`
+}
@@ -0,0 +1,14 @@
+package responses
+
+// RestoreBackupResponse counts the rows created by a restore
+type RestoreBackupResponse struct {
+	Budgets         int `json:"budgets"`
+	BudgetHistories int `json:"budget_histories"`
+	Categories      int `json:"categories"`
+	Expenses        int `json:"expenses"`
+	Incomes         int `json:"incomes"`
+	Transfers       int `json:"transfers"`
+	ImportRules     int `json:"import_rules"`
+	SkippedBudgets  int `json:"skipped_budgets"`
+	MergedBudgets   int `json:"merged_budgets"`
+}
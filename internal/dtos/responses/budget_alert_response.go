@@ -0,0 +1,48 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
+
+type BudgetAlertResponse struct {
+	ID        uuid.UUID `json:"id"`
+	BudgetID  uuid.UUID `json:"budget_id"`
+	Threshold int       `json:"threshold"`
+	URL       string    `json:"url"`
+	Active    bool      `json:"active"`
+	Triggered bool      `json:"triggered"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreatedBudgetAlertResponse is the only response that includes the signing secret
+type CreatedBudgetAlertResponse struct {
+	BudgetAlertResponse
+	Secret string `json:"secret"`
+}
+
+type BudgetAlertListResponse struct {
+	Alerts []BudgetAlertResponse `json:"alerts"`
+	Total  int                   `json:"total"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID            uuid.UUID                    `json:"id"`
+	AlertID       uuid.UUID                    `json:"alert_id"`
+	Event         string                       `json:"event"`
+	Payload       string                       `json:"payload"`
+	Status        models.WebhookDeliveryStatus `json:"status"`
+	Attempts      int                          `json:"attempts"`
+	NextAttemptAt *time.Time                   `json:"next_attempt_at"`
+	StatusCode    int                          `json:"status_code"`
+	Error         string                       `json:"error"`
+	DeliveredAt   *time.Time                   `json:"delivered_at"`
+	CreatedAt     time.Time                    `json:"created_at"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Total      int                       `json:"total"`
+}
@@ -0,0 +1,31 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
+
+type BudgetMemberResponse struct {
+	UserID   uuid.UUID         `json:"user_id"`
+	Name     string            `json:"name"`
+	Email    string            `json:"email"`
+	Role     models.BudgetRole `json:"role"`
+	JoinedAt time.Time         `json:"joined_at"`
+}
+
+type BudgetInvitationResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	Email     string            `json:"email"`
+	Role      models.BudgetRole `json:"role"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// BudgetMemberListResponse lists the owner first. Pending invitations are
+// only shown to the owner.
+type BudgetMemberListResponse struct {
+	Members     []BudgetMemberResponse     `json:"members"`
+	Invitations []BudgetInvitationResponse `json:"invitations,omitempty"`
+}
@@ -1,14 +1,40 @@
 package responses
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
 
 type BudgetResponse struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Amount      float64   `json:"amount"`
-	Spent       float64   `json:"spent"`
-	Remaining   float64   `json:"remaining"`
-	Description string    `json:"description"`
+	ID                uuid.UUID              `json:"id"`
+	WorkspaceID       uuid.UUID              `json:"workspace_id"`
+	OwnerID           uuid.UUID              `json:"owner_id"`
+	Role              models.BudgetRole      `json:"role,omitempty"`
+	Name              string                 `json:"name"`
+	Amount            models.Money           `json:"amount"`
+	Spent             models.Money           `json:"spent"`
+	Remaining         models.Money           `json:"remaining"`
+	Description       string                 `json:"description"`
+	Period            models.BudgetPeriod    `json:"period"`
+	RolloverPolicy    models.RolloverPolicy  `json:"rollover_policy"`
+	PeriodStart       *time.Time             `json:"period_start,omitempty"`
+	PeriodEnd         *time.Time             `json:"period_end,omitempty"`
+	CarryOver         models.Money           `json:"carry_over"`
+	ApprovalThreshold models.Money           `json:"approval_threshold"`
+	History           []BudgetPeriodResponse `json:"history,omitempty"`
+}
+
+// BudgetPeriodResponse is one finished period of a periodic budget
+type BudgetPeriodResponse struct {
+	PeriodStart time.Time    `json:"period_start"`
+	PeriodEnd   time.Time    `json:"period_end"`
+	Amount      models.Money `json:"amount"`
+	CarryIn     models.Money `json:"carry_in"`
+	Spent       models.Money `json:"spent"`
+	Remaining   models.Money `json:"remaining"`
+	CarryOut    models.Money `json:"carry_out"`
 }
 
 type BudgetListResponse struct {
@@ -0,0 +1,22 @@
+package responses
+
+import "github.com/google/uuid"
+
+type CategoryResponse struct {
+	ID          uuid.UUID          `json:"id"`
+	ParentID    *uuid.UUID         `json:"parent_id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Children    []CategoryResponse `json:"children,omitempty"`
+}
+
+// CategoryListResponse returns categories as a tree of root categories
+type CategoryListResponse struct {
+	Categories []CategoryResponse `json:"categories"`
+	Total      int                `json:"total"`
+}
+
+type TagListResponse struct {
+	Tags  []string `json:"tags"`
+	Total int      `json:"total"`
+}
@@ -3,43 +3,84 @@ package responses
 import (
 	"time"
 
+	"github.com/Alvarras/dompet-g0/internal/models"
 	"github.com/google/uuid"
 )
 
 // CreateExpenseResponse is used for POST /expenses response
 type CreateExpenseResponse struct {
-	ID          uuid.UUID `json:"id"`
-	BudgetID    uuid.UUID `json:"budget_id"`
-	BudgetName  string    `json:"budget_name"`
-	Amount      float64   `json:"amount"`
-	Description string    `json:"description"`
-	Date        time.Time `json:"date"`
+	ID           uuid.UUID            `json:"id"`
+	BudgetID     uuid.UUID            `json:"budget_id"`
+	BudgetName   string               `json:"budget_name"`
+	SpentBy      uuid.UUID            `json:"spent_by"`
+	CategoryID   *uuid.UUID           `json:"category_id"`
+	CategoryName string               `json:"category_name,omitempty"`
+	Amount       models.Money         `json:"amount"`
+	Description  string               `json:"description"`
+	Date         time.Time            `json:"date"`
+	Status       models.ExpenseStatus `json:"status"`
+	Tags         []string             `json:"tags"`
 }
 
 // UpdateExpenseResponse is used for PUT /expenses/:id response
 type UpdateExpenseResponse struct {
-	ID          uuid.UUID `json:"id"`
-	BudgetID    uuid.UUID `json:"budget_id"`
-	BudgetName  string    `json:"budget_name"`
-	Amount      float64   `json:"amount"`
-	Description string    `json:"description"`
-	Date        time.Time `json:"date"`
+	ID           uuid.UUID            `json:"id"`
+	BudgetID     uuid.UUID            `json:"budget_id"`
+	BudgetName   string               `json:"budget_name"`
+	SpentBy      uuid.UUID            `json:"spent_by"`
+	CategoryID   *uuid.UUID           `json:"category_id"`
+	CategoryName string               `json:"category_name,omitempty"`
+	Amount       models.Money         `json:"amount"`
+	Description  string               `json:"description"`
+	Date         time.Time            `json:"date"`
+	Status       models.ExpenseStatus `json:"status"`
+	Tags         []string             `json:"tags"`
 }
 
 // ExpenseResponse is used for GET responses
 type ExpenseResponse struct {
-	ID              uuid.UUID `json:"id"`
-	BudgetID        uuid.UUID `json:"budget_id"`
-	BudgetName      string    `json:"budget_name"`
-	Amount          float64   `json:"amount"`
-	Description     string    `json:"description"`
-	Date            time.Time `json:"date"`
-	BudgetRemaining float64   `json:"budget_remaining"`
-	BudgetSpent     float64   `json:"budget_spent"`
-	BudgetTotal     float64   `json:"budget_total"`
+	ID              uuid.UUID            `json:"id"`
+	BudgetID        uuid.UUID            `json:"budget_id"`
+	BudgetName      string               `json:"budget_name"`
+	SpentBy         uuid.UUID            `json:"spent_by"`
+	SpentByName     string               `json:"spent_by_name"`
+	CategoryID      *uuid.UUID           `json:"category_id"`
+	CategoryName    string               `json:"category_name,omitempty"`
+	Amount          models.Money         `json:"amount"`
+	Description     string               `json:"description"`
+	Date            time.Time            `json:"date"`
+	Status          models.ExpenseStatus `json:"status"`
+	BudgetRemaining models.Money         `json:"budget_remaining"`
+	BudgetSpent     models.Money         `json:"budget_spent"`
+	BudgetTotal     models.Money         `json:"budget_total"`
+	Tags            []string             `json:"tags"`
 }
 
+// ExpenseListResponse holds one page of expenses. Total counts every match
+// and NextCursor is empty on the last page.
 type ExpenseListResponse struct {
-	Expenses []ExpenseResponse `json:"expenses"`
-	Total    int               `json:"total"`
+	Expenses   []ExpenseResponse `json:"expenses"`
+	Total      int               `json:"total"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// ExpenseApprovalResponse is one step of an expense's approval history
+type ExpenseApprovalResponse struct {
+	ID        uuid.UUID                    `json:"id"`
+	ExpenseID uuid.UUID                    `json:"expense_id"`
+	BudgetID  uuid.UUID                    `json:"budget_id"`
+	UserID    uuid.UUID                    `json:"user_id"`
+	UserName  string                       `json:"user_name,omitempty"`
+	Action    models.ExpenseApprovalAction `json:"action"`
+	Amount    models.Money                 `json:"amount"`
+	Comment   string                       `json:"comment,omitempty"`
+	CreatedAt time.Time                    `json:"created_at"`
+}
+
+// ExpenseApprovalListResponse is used for GET /expenses/:id/approvals response
+type ExpenseApprovalListResponse struct {
+	ExpenseID uuid.UUID                 `json:"expense_id"`
+	Status    models.ExpenseStatus      `json:"status"`
+	Approvals []ExpenseApprovalResponse `json:"approvals"`
+	Total     int                       `json:"total"`
 }
@@ -0,0 +1,49 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
+
+// ImportRowResponse is one parsed statement row. Rows with an error cannot
+// be imported; duplicate rows match an existing expense.
+type ImportRowResponse struct {
+	Line        int          `json:"line"`
+	Date        time.Time    `json:"date"`
+	Amount      models.Money `json:"amount"`
+	Description string       `json:"description"`
+	BudgetID    *uuid.UUID   `json:"budget_id"`
+	CategoryID  *uuid.UUID   `json:"category_id"`
+	RuleID      *uuid.UUID   `json:"rule_id,omitempty"`
+	Duplicate   bool         `json:"duplicate"`
+	Error       string       `json:"error,omitempty"`
+}
+
+type ImportPreviewResponse struct {
+	Format     string              `json:"format"`
+	Rows       []ImportRowResponse `json:"rows"`
+	Total      int                 `json:"total"`
+	Duplicates int                 `json:"duplicates"`
+	Invalid    int                 `json:"invalid"`
+}
+
+type ImportCommitResponse struct {
+	Imported int                     `json:"imported"`
+	Skipped  int                     `json:"skipped"`
+	Expenses []CreateExpenseResponse `json:"expenses"`
+}
+
+type ImportRuleResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Pattern    string     `json:"pattern"`
+	BudgetID   uuid.UUID  `json:"budget_id"`
+	CategoryID *uuid.UUID `json:"category_id"`
+	Priority   int        `json:"priority"`
+}
+
+type ImportRuleListResponse struct {
+	Rules []ImportRuleResponse `json:"rules"`
+	Total int                  `json:"total"`
+}
@@ -0,0 +1,52 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
+
+type RecurringExpenseResponse struct {
+	ID          uuid.UUID                  `json:"id"`
+	BudgetID    uuid.UUID                  `json:"budget_id"`
+	BudgetName  string                     `json:"budget_name"`
+	CategoryID  *uuid.UUID                 `json:"category_id"`
+	Amount      models.Money               `json:"amount"`
+	Description string                     `json:"description"`
+	Tags        []string                   `json:"tags"`
+	Frequency   models.RecurrenceFrequency `json:"frequency"`
+	Interval    int                        `json:"interval"`
+	StartDate   time.Time                  `json:"start_date"`
+	EndDate     *time.Time                 `json:"end_date,omitempty"`
+	Count       int                        `json:"count,omitempty"`
+	RRule       string                     `json:"rrule"`
+	NextDate    *time.Time                 `json:"next_date"`
+	// LastError is why the last scheduler run could not handle the
+	// recurring expense; it is cleared once a run succeeds
+	LastError    string     `json:"last_error,omitempty"`
+	LastFailedAt *time.Time `json:"last_failed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type RecurringExpenseListResponse struct {
+	RecurringExpenses []RecurringExpenseResponse `json:"recurring_expenses"`
+	Total             int                        `json:"total"`
+}
+
+// OccurrenceResponse is one upcoming occurrence of a recurring expense
+type OccurrenceResponse struct {
+	RecurringExpenseID uuid.UUID               `json:"recurring_expense_id"`
+	Date               time.Time               `json:"date"`
+	BudgetID           uuid.UUID               `json:"budget_id"`
+	BudgetName         string                  `json:"budget_name"`
+	Amount             models.Money            `json:"amount"`
+	Description        string                  `json:"description"`
+	Status             models.OccurrenceStatus `json:"status"`
+	Edited             bool                    `json:"edited"`
+}
+
+type OccurrenceListResponse struct {
+	Occurrences []OccurrenceResponse `json:"occurrences"`
+	Total       int                  `json:"total"`
+}
@@ -0,0 +1,32 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+)
+
+type ReportGroupResponse struct {
+	Key     string       `json:"key"`
+	Label   string       `json:"label,omitempty"`
+	Total   models.Money `json:"total"`
+	Count   int          `json:"count"`
+	Average models.Money `json:"average"`
+}
+
+// ReportSummaryResponse is used for GET /reports/summary. The previous period
+// has the same length and ends where this one starts.
+type ReportSummaryResponse struct {
+	From          time.Time             `json:"from"`
+	To            time.Time             `json:"to"`
+	GroupBy       string                `json:"group_by"`
+	Total         models.Money          `json:"total"`
+	Count         int                   `json:"count"`
+	Average       models.Money          `json:"average"`
+	DailyAverage  models.Money          `json:"daily_average"`
+	Groups        []ReportGroupResponse `json:"groups"`
+	TopBudgets    []ReportGroupResponse `json:"top_budgets"`
+	PreviousTotal models.Money          `json:"previous_total"`
+	Change        models.Money          `json:"change"`
+	ChangePercent *float64              `json:"change_percent"`
+}
@@ -0,0 +1,33 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
+
+// TransactionResponse is one entry of the unified ledger
+type TransactionResponse struct {
+	ID           uuid.UUID              `json:"id"`
+	Type         models.TransactionType `json:"type"`
+	Amount       models.Money           `json:"amount"`
+	Description  string                 `json:"description"`
+	Date         time.Time              `json:"date"`
+	BudgetID     *uuid.UUID             `json:"budget_id"`
+	BudgetName   string                 `json:"budget_name,omitempty"`
+	ToBudgetID   *uuid.UUID             `json:"to_budget_id,omitempty"`
+	ToBudgetName string                 `json:"to_budget_name,omitempty"`
+	TopUpBudget  bool                   `json:"top_up_budget,omitempty"`
+	Status       models.ExpenseStatus   `json:"status,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+}
+
+type LedgerResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	Total        int                   `json:"total"`
+	NextCursor   string                `json:"next_cursor,omitempty"`
+	TotalIncome  models.Money          `json:"total_income"`
+	TotalExpense models.Money          `json:"total_expense"`
+	NetCashFlow  models.Money          `json:"net_cash_flow"`
+}
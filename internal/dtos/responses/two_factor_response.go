@@ -0,0 +1,16 @@
+package responses
+
+type TwoFactorStatusResponse struct {
+	Enabled           bool  `json:"enabled"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret string `json:"secret"`
+	// OTPAuthURI is meant to be shown as a QR code
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,33 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
+
+type WorkspaceResponse struct {
+	ID        uuid.UUID            `json:"id"`
+	Name      string               `json:"name"`
+	Personal  bool                 `json:"personal"`
+	Role      models.WorkspaceRole `json:"role"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// WorkspaceListResponse lists the personal workspace first.
+type WorkspaceListResponse struct {
+	Workspaces []WorkspaceResponse `json:"workspaces"`
+}
+
+type WorkspaceMemberResponse struct {
+	UserID   uuid.UUID            `json:"user_id"`
+	Name     string               `json:"name"`
+	Email    string               `json:"email"`
+	Role     models.WorkspaceRole `json:"role"`
+	JoinedAt time.Time            `json:"joined_at"`
+}
+
+type WorkspaceMemberListResponse struct {
+	Members []WorkspaceMemberResponse `json:"members"`
+}
@@ -0,0 +1,77 @@
+package exporters
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+)
+
+// csvHeader is shared by budget and expense rows; the record column tells
+// them apart so spreadsheets can filter on it.
+var csvHeader = []string{"record", "id", "date", "budget_id", "budget", "category", "description", "amount", "spent", "tags"}
+
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	// A UTF-8 byte order mark makes Excel read non-ASCII text correctly
+	io.WriteString(w, "\ufeff")
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) header() error {
+	if c.wroteHeader {
+		return nil
+	}
+	c.wroteHeader = true
+	return c.w.Write(csvHeader)
+}
+
+func (c *csvWriter) WriteBudgets(budgets []models.Budget) error {
+	if err := c.header(); err != nil {
+		return err
+	}
+	for i := range budgets {
+		record := toBudgetRecord(&budgets[i])
+		err := c.w.Write([]string{
+			"budget", record.ID.String(), "", record.ID.String(), record.Name, "",
+			record.Description, record.Amount.String(), record.Spent.String(), "",
+		})
+		if err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) WriteExpenses(expenses []models.Expense) error {
+	if err := c.header(); err != nil {
+		return err
+	}
+	for i := range expenses {
+		record := toExpenseRecord(&expenses[i])
+		err := c.w.Write([]string{
+			"expense", record.ID.String(), record.Date.Format(time.RFC3339), record.BudgetID.String(), record.BudgetName,
+			record.Category, record.Description, record.Amount.String(), "", strings.Join(record.Tags, ";"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.header(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,109 @@
+// Package exporters writes budgets and expenses to downloadable formats one
+// batch at a time, so an export never has to hold every row in memory.
+package exporters
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+	FormatOFX  Format = "ofx"
+)
+
+// Writer receives the budgets first and then the expenses in batches.
+// Close finishes the document; nothing may be written after it.
+type Writer interface {
+	WriteBudgets(budgets []models.Budget) error
+	WriteExpenses(expenses []models.Expense) error
+	Close() error
+}
+
+func NewWriter(format Format, w io.Writer, exportedAt time.Time) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatJSON:
+		return newJSONWriter(w, exportedAt), nil
+	case FormatOFX:
+		return newOFXWriter(w, exportedAt), nil
+	default:
+		return nil, errors.New("unsupported export format")
+	}
+}
+
+// ContentType returns the MIME type of an export format.
+func ContentType(format Format) string {
+	switch format {
+	case FormatJSON:
+		return "application/json"
+	case FormatOFX:
+		return "application/x-ofx"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+type budgetRecord struct {
+	ID             uuid.UUID             `json:"id"`
+	Name           string                `json:"name"`
+	Description    string                `json:"description"`
+	Amount         models.Money          `json:"amount"`
+	Spent          models.Money          `json:"spent"`
+	Period         models.BudgetPeriod   `json:"period"`
+	RolloverPolicy models.RolloverPolicy `json:"rollover_policy"`
+	PeriodStart    *time.Time            `json:"period_start"`
+	PeriodEnd      *time.Time            `json:"period_end"`
+}
+
+type expenseRecord struct {
+	ID          uuid.UUID    `json:"id"`
+	Date        time.Time    `json:"date"`
+	BudgetID    uuid.UUID    `json:"budget_id"`
+	BudgetName  string       `json:"budget_name"`
+	Category    string       `json:"category,omitempty"`
+	Description string       `json:"description"`
+	Amount      models.Money `json:"amount"`
+	Tags        []string     `json:"tags"`
+}
+
+func toBudgetRecord(budget *models.Budget) budgetRecord {
+	return budgetRecord{
+		ID:             budget.ID,
+		Name:           budget.Name,
+		Description:    budget.Description,
+		Amount:         budget.Amount,
+		Spent:          budget.Spent,
+		Period:         budget.Period,
+		RolloverPolicy: budget.RolloverPolicy,
+		PeriodStart:    budget.PeriodStart,
+		PeriodEnd:      budget.PeriodEnd,
+	}
+}
+
+func toExpenseRecord(expense *models.Expense) expenseRecord {
+	record := expenseRecord{
+		ID:          expense.ID,
+		Date:        expense.Date,
+		BudgetID:    expense.BudgetID,
+		BudgetName:  expense.Budget.Name,
+		Description: expense.Description,
+		Amount:      expense.Amount,
+		Tags:        []string{},
+	}
+	if expense.Category != nil {
+		record.Category = expense.Category.Name
+	}
+	for _, tag := range expense.Tags {
+		record.Tags = append(record.Tags, tag.Name)
+	}
+	return record
+}
@@ -0,0 +1,78 @@
+package exporters
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+)
+
+// jsonWriter streams {"exported_at":...,"budgets":[...],"expenses":[...]}
+// by hand so expenses can be appended batch by batch.
+type jsonWriter struct {
+	w             io.Writer
+	exportedAt    time.Time
+	wroteBudgets  bool
+	wroteExpenses bool
+	err           error
+}
+
+func newJSONWriter(w io.Writer, exportedAt time.Time) *jsonWriter {
+	return &jsonWriter{w: w, exportedAt: exportedAt}
+}
+
+func (j *jsonWriter) write(s string) {
+	if j.err == nil {
+		_, j.err = io.WriteString(j.w, s)
+	}
+}
+
+func (j *jsonWriter) writeValue(v any) {
+	if j.err != nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		j.err = err
+		return
+	}
+	_, j.err = j.w.Write(data)
+}
+
+func (j *jsonWriter) WriteBudgets(budgets []models.Budget) error {
+	records := make([]budgetRecord, 0, len(budgets))
+	for i := range budgets {
+		records = append(records, toBudgetRecord(&budgets[i]))
+	}
+
+	j.write(`{"exported_at":`)
+	j.writeValue(j.exportedAt)
+	j.write(`,"budgets":`)
+	j.writeValue(records)
+	j.write(`,"expenses":[`)
+	j.wroteBudgets = true
+	return j.err
+}
+
+func (j *jsonWriter) WriteExpenses(expenses []models.Expense) error {
+	if !j.wroteBudgets {
+		j.WriteBudgets(nil)
+	}
+	for i := range expenses {
+		if j.wroteExpenses {
+			j.write(",")
+		}
+		j.writeValue(toExpenseRecord(&expenses[i]))
+		j.wroteExpenses = true
+	}
+	return j.err
+}
+
+func (j *jsonWriter) Close() error {
+	if !j.wroteBudgets {
+		j.WriteBudgets(nil)
+	}
+	j.write("]}\n")
+	return j.err
+}
@@ -0,0 +1,74 @@
+package exporters
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+)
+
+const ofxDateFormat = "20060102150405"
+
+// ofxWriter writes expenses as debit transactions of an OFX 2 bank
+// statement. OFX has no notion of budgets, so they are skipped.
+type ofxWriter struct {
+	w          io.Writer
+	exportedAt time.Time
+	started    bool
+	err        error
+}
+
+func newOFXWriter(w io.Writer, exportedAt time.Time) *ofxWriter {
+	return &ofxWriter{w: w, exportedAt: exportedAt}
+}
+
+func (o *ofxWriter) printf(format string, args ...any) {
+	if o.err == nil {
+		_, o.err = fmt.Fprintf(o.w, format, args...)
+	}
+}
+
+func (o *ofxWriter) start() {
+	if o.started {
+		return
+	}
+	o.started = true
+	now := o.exportedAt.Format(ofxDateFormat)
+	o.printf(`<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX><SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><STMTRS><CURDEF>IDR</CURDEF>
+<BANKACCTFROM><BANKID>DOMPET</BANKID><ACCTID>DOMPET</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST>
+`, now)
+}
+
+func (o *ofxWriter) WriteBudgets(budgets []models.Budget) error {
+	o.start()
+	return o.err
+}
+
+func (o *ofxWriter) WriteExpenses(expenses []models.Expense) error {
+	o.start()
+	for i := range expenses {
+		record := toExpenseRecord(&expenses[i])
+		o.printf("<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+			record.Date.Format(ofxDateFormat), (-record.Amount).String(), record.ID, escapeXML(record.Description), escapeXML(record.BudgetName))
+	}
+	return o.err
+}
+
+func (o *ofxWriter) Close() error {
+	o.start()
+	o.printf("</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\n")
+	return o.err
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package importers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+func parseCSV(r io.Reader, opts Options) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = opts.Delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("csv file has no header row")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	index := func(name string) (int, error) {
+		i, ok := columns[strings.ToLower(name)]
+		if !ok {
+			return 0, fmt.Errorf("csv column %q not found", name)
+		}
+		return i, nil
+	}
+	dateCol, err := index(opts.DateColumn)
+	if err != nil {
+		return nil, err
+	}
+	amountCol, err := index(opts.AmountColumn)
+	if err != nil {
+		return nil, err
+	}
+	descriptionCol, err := index(opts.DescriptionColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	dateFormat := opts.DateFormat
+	if dateFormat == "" {
+		dateFormat = time.DateOnly
+	}
+
+	var rows []Row
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(i int) string {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := Row{Line: line, Description: field(descriptionCol)}
+		if row.Date, err = time.ParseInLocation(dateFormat, field(dateCol), time.Local); err != nil {
+			row.Error = "invalid date"
+		} else if amount, err := parseAmount(field(amountCol), opts.DecimalComma); err != nil {
+			row.Error = "invalid amount"
+		} else {
+			if opts.NegativeExpenses {
+				amount = -amount
+			}
+			// Credits such as salary or refunds are no expenses
+			if amount <= 0 {
+				continue
+			}
+			row.Amount = amount
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,101 @@
+// Package importers parses bank statement exports into expense rows.
+package importers
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+)
+
+type Format string
+
+const (
+	FormatCSV Format = "csv"
+	FormatOFX Format = "ofx"
+	FormatQIF Format = "qif"
+)
+
+// Row is one parsed statement line. Amount is the positive amount spent.
+// Rows that could not be parsed carry an Error and are never imported.
+type Row struct {
+	Line        int
+	Date        time.Time
+	Amount      models.Money
+	Description string
+	Error       string
+}
+
+// Options tune how a statement is read. Column names refer to the CSV
+// header; DateFormat applies to CSV and QIF dates. CSV amounts are spending
+// when positive, or when negative if NegativeExpenses is set; rows of the
+// other sign are credits and are skipped.
+type Options struct {
+	DateColumn        string
+	AmountColumn      string
+	DescriptionColumn string
+	DateFormat        string
+	Delimiter         rune
+	DecimalComma      bool
+	NegativeExpenses  bool
+}
+
+func (o Options) withDefaults() Options {
+	if o.DateColumn == "" {
+		o.DateColumn = "date"
+	}
+	if o.AmountColumn == "" {
+		o.AmountColumn = "amount"
+	}
+	if o.DescriptionColumn == "" {
+		o.DescriptionColumn = "description"
+	}
+	if o.Delimiter == 0 {
+		o.Delimiter = ','
+	}
+	return o
+}
+
+// DetectFormat guesses the format from a file name when none is given.
+func DetectFormat(filename string) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ofx", ".qfx":
+		return FormatOFX
+	case ".qif":
+		return FormatQIF
+	default:
+		return FormatCSV
+	}
+}
+
+// Parse reads a whole statement in the given format.
+func Parse(format Format, r io.Reader, opts Options) ([]Row, error) {
+	opts = opts.withDefaults()
+	switch format {
+	case FormatCSV:
+		return parseCSV(r, opts)
+	case FormatOFX:
+		return parseOFX(r)
+	case FormatQIF:
+		return parseQIF(r, opts)
+	default:
+		return nil, errors.New("unsupported import format")
+	}
+}
+
+// parseAmount reads a statement amount such as "-1,234.50" or, with
+// decimalComma, "-1.234,50".
+func parseAmount(s string, decimalComma bool) (models.Money, error) {
+	s = strings.TrimSpace(s)
+	if decimalComma {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	} else {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	s = strings.ReplaceAll(s, " ", "")
+	return models.ParseMoney(s)
+}
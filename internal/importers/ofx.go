@@ -0,0 +1,93 @@
+package importers
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// parseOFX reads the STMTTRN blocks of an OFX/QFX file. Both the SGML
+// flavour without closing tags and the XML flavour are understood. Only
+// debits (negative TRNAMT) are returned.
+func parseOFX(r io.Reader) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []Row
+	var current map[string]string
+	start := 0
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		for text != "" {
+			tag, value, rest := nextOFXElement(text)
+			text = rest
+
+			switch tag {
+			case "STMTTRN":
+				current, start = map[string]string{}, line
+			case "/STMTTRN":
+				if current != nil {
+					if row, ok := ofxRow(current, start); ok {
+						rows = append(rows, row)
+					}
+				}
+				current = nil
+			default:
+				if current != nil && value != "" {
+					current[tag] = value
+				}
+			}
+		}
+	}
+	return rows, scanner.Err()
+}
+
+// nextOFXElement splits "<TAG>value<NEXT>..." into TAG, value and the rest.
+func nextOFXElement(text string) (string, string, string) {
+	open := strings.IndexByte(text, '<')
+	if open < 0 {
+		return "", "", ""
+	}
+	end := strings.IndexByte(text[open:], '>')
+	if end < 0 {
+		return "", "", ""
+	}
+	tag := strings.ToUpper(text[open+1 : open+end])
+	rest := text[open+end+1:]
+
+	value := rest
+	if next := strings.IndexByte(rest, '<'); next >= 0 {
+		value, rest = rest[:next], rest[next:]
+	} else {
+		rest = ""
+	}
+	return tag, strings.TrimSpace(value), rest
+}
+
+func ofxRow(fields map[string]string, line int) (Row, bool) {
+	row := Row{Line: line, Description: fields["NAME"]}
+	if memo := fields["MEMO"]; memo != "" && memo != row.Description {
+		row.Description = strings.TrimSpace(row.Description + " " + memo)
+	}
+
+	amount, err := parseAmount(fields["TRNAMT"], false)
+	if err != nil {
+		row.Error = "invalid amount"
+		return row, true
+	}
+	if amount >= 0 {
+		return row, false
+	}
+	row.Amount = -amount
+
+	posted := fields["DTPOSTED"]
+	if len(posted) < 8 {
+		row.Error = "invalid date"
+		return row, true
+	}
+	if row.Date, err = time.ParseInLocation("20060102", posted[:8], time.Local); err != nil {
+		row.Error = "invalid date"
+	}
+	return row, true
+}
@@ -0,0 +1,73 @@
+package importers
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// qifDateFormats are the usual QIF date spellings, month first.
+var qifDateFormats = []string{"1/2/2006", "1/2/06", "2006-01-02"}
+
+// parseQIF reads the transactions of a QIF file. Only debits (negative T
+// amounts) are returned.
+func parseQIF(r io.Reader, opts Options) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+
+	formats := qifDateFormats
+	if opts.DateFormat != "" {
+		formats = []string{opts.DateFormat}
+	}
+
+	var rows []Row
+	fields := map[byte]string{}
+	start := 0
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "!") {
+			continue
+		}
+		if text[0] != '^' {
+			if len(fields) == 0 {
+				start = line
+			}
+			fields[text[0]] = strings.TrimSpace(text[1:])
+			continue
+		}
+
+		if row, ok := qifRow(fields, start, formats, opts.DecimalComma); ok {
+			rows = append(rows, row)
+		}
+		fields = map[byte]string{}
+	}
+	return rows, scanner.Err()
+}
+
+func qifRow(fields map[byte]string, line int, formats []string, decimalComma bool) (Row, bool) {
+	row := Row{Line: line, Description: fields['P']}
+	if memo := fields['M']; memo != "" {
+		row.Description = strings.TrimSpace(row.Description + " " + memo)
+	}
+
+	amount, err := parseAmount(fields['T'], decimalComma)
+	if err != nil {
+		row.Error = "invalid amount"
+		return row, true
+	}
+	if amount >= 0 {
+		return row, false
+	}
+	row.Amount = -amount
+
+	// Quicken writes 3/10'25 for 3/10/2025
+	date := strings.ReplaceAll(strings.ReplaceAll(fields['D'], "'", "/"), " ", "")
+	row.Error = "invalid date"
+	for _, format := range formats {
+		if parsed, err := time.ParseInLocation(format, date, time.Local); err == nil {
+			row.Date, row.Error = parsed, ""
+			break
+		}
+	}
+	return row, true
+}
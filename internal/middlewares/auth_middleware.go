@@ -2,13 +2,37 @@ package middlewares
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
+	"github.com/Alvarras/dompet-g0/internal/models"
 	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-func AuthMiddleware(jwtSecret string) echo.MiddlewareFunc {
+// TokenDenylist reports whether a valid access token was revoked before expiry.
+type TokenDenylist interface {
+	IsTokenRevoked(claims *utils.JWTClaims) (bool, error)
+}
+
+// WorkspaceResolver decides which workspace a request works in and the
+// caller's role there. A nil workspace ID asks for the personal workspace.
+type WorkspaceResolver interface {
+	ResolveWorkspace(userID uuid.UUID, workspaceID uuid.UUID) (uuid.UUID, models.WorkspaceRole, error)
+}
+
+// WorkspaceHeader selects the workspace of a request.
+const WorkspaceHeader = "X-Workspace-ID"
+
+// APIKeyAuthenticator looks up the API key a request was made with.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(key string) (*models.APIKey, error)
+}
+
+// AuthMiddleware accepts a JWT access token or an API key as Bearer token.
+// Requests made with an API key carry its scopes, see RequireScope.
+func AuthMiddleware(keys *utils.KeySet, denylist TokenDenylist, apiKeys APIKeyAuthenticator) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get("Authorization")
@@ -21,13 +45,122 @@ func AuthMiddleware(jwtSecret string) echo.MiddlewareFunc {
 				return echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization header format")
 			}
 
-			claims, err := utils.ValidateToken(parts[1], jwtSecret)
+			if strings.HasPrefix(parts[1], utils.APIKeyPrefix) {
+				apiKey, err := apiKeys.AuthenticateAPIKey(parts[1])
+				if err != nil {
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid api key")
+				}
+
+				c.Set("user_id", apiKey.UserID)
+				c.Set("api_key_id", apiKey.ID)
+				c.Set("scopes", apiKey.ScopeList())
+
+				return next(c)
+			}
+
+			claims, err := utils.ValidateToken(parts[1], keys)
 			if err != nil {
+				if _, mfaErr := utils.ValidateMFAToken(parts[1], keys); mfaErr == nil {
+					return echo.NewHTTPError(http.StatusUnauthorized, "two-factor authentication required")
+				}
 				return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
 			}
 
+			revoked, err := denylist.IsTokenRevoked(claims)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to check token")
+			}
+			if revoked {
+				return echo.NewHTTPError(http.StatusUnauthorized, "token has been revoked")
+			}
+
 			c.Set("user_id", claims.UserID)
 			c.Set("email", claims.Email)
+			c.Set("jti", claims.ID)
+			c.Set("token_expires_at", claims.ExpiresAt.Time)
+			c.Set("limited", claims.Limited)
+
+			return next(c)
+		}
+	}
+}
+
+// LimitUnverified makes limited tokens read-only. Paths in allowed may still
+// be written to, e.g. so an unverified user can log out.
+func LimitUnverified(allowed ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			limited, _ := c.Get("limited").(bool)
+			if !limited {
+				return next(c)
+			}
+
+			method := c.Request().Method
+			if method == http.MethodGet || method == http.MethodHead {
+				return next(c)
+			}
+			for _, path := range allowed {
+				if c.Path() == path {
+					return next(c)
+				}
+			}
+			return echo.NewHTTPError(http.StatusForbidden, "email not verified")
+		}
+	}
+}
+
+// RequireScope lets API key requests through only if the key has the given
+// scope. Requests with a JWT are not restricted.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			scopes, ok := c.Get("scopes").([]string)
+			if ok && !slices.Contains(scopes, scope) {
+				return echo.NewHTTPError(http.StatusForbidden, "api key is missing scope "+scope)
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireSession refuses API keys, for account management routes that need
+// a signed-in user.
+func RequireSession() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Get("api_key_id") != nil {
+				return echo.NewHTTPError(http.StatusForbidden, "not available with an api key")
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireWorkspace resolves the workspace named by the X-Workspace-ID header,
+// or the caller's personal workspace without it, and stores its ID and the
+// caller's role for the handlers.
+func RequireWorkspace(workspaces WorkspaceResolver) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requested := uuid.Nil
+			if header := c.Request().Header.Get(WorkspaceHeader); header != "" {
+				id, err := uuid.Parse(header)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusBadRequest, "invalid workspace id")
+				}
+				requested = id
+			}
+
+			workspaceID, role, err := workspaces.ResolveWorkspace(c.Get("user_id").(uuid.UUID), requested)
+			if err != nil {
+				if err.Error() == "workspace not found" {
+					return echo.NewHTTPError(http.StatusForbidden, "no access to workspace")
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to resolve workspace")
+			}
+
+			c.Set("workspace_id", workspaceID)
+			c.Set("workspace_role", role)
 
 			return next(c)
 		}
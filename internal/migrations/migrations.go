@@ -0,0 +1,168 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"gorm.io/gorm"
+)
+
+// Run brings the database schema up to date. Data migrations that must see
+// the old column layout run before AutoMigrate touches the tables.
+func Run(db *gorm.DB) error {
+	if err := migrateMoneyColumns(db); err != nil {
+		return fmt.Errorf("money columns: %w", err)
+	}
+
+	// Accounts created before email verification existed count as verified
+	migrator := db.Migrator()
+	backfillVerified := migrator.HasTable(&models.User{}) && !migrator.HasColumn(&models.User{}, "EmailVerifiedAt")
+	// Data recorded before workspaces existed moves into personal workspaces
+	backfillWorkspaces := migrator.HasTable(&models.User{}) && !migrator.HasTable(&models.Workspace{})
+
+	err := db.AutoMigrate(
+		&models.User{},
+		&models.Budget{},
+		&models.Category{},
+		&models.Tag{},
+		&models.Expense{},
+		&models.BudgetHistory{},
+		&models.Income{},
+		&models.Transfer{},
+		&models.ImportRule{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.UserToken{},
+		&models.RecoveryCode{},
+		&models.LoginAttempt{},
+		&models.APIKey{},
+		&models.BudgetMember{},
+		&models.BudgetInvitation{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.ExpenseApproval{},
+		&models.RecurringExpense{},
+		&models.RecurringOccurrence{},
+		&models.BudgetAlert{},
+		&models.WebhookDelivery{},
+	)
+	if err != nil {
+		return err
+	}
+
+	if backfillVerified {
+		if err := db.Exec("UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL").Error; err != nil {
+			return fmt.Errorf("email verification backfill: %w", err)
+		}
+	}
+
+	// Tag names used to be unique per user, now per user and workspace
+	if migrator.HasIndex(&models.Tag{}, "idx_tags_user_name") {
+		if err := migrator.DropIndex(&models.Tag{}, "idx_tags_user_name"); err != nil {
+			return err
+		}
+	}
+
+	if backfillWorkspaces {
+		if err := migrateToWorkspaces(db); err != nil {
+			return fmt.Errorf("workspace backfill: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateToWorkspaces gives every user a personal workspace, which shares
+// the user's ID, and moves the user's data into it. Expenses and everything
+// else hanging off a budget follow the budget, so expenses members recorded
+// in shared budgets end up in the workspace of the budget owner.
+func migrateToWorkspaces(db *gorm.DB) error {
+	statements := []string{
+		"INSERT INTO workspaces (id, name, personal, created_at, updated_at) SELECT id, name, TRUE, created_at, created_at FROM users",
+		"INSERT INTO workspace_members (id, workspace_id, user_id, role, created_at, updated_at) SELECT UUID(), id, id, 'owner', created_at, created_at FROM users",
+	}
+	for _, table := range []string{"budgets", "categories", "tags", "expenses", "incomes", "transfers", "import_rules"} {
+		statements = append(statements, fmt.Sprintf("UPDATE `%s` SET workspace_id = user_id", table))
+	}
+	for _, table := range []string{"expenses", "budget_histories", "budget_members", "budget_invitations"} {
+		statements = append(statements, fmt.Sprintf("UPDATE `%[1]s` JOIN budgets ON budgets.id = `%[1]s`.budget_id SET `%[1]s`.workspace_id = budgets.workspace_id", table))
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// moneyColumns lists the columns that used to be float64 amounts.
+var moneyColumns = map[string][]string{
+	"budgets":  {"amount", "spent"},
+	"expenses": {"amount"},
+}
+
+// migrateMoneyColumns converts legacy floating point amount columns into
+// BIGINT minor units. Each column is copied into a temporary column, the old
+// column is dropped and the new one renamed, so a run interrupted at any step
+// can simply be repeated.
+func migrateMoneyColumns(db *gorm.DB) error {
+	migrator := db.Migrator()
+
+	for table, columns := range moneyColumns {
+		if !migrator.HasTable(table) {
+			continue
+		}
+
+		for _, column := range columns {
+			tmp := column + "_minor"
+
+			legacy, err := isFloatColumn(db, table, column)
+			if err != nil {
+				return err
+			}
+
+			if legacy {
+				if !migrator.HasColumn(table, tmp) {
+					if err := db.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `%s` BIGINT NOT NULL DEFAULT 0", table, tmp)).Error; err != nil {
+						return err
+					}
+				}
+				if err := db.Exec(fmt.Sprintf("UPDATE `%s` SET `%s` = ROUND(`%s` * %d)", table, tmp, column, models.MoneyScale)).Error; err != nil {
+					return err
+				}
+				if err := migrator.DropColumn(table, column); err != nil {
+					return err
+				}
+			}
+
+			if !migrator.HasColumn(table, column) && migrator.HasColumn(table, tmp) {
+				if err := migrator.RenameColumn(table, tmp, column); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func isFloatColumn(db *gorm.DB, table, column string) (bool, error) {
+	columnTypes, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return false, err
+	}
+
+	for _, columnType := range columnTypes {
+		if columnType.Name() != column {
+			continue
+		}
+		switch strings.ToLower(columnType.DatabaseTypeName()) {
+		case "double", "float", "decimal", "real":
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API key scopes. Each one grants access to a group of routes; see
+// routes.SetupRoutes for where they are enforced.
+const (
+	ScopeBudgetsRead       = "budgets:read"
+	ScopeBudgetsWrite      = "budgets:write"
+	ScopeExpensesRead      = "expenses:read"
+	ScopeExpensesWrite     = "expenses:write"
+	ScopeCategoriesRead    = "categories:read"
+	ScopeCategoriesWrite   = "categories:write"
+	ScopeTransactionsRead  = "transactions:read"
+	ScopeTransactionsWrite = "transactions:write"
+	ScopeReportsRead       = "reports:read"
+	ScopeImportsRead       = "imports:read"
+	ScopeImportsWrite      = "imports:write"
+	ScopeExportsRead       = "exports:read"
+	ScopeBackupRead        = "backup:read"
+	ScopeBackupWrite       = "backup:write"
+)
+
+// APIScopes lists every scope an API key can be given
+var APIScopes = []string{
+	ScopeBudgetsRead, ScopeBudgetsWrite,
+	ScopeExpensesRead, ScopeExpensesWrite,
+	ScopeCategoriesRead, ScopeCategoriesWrite,
+	ScopeTransactionsRead, ScopeTransactionsWrite,
+	ScopeReportsRead,
+	ScopeImportsRead, ScopeImportsWrite,
+	ScopeExportsRead,
+	ScopeBackupRead, ScopeBackupWrite,
+}
+
+// APIKey is a long-lived credential for scripts and integrations. Only the
+// SHA-256 hash of the key is stored; Prefix is kept so users can tell their
+// keys apart.
+type APIKey struct {
+	ID      uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	UserID  uuid.UUID `gorm:"type:char(36);not null;index" json:"user_id"`
+	Name    string    `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix  string    `gorm:"type:varchar(16);not null" json:"prefix"`
+	KeyHash string    `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	// Scopes is a space separated list, like an OAuth scope parameter
+	Scopes     string     `gorm:"type:varchar(512);not null" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+// Active reports whether the key can be used at now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -7,15 +7,151 @@ import (
 	"gorm.io/gorm"
 )
 
+type BudgetPeriod string
+
+const (
+	BudgetPeriodNone    BudgetPeriod = "none"
+	BudgetPeriodWeekly  BudgetPeriod = "weekly"
+	BudgetPeriodMonthly BudgetPeriod = "monthly"
+	BudgetPeriodYearly  BudgetPeriod = "yearly"
+	BudgetPeriodCustom  BudgetPeriod = "custom"
+)
+
+// RolloverPolicy decides what happens to the remaining balance when a period ends.
+type RolloverPolicy string
+
+const (
+	RolloverReset        RolloverPolicy = "reset"
+	RolloverCarrySurplus RolloverPolicy = "carry_surplus"
+	RolloverCarryDeficit RolloverPolicy = "carry_deficit"
+)
+
 type Budget struct {
-	ID          uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
-	UserID      uuid.UUID      `gorm:"type:char(36);not null" json:"user_id"`
-	Name        string         `gorm:"not null" json:"name"`
-	Amount      float64        `gorm:"not null" json:"amount"`
-	Spent       float64        `gorm:"default:0" json:"spent"`
-	Description string         `json:"description"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
-	User        User           `gorm:"foreignKey:UserID" json:"user"`
+	ID                uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID       uuid.UUID      `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	UserID            uuid.UUID      `gorm:"type:char(36);not null" json:"user_id"`
+	Name              string         `gorm:"not null" json:"name"`
+	Amount            Money          `gorm:"type:bigint;not null" json:"amount"`
+	Spent             Money          `gorm:"type:bigint;not null;default:0" json:"spent"`
+	Description       string         `json:"description"`
+	Period            BudgetPeriod   `gorm:"type:varchar(16);not null;default:none" json:"period"`
+	PeriodAnchor      *time.Time     `json:"period_anchor"`
+	PeriodDays        int            `gorm:"not null;default:0" json:"period_days"`
+	PeriodStart       *time.Time     `gorm:"index" json:"period_start"`
+	PeriodEnd         *time.Time     `gorm:"index" json:"period_end"`
+	RolloverPolicy    RolloverPolicy `gorm:"type:varchar(16);not null;default:reset" json:"rollover_policy"`
+	CarryOver         Money          `gorm:"type:bigint;not null;default:0" json:"carry_over"`
+	ApprovalThreshold Money          `gorm:"type:bigint;not null;default:0" json:"approval_threshold"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+	User              User           `gorm:"foreignKey:UserID" json:"user"`
+}
+
+// Available is the allocation for the current period including any carry over.
+func (b *Budget) Available() Money {
+	return b.Amount + b.CarryOver
+}
+
+// Remaining is what can still be spent in the current period.
+func (b *Budget) Remaining() Money {
+	return b.Available() - b.Spent
+}
+
+// NeedsApproval reports whether an expense of the given amount, recorded by
+// a user with the given role, has to be approved before it counts. Expenses
+// above ApprovalThreshold wait for someone who may manage the budget; a zero
+// threshold turns approval off.
+func (b *Budget) NeedsApproval(role BudgetRole, amount Money) bool {
+	return b.ApprovalThreshold > 0 && amount > b.ApprovalThreshold && !role.Allows(BudgetActionManage)
+}
+
+// IsPeriodic reports whether the budget resets on a schedule.
+func (b *Budget) IsPeriodic() bool {
+	return b.Period != "" && b.Period != BudgetPeriodNone && b.PeriodAnchor != nil
+}
+
+// InCurrentPeriod reports whether an expense on the given date counts
+// towards Spent. Budgets without a period count every expense.
+func (b *Budget) InCurrentPeriod(date time.Time) bool {
+	if !b.IsPeriodic() || b.PeriodStart == nil || b.PeriodEnd == nil {
+		return true
+	}
+	return !date.Before(*b.PeriodStart) && date.Before(*b.PeriodEnd)
+}
+
+// RolloverDue reports whether the current period has ended at now.
+func (b *Budget) RolloverDue(now time.Time) bool {
+	return b.IsPeriodic() && b.PeriodStart != nil && b.PeriodEnd != nil && !now.Before(*b.PeriodEnd)
+}
+
+// PeriodContaining returns the [start, end) window of the period that t
+// falls in. Periods are counted from PeriodAnchor, so a monthly budget
+// anchored on the 31st starts on the last day of shorter months.
+func (b *Budget) PeriodContaining(t time.Time) (time.Time, time.Time) {
+	if !b.IsPeriodic() {
+		return time.Time{}, time.Time{}
+	}
+
+	n := b.estimatePeriodIndex(t)
+	for b.periodStart(n).After(t) {
+		n--
+	}
+	for !b.periodStart(n + 1).After(t) {
+		n++
+	}
+	return b.periodStart(n), b.periodStart(n + 1)
+}
+
+// NextPeriod returns the window that follows the period starting at start.
+func (b *Budget) NextPeriod(start time.Time) (time.Time, time.Time) {
+	_, end := b.PeriodContaining(start)
+	return b.PeriodContaining(end)
+}
+
+func (b *Budget) periodStart(n int) time.Time {
+	anchor := *b.PeriodAnchor
+	switch b.Period {
+	case BudgetPeriodWeekly:
+		return anchor.AddDate(0, 0, 7*n)
+	case BudgetPeriodMonthly:
+		return addMonthsClamped(anchor, n)
+	case BudgetPeriodYearly:
+		return addMonthsClamped(anchor, 12*n)
+	default:
+		return anchor.AddDate(0, 0, b.periodLengthDays()*n)
+	}
+}
+
+func (b *Budget) estimatePeriodIndex(t time.Time) int {
+	anchor := *b.PeriodAnchor
+	switch b.Period {
+	case BudgetPeriodMonthly:
+		return (t.Year()-anchor.Year())*12 + int(t.Month()) - int(anchor.Month())
+	case BudgetPeriodYearly:
+		return t.Year() - anchor.Year()
+	case BudgetPeriodWeekly:
+		return int(t.Sub(anchor).Hours() / 24 / 7)
+	default:
+		return int(t.Sub(anchor).Hours() / 24 / float64(b.periodLengthDays()))
+	}
+}
+
+func (b *Budget) periodLengthDays() int {
+	if b.PeriodDays < 1 {
+		return 1
+	}
+	return b.PeriodDays
+}
+
+// addMonthsClamped adds n months to t, clamping the day to the end of the
+// target month instead of overflowing into the next one.
+func addMonthsClamped(t time.Time, n int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
 }
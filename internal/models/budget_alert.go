@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BudgetAlert posts a webhook when the spending of a budget reaches
+// Threshold percent of what is available in the current period. Triggered
+// remembers that the threshold was reached, so the webhook is sent once per
+// crossing; it is cleared again when spending drops back below, for
+// example at the start of a new period.
+type BudgetAlert struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	BudgetID    uuid.UUID `gorm:"type:char(36);not null;index" json:"budget_id"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null" json:"user_id"`
+	Threshold   int       `gorm:"not null" json:"threshold"`
+	URL         string    `gorm:"type:varchar(2048);not null" json:"url"`
+	// Secret signs the webhooks; it is shown once when the alert is created
+	Secret    string    `gorm:"type:varchar(64);not null" json:"-"`
+	Active    bool      `gorm:"not null" json:"active"`
+	Triggered bool      `gorm:"not null;default:false" json:"triggered"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Reached reports whether the spending of the budget is at or above the
+// alert's threshold. A budget with nothing available has reached every
+// threshold as soon as anything is spent.
+func (a *BudgetAlert) Reached(budget *Budget) bool {
+	available := budget.Available()
+	if available <= 0 {
+		return budget.Spent > 0
+	}
+	return int64(budget.Spent)*100 >= int64(a.Threshold)*int64(available)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookPending deliveries are sent, or retried, at NextAttemptAt
+	WebhookPending   WebhookDeliveryStatus = "pending"
+	WebhookDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookFailed deliveries ran out of attempts
+	WebhookFailed WebhookDeliveryStatus = "failed"
+)
+
+// Webhook events
+const (
+	WebhookEventThresholdReached = "budget.threshold_reached"
+	WebhookEventTest             = "budget.alert_test"
+)
+
+// WebhookDelivery is one webhook to send for an alert, kept as a log of
+// what was sent and how the receiver answered.
+type WebhookDelivery struct {
+	ID            uuid.UUID             `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID   uuid.UUID             `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	AlertID       uuid.UUID             `gorm:"type:char(36);not null;index" json:"alert_id"`
+	BudgetID      uuid.UUID             `gorm:"type:char(36);not null;index" json:"budget_id"`
+	Event         string                `gorm:"type:varchar(64);not null" json:"event"`
+	Payload       string                `gorm:"type:text;not null" json:"payload"`
+	Status        WebhookDeliveryStatus `gorm:"type:varchar(16);not null;index" json:"status"`
+	Attempts      int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt *time.Time            `gorm:"index" json:"next_attempt_at"`
+	StatusCode    int                   `gorm:"not null;default:0" json:"status_code"`
+	Error         string                `gorm:"type:text" json:"error"`
+	DeliveredAt   *time.Time            `json:"delivered_at"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+	Alert         BudgetAlert           `gorm:"foreignKey:AlertID" json:"alert"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BudgetHistory is the closing figure of one finished budget period.
+type BudgetHistory struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	BudgetID    uuid.UUID `gorm:"type:char(36);not null;index:idx_budget_histories_period" json:"budget_id"`
+	PeriodStart time.Time `gorm:"not null;index:idx_budget_histories_period" json:"period_start"`
+	PeriodEnd   time.Time `gorm:"not null" json:"period_end"`
+	Amount      Money     `gorm:"type:bigint;not null" json:"amount"`
+	CarryIn     Money     `gorm:"type:bigint;not null;default:0" json:"carry_in"`
+	Spent       Money     `gorm:"type:bigint;not null;default:0" json:"spent"`
+	CarryOut    Money     `gorm:"type:bigint;not null;default:0" json:"carry_out"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
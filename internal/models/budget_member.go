@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BudgetRole is what a user may do with a budget. The owner is the user
+// the budget belongs to; everyone else joins as editor or viewer.
+type BudgetRole string
+
+const (
+	BudgetRoleOwner  BudgetRole = "owner"
+	BudgetRoleEditor BudgetRole = "editor"
+	BudgetRoleViewer BudgetRole = "viewer"
+)
+
+// BudgetAction is something a budget member asks to do.
+type BudgetAction string
+
+const (
+	// BudgetActionView reads the budget and its expenses
+	BudgetActionView BudgetAction = "view"
+	// BudgetActionSpend records expenses against the budget and changes the
+	// member's own expenses
+	BudgetActionSpend BudgetAction = "spend"
+	// BudgetActionManage changes the budget itself, its allocation, its
+	// members and expenses of other members
+	BudgetActionManage BudgetAction = "manage"
+)
+
+// Allows reports whether the role permits the action.
+func (r BudgetRole) Allows(action BudgetAction) bool {
+	switch r {
+	case BudgetRoleOwner:
+		return true
+	case BudgetRoleEditor:
+		return action == BudgetActionView || action == BudgetActionSpend
+	case BudgetRoleViewer:
+		return action == BudgetActionView
+	default:
+		return false
+	}
+}
+
+// BudgetMember gives another user access to a budget.
+type BudgetMember struct {
+	ID          uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID  `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	BudgetID    uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_budget_member" json:"budget_id"`
+	UserID      uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_budget_member;index" json:"user_id"`
+	Role        BudgetRole `gorm:"type:varchar(16);not null" json:"role"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	User        User       `gorm:"foreignKey:UserID" json:"user"`
+}
+
+// BudgetInvitation is a pending invitation to join a budget, sent by email.
+// Only the SHA-256 hash of the token is stored.
+type BudgetInvitation struct {
+	ID          uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID  `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	BudgetID    uuid.UUID  `gorm:"type:char(36);not null;index" json:"budget_id"`
+	InvitedBy   uuid.UUID  `gorm:"type:char(36);not null;index" json:"invited_by"`
+	Email       string     `gorm:"not null;index" json:"email"`
+	Role        BudgetRole `gorm:"type:varchar(16);not null" json:"role"`
+	TokenHash   string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Budget      Budget     `gorm:"foreignKey:BudgetID" json:"budget"`
+}
+
+// Pending reports whether the invitation can still be accepted at now.
+func (i *BudgetInvitation) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Category struct {
+	ID          uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID      `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	UserID      uuid.UUID      `gorm:"type:char(36);not null;index" json:"user_id"`
+	ParentID    *uuid.UUID     `gorm:"type:char(36);index" json:"parent_id"`
+	Name        string         `gorm:"not null" json:"name"`
+	Description string         `json:"description"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
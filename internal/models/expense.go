@@ -7,16 +7,31 @@ import (
 	"gorm.io/gorm"
 )
 
+// ExpenseStatus tells whether an expense counts against its budget. Only
+// approved expenses do.
+type ExpenseStatus string
+
+const (
+	ExpenseStatusApproved ExpenseStatus = "approved"
+	ExpenseStatusPending  ExpenseStatus = "pending"
+	ExpenseStatusRejected ExpenseStatus = "rejected"
+)
+
 type Expense struct {
 	ID          uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID      `gorm:"type:char(36);not null;index" json:"workspace_id"`
 	UserID      uuid.UUID      `gorm:"type:char(36);not null" json:"user_id"`
 	BudgetID    uuid.UUID      `gorm:"type:char(36);not null" json:"budget_id"`
-	Amount      float64        `gorm:"not null" json:"amount"`
+	CategoryID  *uuid.UUID     `gorm:"type:char(36);index" json:"category_id"`
+	Amount      Money          `gorm:"type:bigint;not null" json:"amount"`
 	Description string         `json:"description"`
 	Date        time.Time      `gorm:"not null" json:"date"`
+	Status      ExpenseStatus  `gorm:"type:varchar(16);not null;default:approved;index" json:"status"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 	User        User           `gorm:"foreignKey:UserID" json:"user"`
 	Budget      Budget         `gorm:"foreignKey:BudgetID" json:"budget"`
+	Category    *Category      `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Tags        []Tag          `gorm:"many2many:expense_tags;" json:"tags"`
 }
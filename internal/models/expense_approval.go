@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExpenseApprovalAction is one step of an expense's approval history.
+type ExpenseApprovalAction string
+
+const (
+	ExpenseApprovalSubmitted ExpenseApprovalAction = "submitted"
+	ExpenseApprovalApproved  ExpenseApprovalAction = "approved"
+	ExpenseApprovalRejected  ExpenseApprovalAction = "rejected"
+)
+
+// ExpenseApproval records who submitted, approved or rejected an expense.
+type ExpenseApproval struct {
+	ID          uuid.UUID             `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID             `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	ExpenseID   uuid.UUID             `gorm:"type:char(36);not null;index" json:"expense_id"`
+	BudgetID    uuid.UUID             `gorm:"type:char(36);not null;index" json:"budget_id"`
+	UserID      uuid.UUID             `gorm:"type:char(36);not null" json:"user_id"`
+	Action      ExpenseApprovalAction `gorm:"type:varchar(16);not null" json:"action"`
+	Amount      Money                 `gorm:"type:bigint;not null" json:"amount"`
+	Comment     string                `gorm:"type:text" json:"comment"`
+	CreatedAt   time.Time             `json:"created_at"`
+	User        User                  `gorm:"foreignKey:UserID" json:"user"`
+}
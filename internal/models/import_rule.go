@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportRule assigns imported statement rows whose description contains
+// Pattern (case-insensitive) to a budget and optionally a category. Rules
+// with a higher Priority are tried first.
+type ImportRule struct {
+	ID          uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID  `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	UserID      uuid.UUID  `gorm:"type:char(36);not null;index" json:"user_id"`
+	Pattern     string     `gorm:"not null" json:"pattern"`
+	BudgetID    uuid.UUID  `gorm:"type:char(36);not null" json:"budget_id"`
+	CategoryID  *uuid.UUID `gorm:"type:char(36)" json:"category_id"`
+	Priority    int        `gorm:"not null;default:0" json:"priority"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Income struct {
+	ID          uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID      `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	UserID      uuid.UUID      `gorm:"type:char(36);not null;index" json:"user_id"`
+	BudgetID    *uuid.UUID     `gorm:"type:char(36);index" json:"budget_id"`
+	Amount      Money          `gorm:"type:bigint;not null" json:"amount"`
+	Description string         `json:"description"`
+	Date        time.Time      `gorm:"not null" json:"date"`
+	TopUpBudget bool           `gorm:"not null;default:false" json:"top_up_budget"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	Budget      *Budget        `gorm:"foreignKey:BudgetID" json:"budget,omitempty"`
+}
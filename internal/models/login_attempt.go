@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttemptResult tells how a sign-in attempt ended
+type LoginAttemptResult string
+
+const (
+	LoginSucceeded          LoginAttemptResult = "success"
+	LoginMFARequired        LoginAttemptResult = "mfa_required"
+	LoginInvalidCredentials LoginAttemptResult = "invalid_credentials"
+	LoginInvalidCode        LoginAttemptResult = "invalid_code"
+	LoginEmailNotVerified   LoginAttemptResult = "email_not_verified"
+	LoginThrottled          LoginAttemptResult = "throttled"
+	LoginLocked             LoginAttemptResult = "locked"
+)
+
+// LoginAttempt records a single sign-in attempt. UserID is empty when the
+// email did not belong to an account.
+type LoginAttempt struct {
+	ID        uuid.UUID          `gorm:"type:char(36);primary_key" json:"id"`
+	UserID    *uuid.UUID         `gorm:"type:char(36);index" json:"user_id"`
+	Email     string             `gorm:"type:varchar(255);not null;index:idx_login_attempts_email_created" json:"email"`
+	IP        string             `gorm:"type:varchar(45);not null;index:idx_login_attempts_ip_created" json:"ip"`
+	UserAgent string             `gorm:"type:varchar(255)" json:"user_agent"`
+	Success   bool               `gorm:"not null" json:"success"`
+	Result    LoginAttemptResult `gorm:"type:varchar(32);not null" json:"result"`
+	CreatedAt time.Time          `gorm:"index:idx_login_attempts_email_created;index:idx_login_attempts_ip_created" json:"created_at"`
+}
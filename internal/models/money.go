@@ -0,0 +1,108 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MoneyScale is the number of minor units in one major unit (two decimal places).
+const MoneyScale = 100
+
+// Money is an exact monetary amount stored as integer minor units, so sums
+// never drift the way float64 amounts do. It is persisted as BIGINT and
+// encoded in JSON as a number with exactly two decimal places.
+type Money int64
+
+var errInvalidMoney = errors.New("invalid money amount")
+
+// ParseMoney parses a decimal string such as "12", "12.5" or "-0.05".
+// More than two decimal places is rejected rather than rounded.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errInvalidMoney
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, errInvalidMoney
+	}
+	if hasFrac && (frac == "" || len(frac) > 2) {
+		return 0, fmt.Errorf("%w: %q must have at most two decimal places", errInvalidMoney, s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+
+	units, err := strconv.ParseUint(whole, 10, 63)
+	if err != nil || units > math.MaxInt64/MoneyScale-1 {
+		return 0, errInvalidMoney
+	}
+	cents, err := strconv.ParseUint(frac, 10, 8)
+	if err != nil {
+		return 0, errInvalidMoney
+	}
+
+	m := Money(units*MoneyScale + cents)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// String formats the amount with exactly two decimal places, e.g. "1250.05".
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/MoneyScale, v%MoneyScale)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts either a JSON number or a quoted decimal string.
+// The digits are parsed directly so no float rounding is involved.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s := string(bytes.Trim(data, `"`))
+
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// UnmarshalText lets amounts be bound from query strings, e.g. ?min_amount=10.50
+func (m *Money) UnmarshalText(text []byte) error {
+	parsed, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
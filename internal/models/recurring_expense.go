@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecurrenceFrequency is the unit a recurring expense repeats in.
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "daily"
+	RecurrenceWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceMonthly RecurrenceFrequency = "monthly"
+	RecurrenceYearly  RecurrenceFrequency = "yearly"
+)
+
+// RecurringExpense is a template the scheduler turns into an expense on every
+// occurrence. Occurrences are counted from StartDate every Interval units of
+// Frequency and stop after EndDate or after Count occurrences, whichever
+// comes first. NextIndex and NextDate point at the first occurrence that has
+// not been booked yet; NextDate is nil once the schedule is over.
+type RecurringExpense struct {
+	ID          uuid.UUID           `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID           `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	UserID      uuid.UUID           `gorm:"type:char(36);not null;index" json:"user_id"`
+	BudgetID    uuid.UUID           `gorm:"type:char(36);not null;index" json:"budget_id"`
+	CategoryID  *uuid.UUID          `gorm:"type:char(36)" json:"category_id"`
+	Amount      Money               `gorm:"type:bigint;not null" json:"amount"`
+	Description string              `json:"description"`
+	Tags        []string            `gorm:"type:text;serializer:json" json:"tags"`
+	Frequency   RecurrenceFrequency `gorm:"type:varchar(16);not null" json:"frequency"`
+	Interval    int                 `gorm:"not null;default:1" json:"interval"`
+	StartDate   time.Time           `gorm:"not null" json:"start_date"`
+	EndDate     *time.Time          `json:"end_date"`
+	Count       int                 `gorm:"not null;default:0" json:"count"`
+	NextIndex   int                 `gorm:"not null;default:0" json:"next_index"`
+	NextDate    *time.Time          `gorm:"index" json:"next_date"`
+	// LastError is why the last scheduler run failed on this recurring
+	// expense, other than an occurrence being refused
+	LastError    string     `gorm:"type:text" json:"last_error"`
+	LastFailedAt *time.Time `json:"last_failed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Budget       Budget     `gorm:"foreignKey:BudgetID" json:"budget"`
+}
+
+// Occurrence returns the date of the n-th occurrence, counting from 0.
+// Monthly and yearly schedules anchored on a late day of the month fall on
+// the last day of shorter months.
+func (r *RecurringExpense) Occurrence(n int) time.Time {
+	step := max(r.Interval, 1) * n
+	switch r.Frequency {
+	case RecurrenceWeekly:
+		return r.StartDate.AddDate(0, 0, 7*step)
+	case RecurrenceMonthly:
+		return addMonthsClamped(r.StartDate, step)
+	case RecurrenceYearly:
+		return addMonthsClamped(r.StartDate, 12*step)
+	default:
+		return r.StartDate.AddDate(0, 0, step)
+	}
+}
+
+// HasOccurrence reports whether the n-th occurrence is still part of the schedule.
+func (r *RecurringExpense) HasOccurrence(n int) bool {
+	if n < 0 || (r.Count > 0 && n >= r.Count) {
+		return false
+	}
+	return r.EndDate == nil || !r.Occurrence(n).After(*r.EndDate)
+}
+
+// Advance points NextIndex and NextDate at the n-th occurrence, or ends the
+// schedule when there is none.
+func (r *RecurringExpense) Advance(n int) {
+	r.NextIndex = n
+	r.NextDate = nil
+	if r.HasOccurrence(n) {
+		next := r.Occurrence(n)
+		r.NextDate = &next
+	}
+}
+
+// RRule renders the schedule as an iCalendar recurrence rule.
+func (r *RecurringExpense) RRule() string {
+	parts := []string{"FREQ=" + strings.ToUpper(string(r.Frequency))}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if r.EndDate != nil {
+		parts = append(parts, "UNTIL="+r.EndDate.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// OccurrenceStatus is what happened, or will happen, to one occurrence of a
+// recurring expense.
+type OccurrenceStatus string
+
+const (
+	// OccurrenceScheduled is an upcoming occurrence that was edited
+	OccurrenceScheduled OccurrenceStatus = "scheduled"
+	OccurrenceSkipped   OccurrenceStatus = "skipped"
+	OccurrenceBooked    OccurrenceStatus = "booked"
+	// OccurrenceFailed is an occurrence the expense could not be recorded
+	// for, for example because the budget ran out
+	OccurrenceFailed OccurrenceStatus = "failed"
+)
+
+// RecurringOccurrence records a change to a single occurrence of a recurring
+// expense and, once the scheduler reached it, its outcome. The unique index
+// makes sure an occurrence is never booked twice.
+type RecurringOccurrence struct {
+	ID                 uuid.UUID        `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID        uuid.UUID        `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	RecurringExpenseID uuid.UUID        `gorm:"type:char(36);not null;uniqueIndex:idx_recurring_occurrence" json:"recurring_expense_id"`
+	Date               time.Time        `gorm:"not null;uniqueIndex:idx_recurring_occurrence" json:"date"`
+	Status             OccurrenceStatus `gorm:"type:varchar(16);not null" json:"status"`
+	Amount             *Money           `gorm:"type:bigint" json:"amount"`
+	Description        *string          `json:"description"`
+	ExpenseID          *uuid.UUID       `gorm:"type:char(36)" json:"expense_id"`
+	Error              string           `json:"error"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is a long-lived, single-use token exchanged for a new access
+// token. Only the SHA-256 hash of the token is stored. Every rotation stays
+// in the same family, so presenting a token that was already used revokes
+// the whole family.
+type RefreshToken struct {
+	ID           uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:char(36);not null;index" json:"user_id"`
+	FamilyID     uuid.UUID  `gorm:"type:char(36);not null;index" json:"family_id"`
+	TokenHash    string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID *uuid.UUID `gorm:"type:char(36)" json:"replaced_by_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Active reports whether the token can still be exchanged at now.
+func (t *RefreshToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && t.ReplacedByID == nil && now.Before(t.ExpiresAt)
+}
+
+// RevokedToken is a denylist entry for an access token that was revoked
+// before it expired. Entries can be purged once ExpiresAt has passed.
+type RevokedToken struct {
+	JTI       string    `gorm:"type:varchar(64);primary_key" json:"jti"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null" json:"user_id"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
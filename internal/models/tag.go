@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Tag struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_tags_workspace_user_name" json:"workspace_id"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_tags_workspace_user_name" json:"user_id"`
+	Name        string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_tags_workspace_user_name" json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// TransactionType tells the entries of the unified ledger apart.
+type TransactionType string
+
+const (
+	TransactionIncome   TransactionType = "income"
+	TransactionExpense  TransactionType = "expense"
+	TransactionTransfer TransactionType = "transfer"
+)
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Transfer moves allocation from one budget to another.
+type Transfer struct {
+	ID           uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID  uuid.UUID      `gorm:"type:char(36);not null;index" json:"workspace_id"`
+	UserID       uuid.UUID      `gorm:"type:char(36);not null;index" json:"user_id"`
+	FromBudgetID uuid.UUID      `gorm:"type:char(36);not null;index" json:"from_budget_id"`
+	ToBudgetID   uuid.UUID      `gorm:"type:char(36);not null;index" json:"to_budget_id"`
+	Amount       Money          `gorm:"type:bigint;not null" json:"amount"`
+	Description  string         `json:"description"`
+	Date         time.Time      `gorm:"not null" json:"date"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	FromBudget   Budget         `gorm:"foreignKey:FromBudgetID" json:"from_budget"`
+	ToBudget     Budget         `gorm:"foreignKey:ToBudgetID" json:"to_budget"`
+}
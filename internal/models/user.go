@@ -8,11 +8,40 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
-	Email     string         `gorm:"uniqueIndex:idx_users_email,length:255;not null" json:"email"`
-	Password  string         `gorm:"not null" json:"-"`
-	Name      string         `gorm:"not null" json:"name"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	Email           string     `gorm:"uniqueIndex:idx_users_email,length:255;not null" json:"email"`
+	Password        string     `gorm:"not null" json:"-"`
+	Name            string     `gorm:"not null" json:"name"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// TokenVersion is bumped whenever all sessions are revoked; access
+	// tokens carrying an older version are rejected
+	TokenVersion int `gorm:"not null;default:0" json:"-"`
+	// TOTPSecret is set during enrollment; 2FA is on once TOTPEnabledAt is set
+	TOTPSecret    string     `gorm:"type:varchar(64)" json:"-"`
+	TOTPEnabledAt *time.Time `json:"-"`
+	// TOTPLastStep is the time step of the last accepted code, so a code
+	// can't be replayed
+	TOTPLastStep int64 `gorm:"not null;default:0" json:"-"`
+	// FailedLogins counts wrong passwords or codes since the last sign-in;
+	// reaching the limit locks the account until LockedUntil
+	FailedLogins int            `gorm:"not null;default:0" json:"-"`
+	LockedUntil  *time.Time     `json:"-"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// EmailVerified reports whether the user has confirmed their email address.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// TwoFactorEnabled reports whether logins need a TOTP or recovery code.
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// Locked reports whether sign-ins are refused at now.
+func (u *User) Locked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
 }
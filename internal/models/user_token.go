@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTokenPurpose tells what a one-time user token may be used for
+type UserTokenPurpose string
+
+const (
+	UserTokenEmailChange   UserTokenPurpose = "email_change"
+	UserTokenPasswordReset UserTokenPurpose = "password_reset"
+	UserTokenEmailVerify   UserTokenPurpose = "email_verify"
+)
+
+// UserToken is a single-use token sent to the user by email, e.g. to confirm
+// a new address. Only the SHA-256 hash of the token is stored.
+type UserToken struct {
+	ID        uuid.UUID        `gorm:"type:char(36);primary_key" json:"id"`
+	UserID    uuid.UUID        `gorm:"type:char(36);not null;index" json:"user_id"`
+	Purpose   UserTokenPurpose `gorm:"type:varchar(32);not null" json:"purpose"`
+	TokenHash string           `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	// NewEmail is the address being confirmed by an email change
+	NewEmail  string     `json:"new_email,omitempty"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
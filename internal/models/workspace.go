@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkspaceRole is what a member may do in a workspace.
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleOwner  WorkspaceRole = "owner"
+	WorkspaceRoleEditor WorkspaceRole = "editor"
+	WorkspaceRoleViewer WorkspaceRole = "viewer"
+	// WorkspaceRoleGuest is never stored. It is given to users who are not
+	// members of a workspace but were invited to one of its budgets.
+	WorkspaceRoleGuest WorkspaceRole = "guest"
+)
+
+// BudgetRole is the role the workspace role grants on every budget of the
+// workspace. Guests only get access to the budgets shared with them.
+func (r WorkspaceRole) BudgetRole() BudgetRole {
+	switch r {
+	case WorkspaceRoleOwner:
+		return BudgetRoleOwner
+	case WorkspaceRoleEditor:
+		return BudgetRoleEditor
+	case WorkspaceRoleViewer:
+		return BudgetRoleViewer
+	default:
+		return ""
+	}
+}
+
+// Workspace owns budgets, expenses and everything recorded against them, so
+// several teams can use one installation without seeing each other's data.
+// Every user has a personal workspace that shares the user's ID.
+type Workspace struct {
+	ID        uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Personal  bool      `gorm:"not null;default:false" json:"personal"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkspaceMember gives a user access to a workspace.
+type WorkspaceMember struct {
+	ID          uuid.UUID     `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID uuid.UUID     `gorm:"type:char(36);not null;uniqueIndex:idx_workspace_member" json:"workspace_id"`
+	UserID      uuid.UUID     `gorm:"type:char(36);not null;uniqueIndex:idx_workspace_member;index" json:"user_id"`
+	Role        WorkspaceRole `gorm:"type:varchar(16);not null" json:"role"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Workspace   Workspace     `gorm:"foreignKey:WorkspaceID" json:"workspace"`
+	User        User          `gorm:"foreignKey:UserID" json:"user"`
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BudgetAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewBudgetAlertRepository(db *gorm.DB) *BudgetAlertRepository {
+	return &BudgetAlertRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *BudgetAlertRepository) WithTx(tx *gorm.DB) *BudgetAlertRepository {
+	return &BudgetAlertRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *BudgetAlertRepository) InWorkspace(workspaceID uuid.UUID) *BudgetAlertRepository {
+	return &BudgetAlertRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
+func (r *BudgetAlertRepository) Create(alert *models.BudgetAlert) error {
+	return r.db.Create(alert).Error
+}
+
+func (r *BudgetAlertRepository) FindByID(id uuid.UUID) (*models.BudgetAlert, error) {
+	var alert models.BudgetAlert
+	err := r.db.First(&alert, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *BudgetAlertRepository) FindByBudgetID(budgetID uuid.UUID) ([]models.BudgetAlert, error) {
+	var alerts []models.BudgetAlert
+	err := r.db.Where("budget_id = ?", budgetID).Order("threshold").Order("created_at").Find(&alerts).Error
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// FindActiveByBudgetID returns the alerts of a budget that are switched on.
+func (r *BudgetAlertRepository) FindActiveByBudgetID(budgetID uuid.UUID) ([]models.BudgetAlert, error) {
+	var alerts []models.BudgetAlert
+	err := r.db.Where("budget_id = ? AND active = ?", budgetID, true).Order("threshold").Find(&alerts).Error
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (r *BudgetAlertRepository) Update(alert *models.BudgetAlert) error {
+	return r.db.Save(alert).Error
+}
+
+// SetTriggered records whether an alert's threshold is currently reached.
+func (r *BudgetAlertRepository) SetTriggered(id uuid.UUID, triggered bool) error {
+	return r.db.Model(&models.BudgetAlert{}).Where("id = ?", id).UpdateColumn("triggered", triggered).Error
+}
+
+// Delete removes an alert together with its delivery log.
+func (r *BudgetAlertRepository) Delete(id uuid.UUID) error {
+	if err := r.db.Where("alert_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(&models.BudgetAlert{}, "id = ?", id).Error
+}
+
+func (r *BudgetAlertRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Omit("Alert").Create(delivery).Error
+}
+
+func (r *BudgetAlertRepository) FindDeliveryByID(id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.Preload("Alert").First(&delivery, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// FindDeliveriesByAlertID returns the latest deliveries of an alert, newest first.
+func (r *BudgetAlertRepository) FindDeliveriesByAlertID(alertID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Where("alert_id = ?", alertID).Order("created_at DESC").Order("id DESC").Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// FindDueDeliveries returns up to limit pending deliveries whose next attempt
+// is due at now, oldest first.
+func (r *BudgetAlertRepository) FindDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Preload("Alert").Where("status = ? AND next_attempt_at <= ?", models.WebhookPending, now).
+		Order("next_attempt_at").Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimDelivery moves the next attempt of a due delivery to until, so no other
+// worker picks it up while it is being sent. It reports false when the
+// delivery was claimed or changed by someone else since it was loaded.
+func (r *BudgetAlertRepository) ClaimDelivery(delivery *models.WebhookDelivery, until time.Time) (bool, error) {
+	result := r.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ? AND next_attempt_at = ?", delivery.ID, models.WebhookPending, delivery.Attempts, delivery.NextAttemptAt).
+		UpdateColumn("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *BudgetAlertRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Omit(clause.Associations).Save(delivery).Error
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BudgetHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewBudgetHistoryRepository(db *gorm.DB) *BudgetHistoryRepository {
+	return &BudgetHistoryRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *BudgetHistoryRepository) WithTx(tx *gorm.DB) *BudgetHistoryRepository {
+	return &BudgetHistoryRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *BudgetHistoryRepository) InWorkspace(workspaceID uuid.UUID) *BudgetHistoryRepository {
+	return &BudgetHistoryRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
+func (r *BudgetHistoryRepository) Create(history *models.BudgetHistory) error {
+	return r.db.Create(history).Error
+}
+
+// FindByBudgetIDs returns the history of several budgets, newest period first.
+func (r *BudgetHistoryRepository) FindByBudgetIDs(budgetIDs []uuid.UUID) ([]models.BudgetHistory, error) {
+	var histories []models.BudgetHistory
+	if len(budgetIDs) == 0 {
+		return histories, nil
+	}
+	err := r.db.Where("budget_id IN ?", budgetIDs).Order("period_start DESC").Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+// AddSpentAt adjusts the closed period that contains date, if there is one.
+// It is used for expenses back-dated into a period that already rolled over.
+func (r *BudgetHistoryRepository) AddSpentAt(budgetID uuid.UUID, date time.Time, amount models.Money) error {
+	return r.db.Model(&models.BudgetHistory{}).
+		Where("budget_id = ? AND period_start <= ? AND period_end > ?", budgetID, date, date).
+		UpdateColumn("spent", gorm.Expr("spent + ?", amount)).Error
+}
+
+func (r *BudgetHistoryRepository) DeleteByBudgetID(budgetID uuid.UUID) error {
+	return r.db.Where("budget_id = ?", budgetID).Delete(&models.BudgetHistory{}).Error
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BudgetInvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewBudgetInvitationRepository(db *gorm.DB) *BudgetInvitationRepository {
+	return &BudgetInvitationRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *BudgetInvitationRepository) WithTx(tx *gorm.DB) *BudgetInvitationRepository {
+	return &BudgetInvitationRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *BudgetInvitationRepository) InWorkspace(workspaceID uuid.UUID) *BudgetInvitationRepository {
+	return &BudgetInvitationRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
+func (r *BudgetInvitationRepository) Create(invitation *models.BudgetInvitation) error {
+	return r.db.Omit(clause.Associations).Create(invitation).Error
+}
+
+func (r *BudgetInvitationRepository) FindByID(id uuid.UUID) (*models.BudgetInvitation, error) {
+	var invitation models.BudgetInvitation
+	err := r.db.First(&invitation, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// FindByHashForUpdate loads an invitation with its budget and locks it, so
+// it can only be accepted once.
+func (r *BudgetInvitationRepository) FindByHashForUpdate(hash string) (*models.BudgetInvitation, error) {
+	var invitation models.BudgetInvitation
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Budget").
+		First(&invitation, "token_hash = ?", hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// FindPendingByBudgetID returns the invitations of a budget that can still
+// be accepted, newest first.
+func (r *BudgetInvitationRepository) FindPendingByBudgetID(budgetID uuid.UUID, now time.Time) ([]models.BudgetInvitation, error) {
+	var invitations []models.BudgetInvitation
+	err := r.db.Where("budget_id = ? AND accepted_at IS NULL AND expires_at > ?", budgetID, now).
+		Order("created_at DESC").Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *BudgetInvitationRepository) Update(invitation *models.BudgetInvitation) error {
+	return r.db.Omit(clause.Associations).Save(invitation).Error
+}
+
+func (r *BudgetInvitationRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.BudgetInvitation{}, "id = ?", id).Error
+}
+
+// DeletePending removes the open invitations of a budget for an address, so
+// only the most recently sent one works.
+func (r *BudgetInvitationRepository) DeletePending(budgetID uuid.UUID, email string) error {
+	return r.db.Where("budget_id = ? AND email = ? AND accepted_at IS NULL", budgetID, email).
+		Delete(&models.BudgetInvitation{}).Error
+}
+
+// DeleteExpired removes invitations that expired before the given time.
+func (r *BudgetInvitationRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&models.BudgetInvitation{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BudgetMemberRepository struct {
+	db *gorm.DB
+}
+
+func NewBudgetMemberRepository(db *gorm.DB) *BudgetMemberRepository {
+	return &BudgetMemberRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *BudgetMemberRepository) WithTx(tx *gorm.DB) *BudgetMemberRepository {
+	return &BudgetMemberRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *BudgetMemberRepository) InWorkspace(workspaceID uuid.UUID) *BudgetMemberRepository {
+	return &BudgetMemberRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
+func (r *BudgetMemberRepository) Create(member *models.BudgetMember) error {
+	return r.db.Create(member).Error
+}
+
+func (r *BudgetMemberRepository) Find(budgetID, userID uuid.UUID) (*models.BudgetMember, error) {
+	var member models.BudgetMember
+	err := r.db.First(&member, "budget_id = ? AND user_id = ?", budgetID, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindByBudgetID returns the members of a budget with their accounts,
+// oldest first.
+func (r *BudgetMemberRepository) FindByBudgetID(budgetID uuid.UUID) ([]models.BudgetMember, error) {
+	var members []models.BudgetMember
+	err := r.db.Preload("User").Where("budget_id = ?", budgetID).Order("created_at").Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// FindByUserID returns the memberships of a user in other users' budgets.
+func (r *BudgetMemberRepository) FindByUserID(userID uuid.UUID) ([]models.BudgetMember, error) {
+	var members []models.BudgetMember
+	err := r.db.Where("user_id = ?", userID).Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// FindWorkspaceIDsByUserID returns the workspaces the user was invited to a
+// budget of.
+func (r *BudgetMemberRepository) FindWorkspaceIDsByUserID(userID uuid.UUID) ([]uuid.UUID, error) {
+	var workspaceIDs []uuid.UUID
+	err := r.db.Model(&models.BudgetMember{}).Distinct("workspace_id").Where("user_id = ?", userID).Pluck("workspace_id", &workspaceIDs).Error
+	return workspaceIDs, err
+}
+
+// ExistsInWorkspace reports whether a budget of the workspace is shared
+// with the user.
+func (r *BudgetMemberRepository) ExistsInWorkspace(workspaceID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.BudgetMember{}).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *BudgetMemberRepository) Update(member *models.BudgetMember) error {
+	return r.db.Omit("User").Save(member).Error
+}
+
+func (r *BudgetMemberRepository) Delete(budgetID, userID uuid.UUID) error {
+	return r.db.Where("budget_id = ? AND user_id = ?", budgetID, userID).Delete(&models.BudgetMember{}).Error
+}
@@ -1,9 +1,12 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/Alvarras/dompet-g0/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BudgetRepository struct {
@@ -14,6 +17,16 @@ func NewBudgetRepository(db *gorm.DB) *BudgetRepository {
 	return &BudgetRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *BudgetRepository) WithTx(tx *gorm.DB) *BudgetRepository {
+	return &BudgetRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *BudgetRepository) InWorkspace(workspaceID uuid.UUID) *BudgetRepository {
+	return &BudgetRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
 func (r *BudgetRepository) Create(budget *models.Budget) error {
 	return r.db.Create(budget).Error
 }
@@ -27,6 +40,17 @@ func (r *BudgetRepository) FindByID(id uuid.UUID) (*models.Budget, error) {
 	return &budget, nil
 }
 
+// FindByIDForUpdate loads a budget and holds a row lock on it until the
+// surrounding transaction ends. It must be called on a repository returned by WithTx.
+func (r *BudgetRepository) FindByIDForUpdate(id uuid.UUID) (*models.Budget, error) {
+	var budget models.Budget
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&budget, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
 func (r *BudgetRepository) FindByUserID(userID uuid.UUID) ([]models.Budget, error) {
 	var budgets []models.Budget
 	err := r.db.Where("user_id = ?", userID).Find(&budgets).Error
@@ -36,15 +60,85 @@ func (r *BudgetRepository) FindByUserID(userID uuid.UUID) ([]models.Budget, erro
 	return budgets, nil
 }
 
+// FindByWorkspaceID returns every budget of a workspace, oldest first.
+func (r *BudgetRepository) FindByWorkspaceID(workspaceID uuid.UUID) ([]models.Budget, error) {
+	var budgets []models.Budget
+	err := r.db.Where("workspace_id = ?", workspaceID).Order("created_at").Find(&budgets).Error
+	if err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// FindAccessibleByUserID returns the budgets a user owns together with the
+// budgets shared with the user.
+func (r *BudgetRepository) FindAccessibleByUserID(userID uuid.UUID) ([]models.Budget, error) {
+	var budgets []models.Budget
+	shared := r.db.Session(&gorm.Session{NewDB: true}).Model(&models.BudgetMember{}).Select("budget_id").Where("user_id = ?", userID)
+	err := r.db.Where("user_id = ? OR id IN (?)", userID, shared).Order("created_at").Find(&budgets).Error
+	if err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// FindAllByUserID returns every budget of a user, soft-deleted ones included.
+func (r *BudgetRepository) FindAllByUserID(userID uuid.UUID) ([]models.Budget, error) {
+	var budgets []models.Budget
+	err := r.db.Unscoped().Where("user_id = ?", userID).Order("created_at").Find(&budgets).Error
+	if err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// FindDueForRollover returns periodic budgets whose current period has ended.
+func (r *BudgetRepository) FindDueForRollover(now time.Time) ([]models.Budget, error) {
+	var budgets []models.Budget
+	err := r.db.Where("period <> ? AND period_end <= ?", models.BudgetPeriodNone, now).Find(&budgets).Error
+	if err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
 func (r *BudgetRepository) Update(budget *models.Budget) error {
-	return r.db.Save(budget).Error
+	return r.db.Omit(clause.Associations).Save(budget).Error
 }
 
+// Delete removes a budget, stops the recurring expenses booked against it
+// and drops its alerts.
 func (r *BudgetRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Budget{}, "id = ?", id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		recurringIDs := tx.Model(&models.RecurringExpense{}).Select("id").Where("budget_id = ?", id)
+		if err := tx.Where("recurring_expense_id IN (?)", recurringIDs).Delete(&models.RecurringOccurrence{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("budget_id = ?", id).Delete(&models.RecurringExpense{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("budget_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("budget_id = ?", id).Delete(&models.BudgetAlert{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Budget{}, "id = ?", id).Error
+	})
 }
 
-func (r *BudgetRepository) UpdateSpent(id uuid.UUID, amount float64) error {
-	return r.db.Model(&models.Budget{}).Where("id = ?", id).
-		UpdateColumn("spent", gorm.Expr("spent + ?", amount)).Error
+// UpdateSpent adds amount to what a budget spent. An increase is only
+// applied while it still fits the budget, checked by the UPDATE itself so
+// it holds even where the row lock is not honoured; it reports whether the
+// amount was applied.
+func (r *BudgetRepository) UpdateSpent(id uuid.UUID, amount models.Money) (bool, error) {
+	if amount == 0 {
+		return true, nil
+	}
+	query := r.db.Model(&models.Budget{}).Where("id = ?", id)
+	if amount > 0 {
+		query = query.Where("spent + ? <= amount + carry_over", amount)
+	}
+	result := query.UpdateColumn("spent", gorm.Expr("spent + ?", amount))
+	return result.RowsAffected > 0, result.Error
 }
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *CategoryRepository) WithTx(tx *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *CategoryRepository) InWorkspace(workspaceID uuid.UUID) *CategoryRepository {
+	return &CategoryRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
+func (r *CategoryRepository) Create(category *models.Category) error {
+	return r.db.Create(category).Error
+}
+
+func (r *CategoryRepository) FindByID(id uuid.UUID) (*models.Category, error) {
+	var category models.Category
+	err := r.db.First(&category, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *CategoryRepository) FindByUserID(userID uuid.UUID) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Where("user_id = ?", userID).Order("name").Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// FindAllByUserID returns every category of a user, soft-deleted ones included.
+func (r *CategoryRepository) FindAllByUserID(userID uuid.UUID) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Unscoped().Where("user_id = ?", userID).Order("created_at").Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *CategoryRepository) Update(category *models.Category) error {
+	return r.db.Save(category).Error
+}
+
+func (r *CategoryRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Category{}, "id = ?", id).Error
+}
+
+// ReparentChildren moves the direct children of a category under a new parent.
+func (r *CategoryRepository) ReparentChildren(id uuid.UUID, parentID *uuid.UUID) error {
+	return r.db.Model(&models.Category{}).Where("parent_id = ?", id).
+		UpdateColumn("parent_id", parentID).Error
+}
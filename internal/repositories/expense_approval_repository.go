@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ExpenseApprovalRepository struct {
+	db *gorm.DB
+}
+
+func NewExpenseApprovalRepository(db *gorm.DB) *ExpenseApprovalRepository {
+	return &ExpenseApprovalRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *ExpenseApprovalRepository) WithTx(tx *gorm.DB) *ExpenseApprovalRepository {
+	return &ExpenseApprovalRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *ExpenseApprovalRepository) InWorkspace(workspaceID uuid.UUID) *ExpenseApprovalRepository {
+	return &ExpenseApprovalRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
+func (r *ExpenseApprovalRepository) Create(approval *models.ExpenseApproval) error {
+	return r.db.Omit("User").Create(approval).Error
+}
+
+// FindByExpenseID returns the approval history of an expense, oldest first.
+func (r *ExpenseApprovalRepository) FindByExpenseID(expenseID uuid.UUID) ([]models.ExpenseApproval, error) {
+	var approvals []models.ExpenseApproval
+	err := r.db.Preload("User").Where("expense_id = ?", expenseID).Order("created_at").Order("id").Find(&approvals).Error
+	if err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
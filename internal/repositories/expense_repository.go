@@ -1,11 +1,139 @@
 package repositories
 
 import (
+	"strings"
+	"time"
+
 	"github.com/Alvarras/dompet-g0/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExpenseFilter narrows down an expense listing. Empty fields are ignored.
+type ExpenseFilter struct {
+	CategoryIDs []uuid.UUID
+	Tag         string
+	From        time.Time
+	To          time.Time
+	MinAmount   *models.Money
+	MaxAmount   *models.Money
+	Search      string
+	Status      models.ExpenseStatus
+}
+
+func (f ExpenseFilter) apply(db *gorm.DB) *gorm.DB {
+	if !f.From.IsZero() {
+		db = db.Where("expenses.date >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		db = db.Where("expenses.date < ?", f.To)
+	}
+	if f.MinAmount != nil {
+		db = db.Where("expenses.amount >= ?", *f.MinAmount)
+	}
+	if f.MaxAmount != nil {
+		db = db.Where("expenses.amount <= ?", *f.MaxAmount)
+	}
+	if f.Status != "" {
+		db = db.Where("expenses.status = ?", f.Status)
+	}
+	if f.Search != "" {
+		db = db.Where("LOWER(expenses.description) LIKE ?", "%"+escapeLike(strings.ToLower(f.Search))+"%")
+	}
+	if len(f.CategoryIDs) > 0 {
+		db = db.Where("expenses.category_id IN ?", f.CategoryIDs)
+	}
+	if f.Tag != "" {
+		db = db.Where("expenses.id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+			Table("expense_tags").
+			Select("expense_tags.expense_id").
+			Joins("JOIN tags ON tags.id = expense_tags.tag_id").
+			Where("tags.name = ?", f.Tag))
+	}
+	return db
+}
+
+// ExpenseSort is the order of an expense listing. A leading "-" means descending.
+type ExpenseSort string
+
+const (
+	ExpenseSortDateDesc   ExpenseSort = "-date"
+	ExpenseSortDateAsc    ExpenseSort = "date"
+	ExpenseSortAmountDesc ExpenseSort = "-amount"
+	ExpenseSortAmountAsc  ExpenseSort = "amount"
+)
+
+// ExpenseCursor marks the last expense of the previous page. Listings
+// continue strictly after it in the requested order, with ID breaking ties.
+type ExpenseCursor struct {
+	Date   time.Time
+	Amount models.Money
+	ID     uuid.UUID
+}
+
+// ExpensePage selects one page of a listing. A zero Limit returns everything.
+type ExpensePage struct {
+	Sort  ExpenseSort
+	Limit int
+	After *ExpenseCursor
+}
+
+func (p ExpensePage) apply(db *gorm.DB) *gorm.DB {
+	column, value := "expenses.date", any(nil)
+	if p.After != nil {
+		value = p.After.Date
+	}
+	if p.Sort == ExpenseSortAmountAsc || p.Sort == ExpenseSortAmountDesc {
+		column = "expenses.amount"
+		if p.After != nil {
+			value = p.After.Amount
+		}
+	}
+
+	direction, compare := "DESC", "<"
+	if p.Sort == ExpenseSortDateAsc || p.Sort == ExpenseSortAmountAsc {
+		direction, compare = "ASC", ">"
+	}
+
+	if p.After != nil {
+		db = db.Where("("+column+" "+compare+" ? OR ("+column+" = ? AND expenses.id "+compare+" ?))",
+			value, value, p.After.ID)
+	}
+	db = db.Order(column + " " + direction).Order("expenses.id " + direction)
+	if p.Limit > 0 {
+		db = db.Limit(p.Limit)
+	}
+	return db
+}
+
+// ExpenseGroup is the dimension expense totals are aggregated by.
+type ExpenseGroup string
+
+const (
+	ExpenseGroupNone     ExpenseGroup = ""
+	ExpenseGroupDay      ExpenseGroup = "day"
+	ExpenseGroupWeek     ExpenseGroup = "week"
+	ExpenseGroupMonth    ExpenseGroup = "month"
+	ExpenseGroupBudget   ExpenseGroup = "budget"
+	ExpenseGroupCategory ExpenseGroup = "category"
 )
 
+// ExpenseGroupTotal is the aggregate of the expenses sharing one group key.
+// Time groups use the first day of the period as key and have no label.
+// Budget and category groups use the ID (empty for uncategorized expenses)
+// as key and the name as label.
+type ExpenseGroupTotal struct {
+	GroupKey   string
+	GroupLabel string
+	Total      models.Money
+	Count      int64
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 type ExpenseRepository struct {
 	db *gorm.DB
 }
@@ -14,41 +142,235 @@ func NewExpenseRepository(db *gorm.DB) *ExpenseRepository {
 	return &ExpenseRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *ExpenseRepository) WithTx(tx *gorm.DB) *ExpenseRepository {
+	return &ExpenseRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *ExpenseRepository) InWorkspace(workspaceID uuid.UUID) *ExpenseRepository {
+	return &ExpenseRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
 func (r *ExpenseRepository) Create(expense *models.Expense) error {
-	return r.db.Create(expense).Error
+	return r.db.Omit("Tags.*").Create(expense).Error
 }
 
 func (r *ExpenseRepository) FindByID(id uuid.UUID) (*models.Expense, error) {
 	var expense models.Expense
-	err := r.db.Preload("Budget").First(&expense, "id = ?", id).Error
+	err := r.db.Preload("Budget").Preload("Category").Preload("Tags").First(&expense, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &expense, nil
 }
 
-func (r *ExpenseRepository) FindByUserID(userID uuid.UUID) ([]models.Expense, error) {
+// FindByIDForUpdate loads an expense and holds a row lock on it until the
+// surrounding transaction ends. It must be called on a repository returned by WithTx.
+func (r *ExpenseRepository) FindByIDForUpdate(id uuid.UUID) (*models.Expense, error) {
+	var expense models.Expense
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&expense, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+func (r *ExpenseRepository) FindByUserID(userID uuid.UUID, filter ExpenseFilter, page ExpensePage) ([]models.Expense, error) {
 	var expenses []models.Expense
-	err := r.db.Preload("Budget").Where("user_id = ?", userID).Find(&expenses).Error
+	err := page.apply(filter.apply(r.db.Preload("Budget").Preload("Category").Preload("Tags"))).
+		Where("expenses.user_id = ?", userID).Find(&expenses).Error
 	if err != nil {
 		return nil, err
 	}
 	return expenses, nil
 }
 
-func (r *ExpenseRepository) FindByBudgetID(budgetID uuid.UUID) ([]models.Expense, error) {
+// FindByWorkspaceID lists every expense recorded in a workspace.
+func (r *ExpenseRepository) FindByWorkspaceID(workspaceID uuid.UUID, filter ExpenseFilter, page ExpensePage) ([]models.Expense, error) {
 	var expenses []models.Expense
-	err := r.db.Preload("Budget").Where("budget_id = ?", budgetID).Find(&expenses).Error
+	err := page.apply(filter.apply(r.db.Preload("Budget").Preload("Category").Preload("Tags").Preload("User"))).
+		Where("expenses.workspace_id = ?", workspaceID).Find(&expenses).Error
 	if err != nil {
 		return nil, err
 	}
 	return expenses, nil
 }
 
+// CountByWorkspaceID returns how many expenses of a workspace match the filter.
+func (r *ExpenseRepository) CountByWorkspaceID(workspaceID uuid.UUID, filter ExpenseFilter) (int64, error) {
+	var count int64
+	err := filter.apply(r.db.Model(&models.Expense{})).Where("expenses.workspace_id = ?", workspaceID).Count(&count).Error
+	return count, err
+}
+
+// FindVisibleToUser lists the expenses a user recorded together with every
+// expense in budgets the user owns or is a member of.
+func (r *ExpenseRepository) FindVisibleToUser(userID uuid.UUID, filter ExpenseFilter, page ExpensePage) ([]models.Expense, error) {
+	var expenses []models.Expense
+	err := visibleTo(page.apply(filter.apply(r.db.Preload("Budget").Preload("Category").Preload("Tags").Preload("User"))), userID).
+		Find(&expenses).Error
+	if err != nil {
+		return nil, err
+	}
+	return expenses, nil
+}
+
+// CountVisibleToUser returns how many expenses visible to a user match the filter.
+func (r *ExpenseRepository) CountVisibleToUser(userID uuid.UUID, filter ExpenseFilter) (int64, error) {
+	var count int64
+	err := visibleTo(filter.apply(r.db.Model(&models.Expense{})), userID).Count(&count).Error
+	return count, err
+}
+
+func visibleTo(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	owned := db.Session(&gorm.Session{NewDB: true}).Model(&models.Budget{}).Select("id").Where("user_id = ?", userID)
+	shared := db.Session(&gorm.Session{NewDB: true}).Model(&models.BudgetMember{}).Select("budget_id").Where("user_id = ?", userID)
+	return db.Where("(expenses.user_id = ? OR expenses.budget_id IN (?) OR expenses.budget_id IN (?))", userID, owned, shared)
+}
+
+// CountByUserID returns how many of a user's expenses match the filter.
+func (r *ExpenseRepository) CountByUserID(userID uuid.UUID, filter ExpenseFilter) (int64, error) {
+	var count int64
+	err := filter.apply(r.db.Model(&models.Expense{})).Where("expenses.user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// FindAllByUserID returns every expense of a user with its tags, soft-deleted
+// ones included, oldest first.
+func (r *ExpenseRepository) FindAllByUserID(userID uuid.UUID) ([]models.Expense, error) {
+	var expenses []models.Expense
+	err := r.db.Unscoped().Preload("Tags").Where("user_id = ?", userID).Order("date").Find(&expenses).Error
+	if err != nil {
+		return nil, err
+	}
+	return expenses, nil
+}
+
+func (r *ExpenseRepository) FindByBudgetID(budgetID uuid.UUID, filter ExpenseFilter, page ExpensePage) ([]models.Expense, error) {
+	var expenses []models.Expense
+	err := page.apply(filter.apply(r.db.Preload("Budget").Preload("Category").Preload("Tags").Preload("User"))).
+		Where("expenses.budget_id = ?", budgetID).Find(&expenses).Error
+	if err != nil {
+		return nil, err
+	}
+	return expenses, nil
+}
+
+// CountByBudgetID returns how many of a budget's expenses match the filter.
+func (r *ExpenseRepository) CountByBudgetID(budgetID uuid.UUID, filter ExpenseFilter) (int64, error) {
+	var count int64
+	err := filter.apply(r.db.Model(&models.Expense{})).Where("expenses.budget_id = ?", budgetID).Count(&count).Error
+	return count, err
+}
+
+// FindPendingByBudgetIDs lists one page of the expenses waiting for approval
+// in the given budgets.
+func (r *ExpenseRepository) FindPendingByBudgetIDs(budgetIDs []uuid.UUID, filter ExpenseFilter, page ExpensePage) ([]models.Expense, error) {
+	var expenses []models.Expense
+	if len(budgetIDs) == 0 {
+		return expenses, nil
+	}
+	err := page.apply(filter.apply(r.db.Preload("Budget").Preload("Category").Preload("Tags").Preload("User"))).
+		Where("expenses.budget_id IN ? AND expenses.status = ?", budgetIDs, models.ExpenseStatusPending).
+		Find(&expenses).Error
+	if err != nil {
+		return nil, err
+	}
+	return expenses, nil
+}
+
+// CountPendingByBudgetIDs returns how many expenses waiting for approval in
+// the given budgets match the filter.
+func (r *ExpenseRepository) CountPendingByBudgetIDs(budgetIDs []uuid.UUID, filter ExpenseFilter) (int64, error) {
+	var count int64
+	if len(budgetIDs) == 0 {
+		return count, nil
+	}
+	err := filter.apply(r.db.Model(&models.Expense{})).
+		Where("expenses.budget_id IN ? AND expenses.status = ?", budgetIDs, models.ExpenseStatusPending).
+		Count(&count).Error
+	return count, err
+}
+
+// TotalsByUserID sums a user's matching approved expenses in SQL, one row per
+// group ordered by key. ExpenseGroupNone returns a single row for everything.
+func (r *ExpenseRepository) TotalsByUserID(userID uuid.UUID, filter ExpenseFilter, group ExpenseGroup) ([]ExpenseGroupTotal, error) {
+	db := filter.apply(r.db.Model(&models.Expense{})).
+		Where("expenses.user_id = ? AND expenses.status = ?", userID, models.ExpenseStatusApproved)
+
+	key, label := "''", "''"
+	switch group {
+	case ExpenseGroupDay:
+		key = "DATE_FORMAT(expenses.date, '%Y-%m-%d')"
+	case ExpenseGroupWeek:
+		// Weeks start on Monday
+		key = "DATE_FORMAT(DATE_SUB(expenses.date, INTERVAL WEEKDAY(expenses.date) DAY), '%Y-%m-%d')"
+	case ExpenseGroupMonth:
+		key = "DATE_FORMAT(expenses.date, '%Y-%m-01')"
+	case ExpenseGroupBudget:
+		db = db.Joins("LEFT JOIN budgets ON budgets.id = expenses.budget_id")
+		key, label = "expenses.budget_id", "COALESCE(MAX(budgets.name), '')"
+	case ExpenseGroupCategory:
+		db = db.Joins("LEFT JOIN categories ON categories.id = expenses.category_id")
+		key, label = "COALESCE(expenses.category_id, '')", "COALESCE(MAX(categories.name), '')"
+	}
+
+	db = db.Select(key + " AS group_key, " + label + " AS group_label, COALESCE(SUM(expenses.amount), 0) AS total, COUNT(*) AS count")
+	if group != ExpenseGroupNone {
+		db = db.Group(key).Order("group_key")
+	}
+
+	var totals []ExpenseGroupTotal
+	err := db.Scan(&totals).Error
+	return totals, err
+}
+
+// SumByBudgetID returns the total amount of all live approved expenses in a budget.
+func (r *ExpenseRepository) SumByBudgetID(budgetID uuid.UUID) (models.Money, error) {
+	var total models.Money
+	err := r.db.Model(&models.Expense{}).Where("budget_id = ? AND status = ?", budgetID, models.ExpenseStatusApproved).
+		Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+	return total, err
+}
+
+// SumByBudgetIDBetween returns the total of a budget's live approved expenses dated in [from, to).
+func (r *ExpenseRepository) SumByBudgetIDBetween(budgetID uuid.UUID, from, to time.Time) (models.Money, error) {
+	var total models.Money
+	err := r.db.Model(&models.Expense{}).Where("budget_id = ? AND status = ? AND date >= ? AND date < ?", budgetID, models.ExpenseStatusApproved, from, to).
+		Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+	return total, err
+}
+
 func (r *ExpenseRepository) Update(expense *models.Expense) error {
-	return r.db.Save(expense).Error
+	// Never write back a preloaded Budget; its Spent may be stale
+	return r.db.Omit(clause.Associations).Save(expense).Error
+}
+
+// UpdateStatus changes only the approval status of an expense.
+func (r *ExpenseRepository) UpdateStatus(id uuid.UUID, status models.ExpenseStatus) error {
+	return r.db.Model(&models.Expense{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// ReplaceTags sets the tags of an expense to exactly the given list.
+func (r *ExpenseRepository) ReplaceTags(expense *models.Expense, tags []models.Tag) error {
+	return r.db.Model(expense).Omit("Tags.*").Association("Tags").Replace(tags)
+}
+
+// ClearCategory removes a category from every expense and recurring expense
+// that uses it.
+func (r *ExpenseRepository) ClearCategory(categoryID uuid.UUID) error {
+	if err := r.db.Model(&models.RecurringExpense{}).Where("category_id = ?", categoryID).
+		UpdateColumn("category_id", nil).Error; err != nil {
+		return err
+	}
+	return r.db.Model(&models.Expense{}).Where("category_id = ?", categoryID).
+		UpdateColumn("category_id", nil).Error
 }
 
-func (r *ExpenseRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Expense{}, "id = ?", id).Error
+// Delete soft-deletes an expense and reports how many rows it removed, so a
+// caller racing another delete of the same expense can tell it lost.
+func (r *ExpenseRepository) Delete(id uuid.UUID) (int64, error) {
+	result := r.db.Delete(&models.Expense{}, "id = ?", id)
+	return result.RowsAffected, result.Error
 }
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImportRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewImportRuleRepository(db *gorm.DB) *ImportRuleRepository {
+	return &ImportRuleRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *ImportRuleRepository) WithTx(tx *gorm.DB) *ImportRuleRepository {
+	return &ImportRuleRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *ImportRuleRepository) InWorkspace(workspaceID uuid.UUID) *ImportRuleRepository {
+	return &ImportRuleRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
+func (r *ImportRuleRepository) Create(rule *models.ImportRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *ImportRuleRepository) FindByID(id uuid.UUID) (*models.ImportRule, error) {
+	var rule models.ImportRule
+	err := r.db.First(&rule, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindByUserID returns a user's rules in the order they are applied.
+func (r *ImportRuleRepository) FindByUserID(userID uuid.UUID) ([]models.ImportRule, error) {
+	var rules []models.ImportRule
+	err := r.db.Where("user_id = ?", userID).Order("priority DESC").Order("created_at").Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *ImportRuleRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.ImportRule{}, "id = ?", id).Error
+}
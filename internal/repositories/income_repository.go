@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IncomeRepository struct {
+	db *gorm.DB
+}
+
+func NewIncomeRepository(db *gorm.DB) *IncomeRepository {
+	return &IncomeRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *IncomeRepository) WithTx(tx *gorm.DB) *IncomeRepository {
+	return &IncomeRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *IncomeRepository) InWorkspace(workspaceID uuid.UUID) *IncomeRepository {
+	return &IncomeRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
+func (r *IncomeRepository) Create(income *models.Income) error {
+	return r.db.Omit(clause.Associations).Create(income).Error
+}
+
+// FindByIDForUpdate loads an income and holds a row lock on it until the
+// surrounding transaction ends. It must be called on a repository returned by WithTx.
+func (r *IncomeRepository) FindByIDForUpdate(id uuid.UUID) (*models.Income, error) {
+	var income models.Income
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&income, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &income, nil
+}
+
+// FindByUserID returns one page of a user's incomes dated in [from, to);
+// zero times are open ends.
+func (r *IncomeRepository) FindByUserID(userID uuid.UUID, from, to time.Time, page LedgerPage) ([]models.Income, error) {
+	var incomes []models.Income
+	err := page.apply(dateRange(r.db.Preload("Budget"), from, to), "incomes").Where("user_id = ?", userID).
+		Find(&incomes).Error
+	if err != nil {
+		return nil, err
+	}
+	return incomes, nil
+}
+
+// TotalByUserID returns how many incomes a user has dated in [from, to) and their sum.
+func (r *IncomeRepository) TotalByUserID(userID uuid.UUID, from, to time.Time) (int64, models.Money, error) {
+	var result struct {
+		Count int64
+		Total models.Money
+	}
+	err := dateRange(r.db.Model(&models.Income{}), from, to).Where("user_id = ?", userID).
+		Select("COUNT(*) AS count, COALESCE(SUM(amount), 0) AS total").Scan(&result).Error
+	return result.Count, result.Total, err
+}
+
+func (r *IncomeRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Income{}, "id = ?", id).Error
+}
+
+// FindAllByUserID returns every income of a user, soft-deleted ones included.
+func (r *IncomeRepository) FindAllByUserID(userID uuid.UUID) ([]models.Income, error) {
+	var incomes []models.Income
+	err := r.db.Unscoped().Where("user_id = ?", userID).Order("date").Find(&incomes).Error
+	if err != nil {
+		return nil, err
+	}
+	return incomes, nil
+}
+
+// LedgerCursor marks the last transaction of the previous ledger page.
+type LedgerCursor struct {
+	Date time.Time
+	ID   uuid.UUID
+}
+
+// LedgerPage selects one page of incomes or transfers, newest first with ID
+// breaking ties, the same order as the expense listing's "-date" sort. A
+// zero Limit returns everything.
+type LedgerPage struct {
+	Limit int
+	After *LedgerCursor
+}
+
+func (p LedgerPage) apply(db *gorm.DB, table string) *gorm.DB {
+	if p.After != nil {
+		db = db.Where("("+table+".date < ? OR ("+table+".date = ? AND "+table+".id < ?))",
+			p.After.Date, p.After.Date, p.After.ID)
+	}
+	db = db.Order(table + ".date DESC").Order(table + ".id DESC")
+	if p.Limit > 0 {
+		db = db.Limit(p.Limit)
+	}
+	return db
+}
+
+func dateRange(db *gorm.DB, from, to time.Time) *gorm.DB {
+	if !from.IsZero() {
+		db = db.Where("date >= ?", from)
+	}
+	if !to.IsZero() {
+		db = db.Where("date < ?", to)
+	}
+	return db
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// loginFailures are the results that count towards throttling. Attempts
+// that were already throttled don't, so waiting out the delay always helps.
+var loginFailures = []models.LoginAttemptResult{models.LoginInvalidCredentials, models.LoginInvalidCode}
+
+type LoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (r *LoginAttemptRepository) Create(attempt *models.LoginAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+// FailuresByEmail counts failed attempts for an email since the given time
+// and after its last successful sign-in, and returns when the latest was.
+func (r *LoginAttemptRepository) FailuresByEmail(email string, since time.Time) (int64, time.Time, error) {
+	var lastSuccess struct{ At *time.Time }
+	err := r.db.Model(&models.LoginAttempt{}).Select("MAX(created_at) AS at").
+		Where("email = ? AND success = ? AND created_at >= ?", email, true, since).Scan(&lastSuccess).Error
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if lastSuccess.At != nil {
+		since = *lastSuccess.At
+	}
+	return r.failures(r.db.Where("email = ?", email), since)
+}
+
+// FailuresByIP counts failed attempts from an IP address since the given
+// time and returns when the latest was. Successful sign-ins don't reset the
+// count, as one address may try many accounts.
+func (r *LoginAttemptRepository) FailuresByIP(ip string, since time.Time) (int64, time.Time, error) {
+	return r.failures(r.db.Where("ip = ?", ip), since)
+}
+
+func (r *LoginAttemptRepository) failures(scope *gorm.DB, since time.Time) (int64, time.Time, error) {
+	var stats struct {
+		Count  int64
+		LastAt *time.Time
+	}
+	err := scope.Model(&models.LoginAttempt{}).Select("COUNT(*) AS count, MAX(created_at) AS last_at").
+		Where("result IN ? AND created_at > ?", loginFailures, since).Scan(&stats).Error
+	if err != nil || stats.LastAt == nil {
+		return 0, time.Time{}, err
+	}
+	return stats.Count, *stats.LastAt, nil
+}
+
+// FindRecentByUserID returns a user's latest sign-in attempts, newest first.
+func (r *LoginAttemptRepository) FindRecentByUserID(userID uuid.UUID, limit int) ([]models.LoginAttempt, error) {
+	var attempts []models.LoginAttempt
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&attempts).Error
+	return attempts, err
+}
+
+// DeleteOlderThan removes attempts recorded before the given time.
+func (r *LoginAttemptRepository) DeleteOlderThan(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.LoginAttempt{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *RecoveryCodeRepository) WithTx(tx *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: tx}
+}
+
+// Replace deletes all of a user's recovery codes and stores the given ones.
+func (r *RecoveryCodeRepository) Replace(userID uuid.UUID, codes []models.RecoveryCode) error {
+	if err := r.DeleteByUserID(userID); err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return r.db.Create(&codes).Error
+}
+
+// FindUnusedForUpdate loads an unused code by its hash and locks it, so it
+// can only be used once.
+func (r *RecoveryCodeRepository) FindUnusedForUpdate(userID uuid.UUID, hash string) (*models.RecoveryCode, error) {
+	var code models.RecoveryCode
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&code, "user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *RecoveryCodeRepository) Update(code *models.RecoveryCode) error {
+	return r.db.Save(code).Error
+}
+
+func (r *RecoveryCodeRepository) CountUnused(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *RecoveryCodeRepository) DeleteByUserID(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
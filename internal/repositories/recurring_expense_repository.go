@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RecurringExpenseRepository struct {
+	db *gorm.DB
+}
+
+func NewRecurringExpenseRepository(db *gorm.DB) *RecurringExpenseRepository {
+	return &RecurringExpenseRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *RecurringExpenseRepository) WithTx(tx *gorm.DB) *RecurringExpenseRepository {
+	return &RecurringExpenseRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *RecurringExpenseRepository) InWorkspace(workspaceID uuid.UUID) *RecurringExpenseRepository {
+	return &RecurringExpenseRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
+func (r *RecurringExpenseRepository) Create(recurring *models.RecurringExpense) error {
+	return r.db.Omit("Budget").Create(recurring).Error
+}
+
+func (r *RecurringExpenseRepository) FindByID(id uuid.UUID) (*models.RecurringExpense, error) {
+	var recurring models.RecurringExpense
+	err := r.db.Preload("Budget").First(&recurring, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &recurring, nil
+}
+
+// FindByIDForUpdate loads a recurring expense and holds a row lock on it until
+// the surrounding transaction ends. It must be called on a repository returned by WithTx.
+func (r *RecurringExpenseRepository) FindByIDForUpdate(id uuid.UUID) (*models.RecurringExpense, error) {
+	var recurring models.RecurringExpense
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&recurring, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &recurring, nil
+}
+
+// FindByUserID returns a user's recurring expenses, next occurrence first.
+// Finished schedules come last.
+func (r *RecurringExpenseRepository) FindByUserID(userID uuid.UUID) ([]models.RecurringExpense, error) {
+	var recurring []models.RecurringExpense
+	err := r.db.Preload("Budget").Where("user_id = ?", userID).
+		Order("next_date IS NULL").Order("next_date").Order("created_at").Find(&recurring).Error
+	if err != nil {
+		return nil, err
+	}
+	return recurring, nil
+}
+
+// FindDue returns the IDs of recurring expenses with an occurrence at or before now.
+func (r *RecurringExpenseRepository) FindDue(now time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.RecurringExpense{}).Where("next_date <= ?", now).Order("next_date").Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *RecurringExpenseRepository) Update(recurring *models.RecurringExpense) error {
+	return r.db.Omit(clause.Associations).Save(recurring).Error
+}
+
+// RecordFailure notes why a scheduler run failed on a recurring expense.
+func (r *RecurringExpenseRepository) RecordFailure(id uuid.UUID, message string, at time.Time) error {
+	return r.db.Model(&models.RecurringExpense{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"last_error": message, "last_failed_at": at}).Error
+}
+
+// Delete removes a recurring expense together with its occurrences. Booked
+// expenses stay.
+func (r *RecurringExpenseRepository) Delete(id uuid.UUID) error {
+	if err := r.db.Where("recurring_expense_id = ?", id).Delete(&models.RecurringOccurrence{}).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(&models.RecurringExpense{}, "id = ?", id).Error
+}
+
+// FindOccurrences returns the recorded occurrences of several recurring
+// expenses dated in [from, to).
+func (r *RecurringExpenseRepository) FindOccurrences(recurringIDs []uuid.UUID, from, to time.Time) ([]models.RecurringOccurrence, error) {
+	var occurrences []models.RecurringOccurrence
+	if len(recurringIDs) == 0 {
+		return occurrences, nil
+	}
+	err := r.db.Where("recurring_expense_id IN ? AND date >= ? AND date < ?", recurringIDs, from, to).
+		Order("date").Find(&occurrences).Error
+	if err != nil {
+		return nil, err
+	}
+	return occurrences, nil
+}
+
+// FindOccurrence returns the recorded occurrence of a recurring expense on date.
+func (r *RecurringExpenseRepository) FindOccurrence(recurringID uuid.UUID, date time.Time) (*models.RecurringOccurrence, error) {
+	var occurrence models.RecurringOccurrence
+	err := r.db.First(&occurrence, "recurring_expense_id = ? AND date = ?", recurringID, date).Error
+	if err != nil {
+		return nil, err
+	}
+	return &occurrence, nil
+}
+
+func (r *RecurringExpenseRepository) CreateOccurrence(occurrence *models.RecurringOccurrence) error {
+	return r.db.Create(occurrence).Error
+}
+
+func (r *RecurringExpenseRepository) UpdateOccurrence(occurrence *models.RecurringOccurrence) error {
+	return r.db.Save(occurrence).Error
+}
+
+// DeletePendingOccurrences removes the skips and edits of occurrences dated
+// from onwards that the scheduler has not reached yet, for when the schedule
+// itself changes.
+func (r *RecurringExpenseRepository) DeletePendingOccurrences(recurringID uuid.UUID, from time.Time) error {
+	return r.db.Where("recurring_expense_id = ? AND date >= ? AND status IN ?", recurringID, from,
+		[]models.OccurrenceStatus{models.OccurrenceScheduled, models.OccurrenceSkipped}).
+		Delete(&models.RecurringOccurrence{}).Error
+}
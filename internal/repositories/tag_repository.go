@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *TagRepository) WithTx(tx *gorm.DB) *TagRepository {
+	return &TagRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *TagRepository) InWorkspace(workspaceID uuid.UUID) *TagRepository {
+	return &TagRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
+func (r *TagRepository) FindByUserID(userID uuid.UUID) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.Where("user_id = ?", userID).Order("name").Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// FindOrCreate returns the user's tags with the given names, creating the
+// ones that do not exist yet.
+func (r *TagRepository) FindOrCreate(userID uuid.UUID, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	candidates := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		candidates = append(candidates, models.Tag{ID: uuid.New(), UserID: userID, Name: name})
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	var tags []models.Tag
+	err := r.db.Where("user_id = ? AND name IN ?", userID, names).Order("name").Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
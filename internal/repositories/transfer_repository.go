@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *TransferRepository) WithTx(tx *gorm.DB) *TransferRepository {
+	return &TransferRepository{db: tx}
+}
+
+// InWorkspace returns a copy of the repository that only sees rows of the given workspace.
+func (r *TransferRepository) InWorkspace(workspaceID uuid.UUID) *TransferRepository {
+	return &TransferRepository{db: ScopeWorkspace(r.db, workspaceID)}
+}
+
+func (r *TransferRepository) Create(transfer *models.Transfer) error {
+	return r.db.Omit(clause.Associations).Create(transfer).Error
+}
+
+// FindByIDForUpdate loads a transfer and holds a row lock on it until the
+// surrounding transaction ends. It must be called on a repository returned by WithTx.
+func (r *TransferRepository) FindByIDForUpdate(id uuid.UUID) (*models.Transfer, error) {
+	var transfer models.Transfer
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transfer, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// FindByUserID returns one page of a user's transfers dated in [from, to);
+// zero times are open ends.
+func (r *TransferRepository) FindByUserID(userID uuid.UUID, from, to time.Time, page LedgerPage) ([]models.Transfer, error) {
+	var transfers []models.Transfer
+	err := page.apply(dateRange(r.db.Preload("FromBudget").Preload("ToBudget"), from, to), "transfers").Where("user_id = ?", userID).
+		Find(&transfers).Error
+	if err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// CountByUserID returns how many transfers a user has dated in [from, to).
+func (r *TransferRepository) CountByUserID(userID uuid.UUID, from, to time.Time) (int64, error) {
+	var count int64
+	err := dateRange(r.db.Model(&models.Transfer{}), from, to).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *TransferRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Transfer{}, "id = ?", id).Error
+}
+
+// FindAllByUserID returns every transfer of a user, soft-deleted ones included.
+func (r *TransferRepository) FindAllByUserID(userID uuid.UUID) ([]models.Transfer, error) {
+	var transfers []models.Transfer
+	err := r.db.Unscoped().Where("user_id = ?", userID).Order("date").Find(&transfers).Error
+	if err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/Alvarras/dompet-g0/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -14,6 +15,11 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *UserRepository) WithTx(tx *gorm.DB) *UserRepository {
+	return &UserRepository{db: tx}
+}
+
 func (r *UserRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
@@ -36,6 +42,17 @@ func (r *UserRepository) FindByID(id uuid.UUID) (*models.User, error) {
 	return &user, nil
 }
 
+// FindByIDForUpdate loads a user and locks the row until the transaction
+// ends.
+func (r *UserRepository) FindByIDForUpdate(id uuid.UUID) (*models.User, error) {
+	var user models.User
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *UserRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
@@ -43,3 +60,99 @@ func (r *UserRepository) Update(user *models.User) error {
 func (r *UserRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.User{}, "id = ?", id).Error
 }
+
+// HandOverBudgets gives the budgets a user created in shared workspaces to
+// another owner of the workspace.
+func (r *UserRepository) HandOverBudgets(id uuid.UUID) error {
+	return r.db.Exec("UPDATE budgets JOIN workspace_members ON workspace_members.workspace_id = budgets.workspace_id AND workspace_members.role = ? AND workspace_members.user_id <> ? SET budgets.user_id = workspace_members.user_id WHERE budgets.user_id = ?",
+		models.WorkspaceRoleOwner, id, id).Error
+}
+
+// DeleteWithData permanently removes a user together with everything the
+// user owns, including the personal workspace, budgets shared with others
+// and every expense in them. Budgets the user created in shared workspaces
+// are handed to another owner of the workspace, and expenses the user
+// recorded in budgets of other users stay there and are handed to the
+// budget owner. It must be called on a repository returned by WithTx.
+func (r *UserRepository) DeleteWithData(id uuid.UUID) error {
+	if err := r.HandOverBudgets(id); err != nil {
+		return err
+	}
+
+	budgetIDs := r.db.Unscoped().Model(&models.Budget{}).Select("id").Where("user_id = ?", id)
+	sharedExpenseIDs := r.db.Unscoped().Model(&models.Expense{}).Select("id").Where("user_id = ? AND budget_id NOT IN (?)", id, budgetIDs)
+
+	// Tags and categories of the user are deleted below, so they are taken
+	// off the expenses that stay behind
+	if err := r.db.Exec("DELETE FROM expense_tags WHERE expense_id IN (?)", sharedExpenseIDs).Error; err != nil {
+		return err
+	}
+	if err := r.db.Exec("UPDATE expenses JOIN budgets ON budgets.id = expenses.budget_id SET expenses.user_id = budgets.user_id, expenses.category_id = NULL WHERE expenses.user_id = ? AND budgets.user_id <> ?", id, id).Error; err != nil {
+		return err
+	}
+
+	expenseIDs := r.db.Unscoped().Model(&models.Expense{}).Select("id").Where("user_id = ? OR budget_id IN (?)", id, budgetIDs)
+	if err := r.db.Exec("DELETE FROM expense_tags WHERE expense_id IN (?)", expenseIDs).Error; err != nil {
+		return err
+	}
+	// Approval steps the user took on expenses that stay behind go as well
+	if err := r.db.Where("user_id = ? OR expense_id IN (?)", id, expenseIDs).Delete(&models.ExpenseApproval{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Unscoped().Where("user_id = ? OR budget_id IN (?)", id, budgetIDs).Delete(&models.Expense{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Unscoped().Where("budget_id IN (?)", budgetIDs).Delete(&models.BudgetHistory{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("user_id = ? OR budget_id IN (?)", id, budgetIDs).Delete(&models.BudgetMember{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("budget_id IN (?)", budgetIDs).Delete(&models.BudgetInvitation{}).Error; err != nil {
+		return err
+	}
+	recurringIDs := r.db.Unscoped().Model(&models.RecurringExpense{}).Select("id").Where("user_id = ? OR budget_id IN (?)", id, budgetIDs)
+	if err := r.db.Where("recurring_expense_id IN (?)", recurringIDs).Delete(&models.RecurringOccurrence{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("user_id = ? OR budget_id IN (?)", id, budgetIDs).Delete(&models.RecurringExpense{}).Error; err != nil {
+		return err
+	}
+	alertIDs := r.db.Model(&models.BudgetAlert{}).Select("id").Where("user_id = ? OR budget_id IN (?)", id, budgetIDs)
+	if err := r.db.Where("alert_id IN (?) OR budget_id IN (?)", alertIDs, budgetIDs).Delete(&models.WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("user_id = ? OR budget_id IN (?)", id, budgetIDs).Delete(&models.BudgetAlert{}).Error; err != nil {
+		return err
+	}
+
+	owned := []any{
+		&models.Income{},
+		&models.Transfer{},
+		&models.ImportRule{},
+		&models.Tag{},
+		&models.Category{},
+		&models.Budget{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.UserToken{},
+		&models.RecoveryCode{},
+		&models.LoginAttempt{},
+		&models.APIKey{},
+	}
+	for _, model := range owned {
+		if err := r.db.Unscoped().Where("user_id = ?", id).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := r.db.Where("user_id = ?", id).Delete(&models.WorkspaceMember{}).Error; err != nil {
+		return err
+	}
+	// Whatever guests recorded in the personal workspace goes with it
+	if err := (&WorkspaceRepository{db: r.db}).DeleteWithData(id); err != nil {
+		return err
+	}
+
+	return r.db.Unscoped().Delete(&models.User{}, "id = ?", id).Error
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *UserTokenRepository) WithTx(tx *gorm.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: tx}
+}
+
+func (r *UserTokenRepository) Create(token *models.UserToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindByHashForUpdate loads a token for the given purpose and locks it, so
+// it can only be used once.
+func (r *UserTokenRepository) FindByHashForUpdate(hash string, purpose models.UserTokenPurpose) (*models.UserToken, error) {
+	var token models.UserToken
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&token, "token_hash = ? AND purpose = ?", hash, purpose).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *UserTokenRepository) Update(token *models.UserToken) error {
+	return r.db.Save(token).Error
+}
+
+// InvalidateByUserID marks a user's unused tokens for a purpose as used, so
+// only the most recently sent one works.
+func (r *UserTokenRepository) InvalidateByUserID(userID uuid.UUID, purpose models.UserTokenPurpose, at time.Time) error {
+	return r.db.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", at).Error
+}
+
+// DeleteExpired removes tokens that expired before the given time.
+func (r *UserTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&models.UserToken{})
+	return result.RowsAffected, result.Error
+}
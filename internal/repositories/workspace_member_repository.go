@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WorkspaceMemberRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceMemberRepository(db *gorm.DB) *WorkspaceMemberRepository {
+	return &WorkspaceMemberRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *WorkspaceMemberRepository) WithTx(tx *gorm.DB) *WorkspaceMemberRepository {
+	return &WorkspaceMemberRepository{db: tx}
+}
+
+func (r *WorkspaceMemberRepository) Create(member *models.WorkspaceMember) error {
+	return r.db.Omit(clause.Associations).Create(member).Error
+}
+
+func (r *WorkspaceMemberRepository) Find(workspaceID, userID uuid.UUID) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := r.db.First(&member, "workspace_id = ? AND user_id = ?", workspaceID, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindByWorkspaceID returns the members of a workspace with their accounts,
+// oldest first.
+func (r *WorkspaceMemberRepository) FindByWorkspaceID(workspaceID uuid.UUID) ([]models.WorkspaceMember, error) {
+	var members []models.WorkspaceMember
+	err := r.db.Preload("User").Where("workspace_id = ?", workspaceID).Order("created_at").Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// FindByUserID returns the memberships of a user with their workspaces.
+func (r *WorkspaceMemberRepository) FindByUserID(userID uuid.UUID) ([]models.WorkspaceMember, error) {
+	var members []models.WorkspaceMember
+	err := r.db.Preload("Workspace").Where("user_id = ?", userID).Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// CountOwners returns how many owners a workspace has.
+func (r *WorkspaceMemberRepository) CountOwners(workspaceID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, models.WorkspaceRoleOwner).Count(&count).Error
+	return count, err
+}
+
+func (r *WorkspaceMemberRepository) Update(member *models.WorkspaceMember) error {
+	return r.db.Omit(clause.Associations).Save(member).Error
+}
+
+func (r *WorkspaceMemberRepository) Delete(workspaceID, userID uuid.UUID) error {
+	return r.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&models.WorkspaceMember{}).Error
+}
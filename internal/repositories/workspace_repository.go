@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction.
+func (r *WorkspaceRepository) WithTx(tx *gorm.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{db: tx}
+}
+
+func (r *WorkspaceRepository) Create(workspace *models.Workspace) error {
+	return r.db.Create(workspace).Error
+}
+
+func (r *WorkspaceRepository) FindByID(id uuid.UUID) (*models.Workspace, error) {
+	var workspace models.Workspace
+	err := r.db.First(&workspace, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// FindByIDs returns the given workspaces ordered by name.
+func (r *WorkspaceRepository) FindByIDs(ids []uuid.UUID) ([]models.Workspace, error) {
+	var workspaces []models.Workspace
+	if len(ids) == 0 {
+		return workspaces, nil
+	}
+	err := r.db.Where("id IN ?", ids).Order("name").Find(&workspaces).Error
+	if err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (r *WorkspaceRepository) Update(workspace *models.Workspace) error {
+	return r.db.Save(workspace).Error
+}
+
+// HasRecords reports whether budgets, incomes or transfers were recorded
+// in a workspace.
+func (r *WorkspaceRepository) HasRecords(id uuid.UUID) (bool, error) {
+	for _, model := range []any{&models.Budget{}, &models.Income{}, &models.Transfer{}} {
+		var count int64
+		if err := r.db.Model(model).Where("workspace_id = ?", id).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteWithData permanently removes a workspace, its members and every row
+// recorded in it, soft-deleted ones included. It must be called on a
+// repository returned by WithTx.
+func (r *WorkspaceRepository) DeleteWithData(id uuid.UUID) error {
+	expenseIDs := r.db.Unscoped().Model(&models.Expense{}).Select("id").Where("workspace_id = ?", id)
+	if err := r.db.Exec("DELETE FROM expense_tags WHERE expense_id IN (?)", expenseIDs).Error; err != nil {
+		return err
+	}
+
+	owned := []any{
+		&models.ExpenseApproval{},
+		&models.Expense{},
+		&models.BudgetHistory{},
+		&models.BudgetMember{},
+		&models.BudgetInvitation{},
+		&models.Income{},
+		&models.Transfer{},
+		&models.ImportRule{},
+		&models.RecurringOccurrence{},
+		&models.RecurringExpense{},
+		&models.WebhookDelivery{},
+		&models.BudgetAlert{},
+		&models.Tag{},
+		&models.Category{},
+		&models.Budget{},
+		&models.WorkspaceMember{},
+	}
+	for _, model := range owned {
+		if err := r.db.Unscoped().Where("workspace_id = ?", id).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	return r.db.Delete(&models.Workspace{}, "id = ?", id).Error
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type workspaceKey struct{}
+
+// ScopeWorkspace returns a session that only sees rows of the given
+// workspace. Every query, update and delete on a model with a WorkspaceID
+// field is filtered by it, preloads and subqueries included, and new rows
+// are stamped with it. See RegisterWorkspaceScope.
+func ScopeWorkspace(db *gorm.DB, workspaceID uuid.UUID) *gorm.DB {
+	return db.WithContext(context.WithValue(db.Statement.Context, workspaceKey{}, workspaceID))
+}
+
+// RegisterWorkspaceScope installs the callbacks that enforce ScopeWorkspace.
+// Sessions without a workspace, such as background jobs, are not filtered.
+func RegisterWorkspaceScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("workspace:filter", filterWorkspace); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("workspace:filter", filterWorkspace); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("workspace:filter", filterWorkspace); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("workspace:filter", filterWorkspace); err != nil {
+		return err
+	}
+	return callbacks.Create().Before("gorm:create").Register("workspace:assign", assignWorkspace)
+}
+
+func scopedWorkspace(db *gorm.DB) (uuid.UUID, *schema.Field, bool) {
+	workspaceID, ok := db.Statement.Context.Value(workspaceKey{}).(uuid.UUID)
+	if !ok || db.Statement.Schema == nil {
+		return uuid.Nil, nil, false
+	}
+	field := db.Statement.Schema.LookUpField("WorkspaceID")
+	return workspaceID, field, field != nil
+}
+
+func filterWorkspace(db *gorm.DB) {
+	workspaceID, field, ok := scopedWorkspace(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: field.DBName}, Value: workspaceID},
+	}})
+}
+
+func assignWorkspace(db *gorm.DB) {
+	workspaceID, field, ok := scopedWorkspace(db)
+	if !ok {
+		return
+	}
+
+	assign := func(row reflect.Value) {
+		row = reflect.Indirect(row)
+		current, zero := field.ValueOf(db.Statement.Context, row)
+		if zero {
+			db.AddError(field.Set(db.Statement.Context, row, workspaceID))
+		} else if current != workspaceID {
+			db.AddError(errors.New("record belongs to another workspace"))
+		}
+	}
+
+	switch db.Statement.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < db.Statement.ReflectValue.Len(); i++ {
+			assign(db.Statement.ReflectValue.Index(i))
+		}
+	case reflect.Struct:
+		assign(db.Statement.ReflectValue)
+	}
+}
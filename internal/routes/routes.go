@@ -3,36 +3,157 @@ package routes
 import (
 	"github.com/Alvarras/dompet-g0/internal/controllers"
 	"github.com/Alvarras/dompet-g0/internal/middlewares"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/utils"
 	"github.com/labstack/echo/v4"
 )
 
 // SetupRoutes configures all routes for the application
-func SetupRoutes(e *echo.Echo, jwtSecret string, authController *controllers.AuthController, budgetController *controllers.BudgetController, expenseController *controllers.ExpenseController) {
+func SetupRoutes(e *echo.Echo, jwtKeys *utils.KeySet, denylist middlewares.TokenDenylist, apiKeys middlewares.APIKeyAuthenticator, workspaces middlewares.WorkspaceResolver, authController *controllers.AuthController, budgetController *controllers.BudgetController, expenseController *controllers.ExpenseController, categoryController *controllers.CategoryController, transactionController *controllers.TransactionController, reportController *controllers.ReportController, importController *controllers.ImportController, exportController *controllers.ExportController, backupController *controllers.BackupController, profileController *controllers.ProfileController, twoFactorController *controllers.TwoFactorController, apiKeyController *controllers.APIKeyController, budgetMemberController *controllers.BudgetMemberController, workspaceController *controllers.WorkspaceController, recurringExpenseController *controllers.RecurringExpenseController, budgetAlertController *controllers.BudgetAlertController) {
+	// Public verification keys for other services
+	e.GET("/.well-known/jwks.json", authController.JWKS)
+
 	// API version group
 	v1 := e.Group("/api/v1")
 	{
 		// Public routes
 		v1.POST("/register", authController.Register)
 		v1.POST("/login", authController.Login)
+		v1.POST("/login/mfa", authController.LoginMFA)
+		v1.POST("/token/refresh", authController.Refresh)
+		v1.POST("/email/confirm", profileController.ConfirmEmailChange)
+		v1.POST("/password/forgot", authController.ForgotPassword)
+		v1.POST("/password/reset", authController.ResetPassword)
+		v1.POST("/verify-email", authController.VerifyEmail)
+		v1.POST("/verify-email/resend", authController.ResendVerification)
 
-		// Protected routes
+		// Protected routes, open to JWTs and API keys
 		protected := v1.Group("")
-		protected.Use(middlewares.AuthMiddleware(jwtSecret))
+		protected.Use(middlewares.AuthMiddleware(jwtKeys, denylist, apiKeys))
+		// Unverified accounts can still sign out and fix their email address
+		protected.Use(middlewares.LimitUnverified("/api/v1/logout", "/api/v1/me/email"))
 		{
+			protected.POST("/logout", authController.Logout, middlewares.RequireSession())
+
+			// Profile routes, never available to API keys
+			me := protected.Group("/me", middlewares.RequireSession())
+			me.GET("", profileController.GetProfile)
+			me.PUT("", profileController.UpdateProfile)
+			me.DELETE("", profileController.DeleteAccount)
+			me.GET("/sessions", profileController.GetSignIns)
+			me.POST("/password", profileController.ChangePassword)
+			me.POST("/email", profileController.RequestEmailChange)
+
+			// Two-factor authentication routes
+			twoFactor := me.Group("/2fa")
+			twoFactor.GET("", twoFactorController.GetStatus)
+			twoFactor.POST("/setup", twoFactorController.Setup)
+			twoFactor.POST("/enable", twoFactorController.Enable)
+			twoFactor.POST("/disable", twoFactorController.Disable)
+			twoFactor.POST("/recovery-codes", twoFactorController.RegenerateRecoveryCodes)
+
+			// API key routes
+			apiKeyRoutes := me.Group("/api-keys")
+			apiKeyRoutes.POST("", apiKeyController.CreateAPIKey)
+			apiKeyRoutes.GET("", apiKeyController.GetAPIKeys)
+			apiKeyRoutes.DELETE("/:id", apiKeyController.RevokeAPIKey)
+
+			// Joining a shared budget
+			protected.POST("/invitations/accept", budgetMemberController.AcceptInvitation, middlewares.RequireSession())
+
+			// Workspace routes
+			workspaceRoutes := protected.Group("/workspaces", middlewares.RequireSession())
+			workspaceRoutes.POST("", workspaceController.CreateWorkspace)
+			workspaceRoutes.GET("", workspaceController.GetWorkspaces)
+			workspaceRoutes.PUT("/:id", workspaceController.UpdateWorkspace)
+			workspaceRoutes.DELETE("/:id", workspaceController.DeleteWorkspace)
+			workspaceRoutes.GET("/:id/members", workspaceController.GetMembers)
+			workspaceRoutes.POST("/:id/members", workspaceController.AddMember)
+			workspaceRoutes.PUT("/:id/members/:user_id", workspaceController.UpdateMemberRole)
+			workspaceRoutes.DELETE("/:id/members/:user_id", workspaceController.RemoveMember)
+
+			// Every route below needs a scope when called with an API key and
+			// works in the workspace selected by the X-Workspace-ID header
+			inWorkspace := middlewares.RequireWorkspace(workspaces)
+
 			// Budget routes
-			budgets := protected.Group("/budgets")
-			budgets.POST("", budgetController.CreateBudget)
-			budgets.GET("", budgetController.GetBudgets)
-			budgets.PUT("/:id", budgetController.UpdateBudget)
-			budgets.DELETE("/:id", budgetController.DeleteBudget)
+			budgets := protected.Group("/budgets", inWorkspace)
+			budgets.POST("", budgetController.CreateBudget, middlewares.RequireScope(models.ScopeBudgetsWrite))
+			budgets.GET("", budgetController.GetBudgets, middlewares.RequireScope(models.ScopeBudgetsRead))
+			budgets.PUT("/:id", budgetController.UpdateBudget, middlewares.RequireScope(models.ScopeBudgetsWrite))
+			budgets.DELETE("/:id", budgetController.DeleteBudget, middlewares.RequireScope(models.ScopeBudgetsWrite))
+
+			// Budget sharing routes
+			budgets.GET("/:id/members", budgetMemberController.GetMembers, middlewares.RequireScope(models.ScopeBudgetsRead))
+			budgets.POST("/:id/members", budgetMemberController.InviteMember, middlewares.RequireScope(models.ScopeBudgetsWrite))
+			budgets.PUT("/:id/members/:user_id", budgetMemberController.UpdateMemberRole, middlewares.RequireScope(models.ScopeBudgetsWrite))
+			budgets.DELETE("/:id/members/:user_id", budgetMemberController.RemoveMember, middlewares.RequireScope(models.ScopeBudgetsWrite))
+			budgets.DELETE("/:id/invitations/:invitation_id", budgetMemberController.RevokeInvitation, middlewares.RequireScope(models.ScopeBudgetsWrite))
+
+			// Budget alert routes
+			budgets.POST("/:id/alerts", budgetAlertController.CreateAlert, middlewares.RequireScope(models.ScopeBudgetsWrite))
+			budgets.GET("/:id/alerts", budgetAlertController.GetAlerts, middlewares.RequireScope(models.ScopeBudgetsRead))
+			budgets.PUT("/:id/alerts/:alert_id", budgetAlertController.UpdateAlert, middlewares.RequireScope(models.ScopeBudgetsWrite))
+			budgets.DELETE("/:id/alerts/:alert_id", budgetAlertController.DeleteAlert, middlewares.RequireScope(models.ScopeBudgetsWrite))
+			budgets.POST("/:id/alerts/:alert_id/test", budgetAlertController.TestAlert, middlewares.RequireScope(models.ScopeBudgetsWrite))
+			budgets.GET("/:id/alerts/:alert_id/deliveries", budgetAlertController.GetDeliveries, middlewares.RequireScope(models.ScopeBudgetsRead))
 
 			// Expense routes
-			expenses := protected.Group("/expenses")
-			expenses.POST("", expenseController.CreateExpense)
-			expenses.GET("", expenseController.GetExpenses)
-			expenses.GET("/budget/:budget_id", expenseController.GetExpensesByBudget)
-			expenses.PUT("/:id", expenseController.UpdateExpense)
-			expenses.DELETE("/:id", expenseController.DeleteExpense)
+			expenses := protected.Group("/expenses", inWorkspace)
+			expenses.POST("", expenseController.CreateExpense, middlewares.RequireScope(models.ScopeExpensesWrite))
+			expenses.GET("", expenseController.GetExpenses, middlewares.RequireScope(models.ScopeExpensesRead))
+			expenses.GET("/budget/:budget_id", expenseController.GetExpensesByBudget, middlewares.RequireScope(models.ScopeExpensesRead))
+			expenses.PUT("/:id", expenseController.UpdateExpense, middlewares.RequireScope(models.ScopeExpensesWrite))
+			expenses.DELETE("/:id", expenseController.DeleteExpense, middlewares.RequireScope(models.ScopeExpensesWrite))
+			expenses.GET("/approvals", expenseController.GetPendingApprovals, middlewares.RequireScope(models.ScopeExpensesRead))
+			expenses.GET("/:id/approvals", expenseController.GetApprovalHistory, middlewares.RequireScope(models.ScopeExpensesRead))
+			expenses.POST("/:id/approve", expenseController.ApproveExpense, middlewares.RequireScope(models.ScopeExpensesWrite))
+			expenses.POST("/:id/reject", expenseController.RejectExpense, middlewares.RequireScope(models.ScopeExpensesWrite))
+
+			// Recurring expense routes
+			recurring := protected.Group("/recurring-expenses", inWorkspace)
+			recurring.POST("", recurringExpenseController.CreateRecurringExpense, middlewares.RequireScope(models.ScopeExpensesWrite))
+			recurring.GET("", recurringExpenseController.GetRecurringExpenses, middlewares.RequireScope(models.ScopeExpensesRead))
+			recurring.GET("/upcoming", recurringExpenseController.GetUpcomingOccurrences, middlewares.RequireScope(models.ScopeExpensesRead))
+			recurring.PUT("/:id", recurringExpenseController.UpdateRecurringExpense, middlewares.RequireScope(models.ScopeExpensesWrite))
+			recurring.DELETE("/:id", recurringExpenseController.DeleteRecurringExpense, middlewares.RequireScope(models.ScopeExpensesWrite))
+			recurring.POST("/:id/occurrences/:date/skip", recurringExpenseController.SkipOccurrence, middlewares.RequireScope(models.ScopeExpensesWrite))
+			recurring.PUT("/:id/occurrences/:date", recurringExpenseController.UpdateOccurrence, middlewares.RequireScope(models.ScopeExpensesWrite))
+
+			// Category routes
+			categories := protected.Group("/categories", inWorkspace)
+			categories.POST("", categoryController.CreateCategory, middlewares.RequireScope(models.ScopeCategoriesWrite))
+			categories.GET("", categoryController.GetCategories, middlewares.RequireScope(models.ScopeCategoriesRead))
+			categories.PUT("/:id", categoryController.UpdateCategory, middlewares.RequireScope(models.ScopeCategoriesWrite))
+			categories.DELETE("/:id", categoryController.DeleteCategory, middlewares.RequireScope(models.ScopeCategoriesWrite))
+
+			// Tag routes
+			protected.GET("/tags", categoryController.GetTags, inWorkspace, middlewares.RequireScope(models.ScopeCategoriesRead))
+
+			// Transaction routes
+			transactions := protected.Group("/transactions", inWorkspace)
+			transactions.POST("", transactionController.CreateTransaction, middlewares.RequireScope(models.ScopeTransactionsWrite))
+			transactions.GET("", transactionController.GetTransactions, middlewares.RequireScope(models.ScopeTransactionsRead))
+			transactions.DELETE("/:id", transactionController.DeleteTransaction, middlewares.RequireScope(models.ScopeTransactionsWrite))
+
+			// Report routes
+			reports := protected.Group("/reports", inWorkspace)
+			reports.GET("/summary", reportController.GetSummary, middlewares.RequireScope(models.ScopeReportsRead))
+
+			// Import routes
+			imports := protected.Group("/imports", inWorkspace)
+			imports.POST("/preview", importController.PreviewImport, middlewares.RequireScope(models.ScopeImportsWrite))
+			imports.POST("/commit", importController.CommitImport, middlewares.RequireScope(models.ScopeImportsWrite))
+			imports.POST("/rules", importController.CreateRule, middlewares.RequireScope(models.ScopeImportsWrite))
+			imports.GET("/rules", importController.GetRules, middlewares.RequireScope(models.ScopeImportsRead))
+			imports.DELETE("/rules/:id", importController.DeleteRule, middlewares.RequireScope(models.ScopeImportsWrite))
+
+			// Export routes
+			protected.GET("/export", exportController.Export, inWorkspace, middlewares.RequireScope(models.ScopeExportsRead))
+
+			// Backup routes
+			protected.GET("/backup", backupController.Backup, inWorkspace, middlewares.RequireScope(models.ScopeBackupRead))
+			protected.POST("/backup/restore", backupController.Restore, inWorkspace, middlewares.RequireScope(models.ScopeBackupWrite))
 		}
 	}
 }
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/google/uuid"
+)
+
+// apiKeyTouchInterval limits how often the last use of a key is written
+const apiKeyTouchInterval = time.Minute
+
+type APIKeyService struct {
+	apiKeyRepo *repositories.APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepo *repositories.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{apiKeyRepo: apiKeyRepo}
+}
+
+// CreateAPIKey returns the new key together with its details. The key is
+// not stored and can't be shown again.
+func (s *APIKeyService) CreateAPIKey(userID uuid.UUID, req *requests.CreateAPIKeyRequest) (*responses.CreatedAPIKeyResponse, error) {
+	var scopes []string
+	for _, scope := range req.Scopes {
+		if !slices.Contains(models.APIScopes, scope) {
+			return nil, errors.New("invalid scope")
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	key, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &models.APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    key[:len(utils.APIKeyPrefix)+8],
+		KeyHash:   utils.HashToken(key),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.apiKeyRepo.Create(apiKey); err != nil {
+		return nil, err
+	}
+
+	return &responses.CreatedAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (s *APIKeyService) GetAPIKeys(userID uuid.UUID) ([]responses.APIKeyResponse, error) {
+	keys, err := s.apiKeyRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]responses.APIKeyResponse, len(keys))
+	for i := range keys {
+		response[i] = toAPIKeyResponse(&keys[i])
+	}
+	return response, nil
+}
+
+// RevokeAPIKey stops a key from working. It stays listed so its last use
+// can still be seen.
+func (s *APIKeyService) RevokeAPIKey(userID, id uuid.UUID) error {
+	apiKey, err := s.apiKeyRepo.FindByID(id)
+	if err != nil || apiKey.UserID != userID {
+		return errors.New("api key not found")
+	}
+	if apiKey.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	apiKey.RevokedAt = &now
+	return s.apiKeyRepo.Update(apiKey)
+}
+
+// AuthenticateAPIKey returns the active key matching the given secret.
+func (s *APIKeyService) AuthenticateAPIKey(key string) (*models.APIKey, error) {
+	apiKey, err := s.apiKeyRepo.FindByHash(utils.HashToken(key))
+	now := time.Now()
+	if err != nil || !apiKey.Active(now) {
+		return nil, errors.New("invalid api key")
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.apiKeyRepo.Touch(apiKey.ID, now); err != nil {
+			return nil, err
+		}
+		apiKey.LastUsedAt = &now
+	}
+	return apiKey, nil
+}
+
+func toAPIKeyResponse(apiKey *models.APIKey) responses.APIKeyResponse {
+	return responses.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.ScopeList(),
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
@@ -2,34 +2,90 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
 	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/mailer"
 	"github.com/Alvarras/dompet-g0/internal/models"
 	"github.com/Alvarras/dompet-g0/internal/repositories"
 	"github.com/Alvarras/dompet-g0/internal/utils"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+// How long emailed links stay valid
+const (
+	passwordResetTTL = time.Hour
+	emailVerifyTTL   = 48 * time.Hour
+)
+
+// mfaPendingTTL is how long the user has to enter a second factor after the
+// password was accepted
+const mfaPendingTTL = 5 * time.Minute
+
+// UnverifiedPolicy decides what an account may do before its email address
+// is verified.
+type UnverifiedPolicy string
+
+const (
+	// UnverifiedAllow puts no restriction on unverified accounts
+	UnverifiedAllow UnverifiedPolicy = "allow"
+	// UnverifiedLimit lets unverified accounts sign in with read-only tokens
+	UnverifiedLimit UnverifiedPolicy = "limit"
+	// UnverifiedBlock refuses to sign unverified accounts in
+	UnverifiedBlock UnverifiedPolicy = "block"
+)
+
+func (p UnverifiedPolicy) Valid() bool {
+	return p == UnverifiedAllow || p == UnverifiedLimit || p == UnverifiedBlock
+}
+
 type AuthService struct {
-	userRepo    *repositories.UserRepository
-	jwtSecret   string
-	jwtDuration time.Duration
+	db               *gorm.DB
+	userRepo         *repositories.UserRepository
+	refreshTokenRepo *repositories.RefreshTokenRepository
+	revokedTokenRepo *repositories.RevokedTokenRepository
+	userTokenRepo    *repositories.UserTokenRepository
+	recoveryCodeRepo *repositories.RecoveryCodeRepository
+	loginAttemptRepo *repositories.LoginAttemptRepository
+	mailer           mailer.Mailer
+	jwtKeys          *utils.KeySet
+	jwtDuration      time.Duration
+	refreshDuration  time.Duration
+	linkBaseURL      string
+	unverifiedPolicy UnverifiedPolicy
+	loginPolicy      LoginPolicy
 }
 
-func NewAuthService(userRepo *repositories.UserRepository, jwtSecret string, jwtDuration time.Duration) *AuthService {
+func NewAuthService(db *gorm.DB, userRepo *repositories.UserRepository, refreshTokenRepo *repositories.RefreshTokenRepository, revokedTokenRepo *repositories.RevokedTokenRepository, userTokenRepo *repositories.UserTokenRepository, recoveryCodeRepo *repositories.RecoveryCodeRepository, loginAttemptRepo *repositories.LoginAttemptRepository, mailer mailer.Mailer, jwtKeys *utils.KeySet, jwtDuration, refreshDuration time.Duration, linkBaseURL string, unverifiedPolicy UnverifiedPolicy, loginPolicy LoginPolicy) *AuthService {
 	return &AuthService{
-		userRepo:    userRepo,
-		jwtSecret:   jwtSecret,
-		jwtDuration: jwtDuration,
+		db:               db,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		userTokenRepo:    userTokenRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		mailer:           mailer,
+		jwtKeys:          jwtKeys,
+		jwtDuration:      jwtDuration,
+		refreshDuration:  refreshDuration,
+		linkBaseURL:      strings.TrimRight(linkBaseURL, "/"),
+		unverifiedPolicy: unverifiedPolicy,
+		loginPolicy:      loginPolicy,
 	}
 }
 
 func (s *AuthService) Register(req *requests.RegisterRequest) (*responses.AuthResponse, error) {
 	// Check if user already exists
-	existingUser, _ := s.userRepo.FindByEmail(req.Email)
+	email := normalizeEmail(req.Email)
+	existingUser, _ := s.userRepo.FindByEmail(email)
 	if existingUser != nil {
 		return nil, errors.New("user already exists")
 	}
@@ -43,7 +99,7 @@ func (s *AuthService) Register(req *requests.RegisterRequest) (*responses.AuthRe
 	// Create user
 	user := &models.User{
 		ID:       uuid.New(),
-		Email:    req.Email,
+		Email:    email,
 		Password: string(hashedPassword),
 		Name:     req.Name,
 	}
@@ -52,46 +108,502 @@ func (s *AuthService) Register(req *requests.RegisterRequest) (*responses.AuthRe
 		return nil, err
 	}
 
-	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.Email, s.jwtSecret, s.jwtDuration)
-	if err != nil {
-		return nil, err
+	// The account exists either way; the link can be requested again
+	if err := s.sendVerification(user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
 	}
 
-	return &responses.AuthResponse{
-		Token: token,
-		User: responses.UserResponse{
-			ID:    user.ID,
-			Email: user.Email,
-			Name:  user.Name,
-		},
-	}, nil
+	if s.unverifiedPolicy == UnverifiedBlock {
+		return &responses.AuthResponse{User: toUserResponse(user)}, nil
+	}
+	return s.StartSession(user)
 }
 
-func (s *AuthService) Login(req *requests.LoginRequest) (*responses.AuthResponse, error) {
-	// Find user
-	user, err := s.userRepo.FindByEmail(req.Email)
+// dummyPasswordHash is compared against when no account matches the email.
+// It has the default cost, like every stored password.
+var dummyPasswordHash = []byte("$2a$10$foVwg2LLL8dSUmfwNpLylutjCPGcz1dnSHUjfdB96N8nvUARjOgeG")
+
+// Login checks the password. Attempts are recorded and throttled per email
+// and IP address, and too many failures in a row lock the account.
+func (s *AuthService) Login(req *requests.LoginRequest, ip, userAgent string) (*responses.AuthResponse, error) {
+	email := normalizeEmail(req.Email)
+	now := time.Now()
+	if err := s.checkLoginThrottle(email, ip, userAgent, now); err != nil {
+		return nil, err
+	}
+
+	// Find user. An unknown email still pays for a bcrypt comparison, so
+	// response times don't tell which emails have an account
+	user, err := s.userRepo.FindByEmail(email)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		return nil, s.failLogin(nil, email, ip, userAgent, models.LoginInvalidCredentials, errors.New("invalid credentials"))
+	}
+	if err := checkLocked(user, now); err != nil {
+		return nil, s.failLogin(user, email, ip, userAgent, models.LoginLocked, err)
 	}
 
 	// Check password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			locked, err := s.userRepo.WithTx(tx).FindByIDForUpdate(user.ID)
+			if err != nil {
+				return err
+			}
+			return s.registerLoginFailure(tx, locked, now)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return nil, s.failLogin(user, email, ip, userAgent, models.LoginInvalidCredentials, errors.New("invalid credentials"))
 	}
 
-	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.Email, s.jwtSecret, s.jwtDuration)
+	if s.unverifiedPolicy == UnverifiedBlock && !user.EmailVerified() {
+		return nil, s.failLogin(user, email, ip, userAgent, models.LoginEmailNotVerified, errors.New("email not verified"))
+	}
+
+	// The failure count is only reset once the second factor is in too,
+	// otherwise knowing the password would allow guessing codes forever
+	if user.TwoFactorEnabled() {
+		if err := s.recordLoginAttempt(user, email, ip, userAgent, models.LoginMFARequired); err != nil {
+			return nil, err
+		}
+		token, err := utils.GenerateMFAToken(user.ID, user.Email, s.jwtKeys, mfaPendingTTL)
+		if err != nil {
+			return nil, err
+		}
+		return &responses.AuthResponse{
+			MFARequired: true,
+			MFAToken:    token,
+			ExpiresIn:   int64(mfaPendingTTL.Seconds()),
+			User:        toUserResponse(user),
+		}, nil
+	}
+
+	if err := s.resetLoginFailures(s.db, user); err != nil {
+		return nil, err
+	}
+	if err := s.recordLoginAttempt(user, email, ip, userAgent, models.LoginSucceeded); err != nil {
+		return nil, err
+	}
+
+	// Every login starts a new refresh token family
+	return s.StartSession(user)
+}
+
+// LoginMFA finishes a login of a user with 2FA: the pending token from
+// Login is exchanged for a session once a valid code is given. The pending
+// token can only be used once. Wrong codes count like wrong passwords.
+func (s *AuthService) LoginMFA(req *requests.MFALoginRequest, ip, userAgent string) (*responses.AuthResponse, error) {
+	claims, err := utils.ValidateMFAToken(req.MFAToken, s.jwtKeys)
+	if err != nil {
+		return nil, errors.New("invalid mfa token")
+	}
+	if revoked, err := s.IsTokenRevoked(claims); err != nil || revoked {
+		return nil, errors.New("invalid mfa token")
+	}
+
+	email := normalizeEmail(claims.Email)
+	now := time.Now()
+	if err := s.checkLoginThrottle(email, ip, userAgent, now); err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+	var wrongCode bool
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		user, err = s.userRepo.WithTx(tx).FindByIDForUpdate(claims.UserID)
+		if err != nil || !user.TwoFactorEnabled() {
+			return errors.New("invalid mfa token")
+		}
+		if err := checkLocked(user, now); err != nil {
+			return err
+		}
+		if err := s.checkSecondFactor(tx, user, req.Code); err != nil {
+			if err.Error() != "invalid code" {
+				return err
+			}
+			// Commit the failure; the error is returned after the transaction
+			wrongCode = true
+			return s.registerLoginFailure(tx, user, now)
+		}
+		if err := s.resetLoginFailures(tx, user); err != nil {
+			return err
+		}
+
+		return s.revokedTokenRepo.WithTx(tx).Create(&models.RevokedToken{
+			JTI:       claims.ID,
+			UserID:    user.ID,
+			ExpiresAt: claims.ExpiresAt.Time,
+		})
+	})
+	var blocked *LoginBlockedError
+	switch {
+	case errors.As(err, &blocked):
+		return nil, s.failLogin(user, email, ip, userAgent, models.LoginLocked, err)
+	case err != nil:
+		return nil, err
+	case wrongCode:
+		return nil, s.failLogin(user, email, ip, userAgent, models.LoginInvalidCode, errors.New("invalid code"))
+	}
+
+	if err := s.recordLoginAttempt(user, email, ip, userAgent, models.LoginSucceeded); err != nil {
+		return nil, err
+	}
+	return s.StartSession(user)
+}
+
+// checkSecondFactor accepts either a TOTP code that wasn't used before or an
+// unused recovery code, and records its use. The user must be locked by tx.
+func (s *AuthService) checkSecondFactor(tx *gorm.DB, user *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep); ok {
+		user.TOTPLastStep = step
+		return s.userRepo.WithTx(tx).Update(user)
+	}
+
+	recoveryCodeRepo := s.recoveryCodeRepo.WithTx(tx)
+	recovery, err := recoveryCodeRepo.FindUnusedForUpdate(user.ID, utils.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return errors.New("invalid code")
+	}
+	now := time.Now()
+	recovery.UsedAt = &now
+	return recoveryCodeRepo.Update(recovery)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token. The
+// presented token is used up; presenting it again revokes its whole family,
+// since either the client or an attacker holds a stolen copy.
+func (s *AuthService) Refresh(req *requests.RefreshTokenRequest) (*responses.AuthResponse, error) {
+	now := time.Now()
+	reused := false
+
+	var response *responses.AuthResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		refreshTokenRepo := s.refreshTokenRepo.WithTx(tx)
+
+		token, err := refreshTokenRepo.FindByHashForUpdate(utils.HashToken(req.RefreshToken))
+		if err != nil {
+			return errors.New("invalid refresh token")
+		}
+
+		if token.ReplacedByID != nil || token.RevokedAt != nil {
+			// The revocation must be committed, so the error is returned afterwards
+			reused = true
+			return refreshTokenRepo.RevokeFamily(token.FamilyID, now)
+		}
+		if !token.Active(now) {
+			return errors.New("refresh token expired")
+		}
+
+		user, err := s.userRepo.WithTx(tx).FindByID(token.UserID)
+		if err != nil {
+			return errors.New("invalid refresh token")
+		}
+
+		var next *models.RefreshToken
+		response, next, err = s.issueTokens(refreshTokenRepo, user, token.FamilyID)
+		if err != nil {
+			return err
+		}
+
+		token.ReplacedByID = &next.ID
+		token.RevokedAt = &now
+		return refreshTokenRepo.Update(token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, errors.New("refresh token reuse detected")
+	}
+
+	return response, nil
+}
+
+// Logout denies the access token until it expires and revokes the family of
+// the refresh token, if one is given.
+func (s *AuthService) Logout(userID uuid.UUID, jti string, expiresAt time.Time, req *requests.LogoutRequest) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if req.RefreshToken != "" {
+			refreshTokenRepo := s.refreshTokenRepo.WithTx(tx)
+			token, err := refreshTokenRepo.FindByHashForUpdate(utils.HashToken(req.RefreshToken))
+			if err != nil || token.UserID != userID {
+				return errors.New("invalid refresh token")
+			}
+			if err := refreshTokenRepo.RevokeFamily(token.FamilyID, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		return s.revokedTokenRepo.WithTx(tx).Create(&models.RevokedToken{
+			JTI:       jti,
+			UserID:    userID,
+			ExpiresAt: expiresAt,
+		})
+	})
+}
+
+// RequestPasswordReset mails a single-use reset link if an account with the
+// address exists. It succeeds either way, so it cannot be used to find out
+// which addresses are registered.
+func (s *AuthService) RequestPasswordReset(req *requests.ForgotPasswordRequest) error {
+	user, err := s.userRepo.FindByEmail(normalizeEmail(req.Email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := s.createUserToken(user.ID, models.UserTokenPasswordReset, passwordResetTTL, "")
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password",
+		Body: fmt.Sprintf("Halo %s,\n\nBuka tautan berikut untuk membuat password baru:\n%s\n\nTautan berlaku selama 1 jam dan hanya bisa dipakai sekali. Abaikan email ini jika Anda tidak memintanya.\n",
+			user.Name, mailLink(s.linkBaseURL, "/reset-password", token)),
+	})
+}
+
+// ResetPassword sets a new password with a reset token and signs the user
+// out of every session.
+func (s *AuthService) ResetPassword(req *requests.ResetPasswordRequest) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		userTokenRepo := s.userTokenRepo.WithTx(tx)
+		token, err := userTokenRepo.FindByHashForUpdate(utils.HashToken(req.Token), models.UserTokenPasswordReset)
+		if err != nil || token.UsedAt != nil || !time.Now().Before(token.ExpiresAt) {
+			return errors.New("invalid or expired token")
+		}
+
+		user, err := s.userRepo.WithTx(tx).FindByID(token.UserID)
+		if err != nil {
+			return errors.New("invalid or expired token")
+		}
+
+		now := time.Now()
+		token.UsedAt = &now
+		if err := userTokenRepo.Update(token); err != nil {
+			return err
+		}
+
+		// The link proves the owner is back, so a lockout no longer applies
+		user.FailedLogins = 0
+		user.LockedUntil = nil
+		return s.revokeSessions(tx, user, string(hashedPassword))
+	})
+}
+
+// VerifyEmail marks the address of the account a verification token was
+// sent to as verified.
+func (s *AuthService) VerifyEmail(req *requests.VerifyEmailRequest) (*responses.UserResponse, error) {
+	var user *models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		userTokenRepo := s.userTokenRepo.WithTx(tx)
+		token, err := userTokenRepo.FindByHashForUpdate(utils.HashToken(req.Token), models.UserTokenEmailVerify)
+		if err != nil || token.UsedAt != nil || !time.Now().Before(token.ExpiresAt) {
+			return errors.New("invalid or expired token")
+		}
+
+		userRepo := s.userRepo.WithTx(tx)
+		user, err = userRepo.FindByID(token.UserID)
+		// The address may have changed since the link was sent
+		if err != nil || !strings.EqualFold(user.Email, token.NewEmail) {
+			return errors.New("invalid or expired token")
+		}
+
+		now := time.Now()
+		token.UsedAt = &now
+		if err := userTokenRepo.Update(token); err != nil {
+			return err
+		}
+
+		if user.EmailVerifiedAt == nil {
+			user.EmailVerifiedAt = &now
+		}
+		return userRepo.Update(user)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	response := toUserResponse(user)
+	return &response, nil
+}
+
+// ResendVerification sends a new verification link to an unverified
+// account. Like RequestPasswordReset it does not reveal whether the
+// address is registered.
+func (s *AuthService) ResendVerification(req *requests.ResendVerificationRequest) error {
+	user, err := s.userRepo.FindByEmail(normalizeEmail(req.Email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified() {
+		return nil
+	}
+	return s.sendVerification(user)
+}
+
+func (s *AuthService) sendVerification(user *models.User) error {
+	token, err := s.createUserToken(user.ID, models.UserTokenEmailVerify, emailVerifyTTL, user.Email)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email",
+		Body: fmt.Sprintf("Halo %s,\n\nBuka tautan berikut untuk memverifikasi alamat email Anda:\n%s\n\nTautan berlaku selama 48 jam.\n",
+			user.Name, mailLink(s.linkBaseURL, "/verify-email", token)),
+	})
+}
+
+// createUserToken stores a new emailed token for a user and returns it.
+// Earlier unused tokens for the same purpose stop working.
+func (s *AuthService) createUserToken(userID uuid.UUID, purpose models.UserTokenPurpose, ttl time.Duration, email string) (string, error) {
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		userTokenRepo := s.userTokenRepo.WithTx(tx)
+		if err := userTokenRepo.InvalidateByUserID(userID, purpose, time.Now()); err != nil {
+			return err
+		}
+		return userTokenRepo.Create(&models.UserToken{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: utils.HashToken(token),
+			NewEmail:  email,
+			ExpiresAt: time.Now().Add(ttl),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// revokeSessions stores a new password hash and invalidates every token
+// issued to the user so far.
+func (s *AuthService) revokeSessions(tx *gorm.DB, user *models.User, hashedPassword string) error {
+	user.Password = hashedPassword
+	user.TokenVersion++
+	if err := s.userRepo.WithTx(tx).Update(user); err != nil {
+		return err
+	}
+	return s.refreshTokenRepo.WithTx(tx).RevokeByUserID(user.ID, time.Now())
+}
+
+// IsTokenRevoked reports whether an access token was revoked by a logout,
+// by a password change or because its account no longer exists.
+func (s *AuthService) IsTokenRevoked(claims *utils.JWTClaims) (bool, error) {
+	revoked, err := s.revokedTokenRepo.IsRevoked(claims.ID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return claims.TokenVersion != user.TokenVersion, nil
+}
+
+// StartSession issues an access token and a refresh token of a new family.
+func (s *AuthService) StartSession(user *models.User) (*responses.AuthResponse, error) {
+	response, _, err := s.issueTokens(s.refreshTokenRepo, user, uuid.New())
+	return response, err
+}
+
+// PurgeExpiredTokens drops refresh tokens, denylist entries and emailed
+// tokens that have expired and can no longer be used anyway.
+func (s *AuthService) PurgeExpiredTokens(now time.Time) (int64, error) {
+	refreshed, err := s.refreshTokenRepo.DeleteExpired(now)
+	if err != nil {
+		return 0, err
+	}
+	revoked, err := s.revokedTokenRepo.DeleteExpired(now)
+	if err != nil {
+		return 0, err
+	}
+	mailed, err := s.userTokenRepo.DeleteExpired(now)
+	if err != nil {
+		return 0, err
+	}
+	attempts, err := s.loginAttemptRepo.DeleteOlderThan(now.Add(-loginAttemptRetention))
+	return refreshed + revoked + mailed + attempts, err
+}
+
+// JWKS returns the public keys other services can verify tokens with.
+func (s *AuthService) JWKS() utils.JWKS {
+	return s.jwtKeys.JWKS()
+}
+
+func (s *AuthService) issueTokens(refreshTokenRepo *repositories.RefreshTokenRepository, user *models.User, familyID uuid.UUID) (*responses.AuthResponse, *models.RefreshToken, error) {
+	// Generate token
+	token, err := utils.GenerateTokenWithClaims(utils.JWTClaims{
+		UserID:       user.ID,
+		Email:        user.Email,
+		Limited:      s.unverifiedPolicy == UnverifiedLimit && !user.EmailVerified(),
+		TokenVersion: user.TokenVersion,
+	}, s.jwtKeys, s.jwtDuration)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, err := utils.GenerateRandomToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	stored := &models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshDuration),
+	}
+	if err := refreshTokenRepo.Create(stored); err != nil {
+		return nil, nil, err
+	}
+
 	return &responses.AuthResponse{
-		Token: token,
-		User: responses.UserResponse{
-			ID:    user.ID,
-			Email: user.Email,
-			Name:  user.Name,
-		},
-	}, nil
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.jwtDuration.Seconds()),
+		User:         toUserResponse(user),
+	}, stored, nil
+}
+
+func toUserResponse(user *models.User) responses.UserResponse {
+	return responses.UserResponse{
+		ID:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		EmailVerified: user.EmailVerified(),
+	}
+}
+
+// mailLink builds a frontend link carrying an emailed token.
+func mailLink(baseURL, path, token string) string {
+	return baseURL + path + "?token=" + url.QueryEscape(token)
 }
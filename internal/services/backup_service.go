@@ -0,0 +1,695 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/backup"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Budget name conflict strategies of a restore
+const (
+	RestoreConflictRename = "rename"
+	RestoreConflictSkip   = "skip"
+	RestoreConflictMerge  = "merge"
+)
+
+type BackupService struct {
+	db           *gorm.DB
+	userRepo     *repositories.UserRepository
+	budgetRepo   *repositories.BudgetRepository
+	expenseRepo  *repositories.ExpenseRepository
+	historyRepo  *repositories.BudgetHistoryRepository
+	categoryRepo *repositories.CategoryRepository
+	tagRepo      *repositories.TagRepository
+	incomeRepo   *repositories.IncomeRepository
+	transferRepo *repositories.TransferRepository
+	ruleRepo     *repositories.ImportRuleRepository
+	alertRepo    *repositories.BudgetAlertRepository
+	workspace    WorkspaceScope
+}
+
+func NewBackupService(db *gorm.DB, userRepo *repositories.UserRepository, budgetRepo *repositories.BudgetRepository, expenseRepo *repositories.ExpenseRepository, historyRepo *repositories.BudgetHistoryRepository, categoryRepo *repositories.CategoryRepository, tagRepo *repositories.TagRepository, incomeRepo *repositories.IncomeRepository, transferRepo *repositories.TransferRepository, ruleRepo *repositories.ImportRuleRepository, alertRepo *repositories.BudgetAlertRepository) *BackupService {
+	return &BackupService{
+		db:           db,
+		userRepo:     userRepo,
+		budgetRepo:   budgetRepo,
+		expenseRepo:  expenseRepo,
+		historyRepo:  historyRepo,
+		categoryRepo: categoryRepo,
+		tagRepo:      tagRepo,
+		incomeRepo:   incomeRepo,
+		transferRepo: transferRepo,
+		ruleRepo:     ruleRepo,
+		alertRepo:    alertRepo,
+	}
+}
+
+// InWorkspace returns a copy of the service that only sees the given
+// workspace and acts with the caller's role in it.
+func (s *BackupService) InWorkspace(workspace WorkspaceScope) *BackupService {
+	return &BackupService{
+		db:           repositories.ScopeWorkspace(s.db, workspace.ID),
+		userRepo:     s.userRepo,
+		budgetRepo:   s.budgetRepo.InWorkspace(workspace.ID),
+		expenseRepo:  s.expenseRepo.InWorkspace(workspace.ID),
+		historyRepo:  s.historyRepo.InWorkspace(workspace.ID),
+		categoryRepo: s.categoryRepo.InWorkspace(workspace.ID),
+		tagRepo:      s.tagRepo.InWorkspace(workspace.ID),
+		incomeRepo:   s.incomeRepo.InWorkspace(workspace.ID),
+		transferRepo: s.transferRepo.InWorkspace(workspace.ID),
+		ruleRepo:     s.ruleRepo.InWorkspace(workspace.ID),
+		alertRepo:    s.alertRepo.InWorkspace(workspace.ID),
+		workspace:    workspace,
+	}
+}
+
+// Backup collects every row of an account, soft-deleted ones included.
+func (s *BackupService) Backup(userID uuid.UUID) (*backup.Bundle, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	bundle := &backup.Bundle{
+		Version:    backup.Version,
+		ExportedAt: time.Now(),
+		User: backup.User{
+			Name:      user.Name,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt,
+		},
+		Budgets:         []backup.Budget{},
+		BudgetHistories: []backup.BudgetHistory{},
+		Categories:      []backup.Category{},
+		Expenses:        []backup.Expense{},
+		Incomes:         []backup.Income{},
+		Transfers:       []backup.Transfer{},
+		ImportRules:     []backup.ImportRule{},
+	}
+
+	budgets, err := s.budgetRepo.FindAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	budgetIDs := make([]uuid.UUID, 0, len(budgets))
+	for _, budget := range budgets {
+		budgetIDs = append(budgetIDs, budget.ID)
+		bundle.Budgets = append(bundle.Budgets, backup.Budget{
+			ID:                budget.ID,
+			Name:              budget.Name,
+			Description:       budget.Description,
+			Amount:            budget.Amount,
+			Spent:             budget.Spent,
+			Period:            budget.Period,
+			PeriodAnchor:      budget.PeriodAnchor,
+			PeriodDays:        budget.PeriodDays,
+			PeriodStart:       budget.PeriodStart,
+			PeriodEnd:         budget.PeriodEnd,
+			RolloverPolicy:    budget.RolloverPolicy,
+			CarryOver:         budget.CarryOver,
+			ApprovalThreshold: budget.ApprovalThreshold,
+			CreatedAt:         budget.CreatedAt,
+			DeletedAt:         deletedAt(budget.DeletedAt),
+		})
+	}
+
+	histories, err := s.historyRepo.FindByBudgetIDs(budgetIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, history := range histories {
+		bundle.BudgetHistories = append(bundle.BudgetHistories, backup.BudgetHistory{
+			BudgetID:    history.BudgetID,
+			PeriodStart: history.PeriodStart,
+			PeriodEnd:   history.PeriodEnd,
+			Amount:      history.Amount,
+			CarryIn:     history.CarryIn,
+			Spent:       history.Spent,
+			CarryOut:    history.CarryOut,
+		})
+	}
+
+	categories, err := s.categoryRepo.FindAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		bundle.Categories = append(bundle.Categories, backup.Category{
+			ID:          category.ID,
+			ParentID:    category.ParentID,
+			Name:        category.Name,
+			Description: category.Description,
+			CreatedAt:   category.CreatedAt,
+			DeletedAt:   deletedAt(category.DeletedAt),
+		})
+	}
+
+	expenses, err := s.expenseRepo.FindAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, expense := range expenses {
+		bundle.Expenses = append(bundle.Expenses, backup.Expense{
+			ID:          expense.ID,
+			BudgetID:    expense.BudgetID,
+			CategoryID:  expense.CategoryID,
+			Amount:      expense.Amount,
+			Description: expense.Description,
+			Date:        expense.Date,
+			Status:      expense.Status,
+			Tags:        tagNames(expense.Tags),
+			CreatedAt:   expense.CreatedAt,
+			DeletedAt:   deletedAt(expense.DeletedAt),
+		})
+	}
+
+	incomes, err := s.incomeRepo.FindAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, income := range incomes {
+		bundle.Incomes = append(bundle.Incomes, backup.Income{
+			BudgetID:    income.BudgetID,
+			Amount:      income.Amount,
+			Description: income.Description,
+			Date:        income.Date,
+			TopUpBudget: income.TopUpBudget,
+			CreatedAt:   income.CreatedAt,
+			DeletedAt:   deletedAt(income.DeletedAt),
+		})
+	}
+
+	transfers, err := s.transferRepo.FindAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range transfers {
+		bundle.Transfers = append(bundle.Transfers, backup.Transfer{
+			FromBudgetID: transfer.FromBudgetID,
+			ToBudgetID:   transfer.ToBudgetID,
+			Amount:       transfer.Amount,
+			Description:  transfer.Description,
+			Date:         transfer.Date,
+			CreatedAt:    transfer.CreatedAt,
+			DeletedAt:    deletedAt(transfer.DeletedAt),
+		})
+	}
+
+	rules, err := s.ruleRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		bundle.ImportRules = append(bundle.ImportRules, backup.ImportRule{
+			Pattern:    rule.Pattern,
+			BudgetID:   rule.BudgetID,
+			CategoryID: rule.CategoryID,
+			Priority:   rule.Priority,
+		})
+	}
+
+	return bundle, nil
+}
+
+// Restore re-creates the contents of a bundle in the given account inside a
+// single transaction. Every row gets a new ID, so a bundle can be restored
+// into another instance or next to the data it was taken from.
+func (s *BackupService) Restore(userID uuid.UUID, bundle *backup.Bundle, conflict string) (*responses.RestoreBackupResponse, error) {
+	if bundle.Version != backup.Version {
+		return nil, errors.New("unsupported backup version")
+	}
+	if !s.workspace.mayCreateBudgets() {
+		return nil, errors.New("unauthorized")
+	}
+	switch conflict {
+	case "":
+		conflict = RestoreConflictRename
+	case RestoreConflictRename, RestoreConflictSkip, RestoreConflictMerge:
+	default:
+		return nil, errors.New("invalid restore conflict")
+	}
+	if err := validateBundle(bundle); err != nil {
+		return nil, err
+	}
+
+	result := &responses.RestoreBackupResponse{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		r := &restore{
+			service:    s,
+			tx:         tx,
+			userID:     userID,
+			result:     result,
+			budgets:    make(map[uuid.UUID]uuid.UUID),
+			categories: make(map[uuid.UUID]uuid.UUID),
+		}
+
+		steps := []func(*backup.Bundle) error{
+			r.restoreCategories,
+			func(b *backup.Bundle) error { return r.restoreBudgets(b, conflict) },
+			r.restoreExpenses,
+			r.restoreIncomesAndTransfers,
+			r.restoreImportRules,
+			r.settleBudgets,
+		}
+		for _, step := range steps {
+			if err := step(bundle); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// restore holds the ID mapping from bundle IDs to the rows created for them.
+type restore struct {
+	service    *BackupService
+	tx         *gorm.DB
+	userID     uuid.UUID
+	result     *responses.RestoreBackupResponse
+	budgets    map[uuid.UUID]uuid.UUID
+	categories map[uuid.UUID]uuid.UUID
+	// created lists the budgets inserted by this restore, merged lists
+	// existing budgets that received expenses
+	created []uuid.UUID
+	merged  []uuid.UUID
+}
+
+// restoreCategories recreates the category tree parents first. A live
+// category with the same name under the same parent is reused.
+func (r *restore) restoreCategories(bundle *backup.Bundle) error {
+	categoryRepo := r.service.categoryRepo.WithTx(r.tx)
+
+	existing, err := categoryRepo.FindByUserID(r.userID)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]uuid.UUID, len(existing))
+	for _, category := range existing {
+		byName[categoryKey(category.ParentID, category.Name)] = category.ID
+	}
+
+	pending := bundle.Categories
+	for len(pending) > 0 {
+		var next []backup.Category
+		for _, category := range pending {
+			var parentID *uuid.UUID
+			if category.ParentID != nil {
+				mapped, ok := r.categories[*category.ParentID]
+				if !ok && containsCategory(pending, *category.ParentID) {
+					next = append(next, category)
+					continue
+				}
+				if ok {
+					parentID = &mapped
+				}
+			}
+
+			key := categoryKey(parentID, category.Name)
+			if id, ok := byName[key]; ok && category.DeletedAt == nil {
+				r.categories[category.ID] = id
+				continue
+			}
+
+			created := &models.Category{
+				ID:          uuid.New(),
+				UserID:      r.userID,
+				ParentID:    parentID,
+				Name:        category.Name,
+				Description: category.Description,
+				CreatedAt:   category.CreatedAt,
+				DeletedAt:   gormDeletedAt(category.DeletedAt),
+			}
+			if err := categoryRepo.Create(created); err != nil {
+				return err
+			}
+			if category.DeletedAt == nil {
+				byName[key] = created.ID
+			}
+			r.categories[category.ID] = created.ID
+			r.result.Categories++
+		}
+
+		// A parent cycle cannot be resolved; attach the rest at the root
+		if len(next) == len(pending) {
+			for i := range next {
+				next[i].ParentID = nil
+			}
+		}
+		pending = next
+	}
+	return nil
+}
+
+func (r *restore) restoreBudgets(bundle *backup.Bundle, conflict string) error {
+	budgetRepo := r.service.budgetRepo.WithTx(r.tx)
+	historyRepo := r.service.historyRepo.WithTx(r.tx)
+
+	existing, err := budgetRepo.FindByUserID(r.userID)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]uuid.UUID, len(existing))
+	for _, budget := range existing {
+		byName[strings.ToLower(budget.Name)] = budget.ID
+	}
+
+	created := make(map[uuid.UUID]bool)
+	for _, budget := range bundle.Budgets {
+		name := budget.Name
+		if id, ok := byName[strings.ToLower(name)]; ok && budget.DeletedAt == nil {
+			switch conflict {
+			case RestoreConflictSkip:
+				r.result.SkippedBudgets++
+				continue
+			case RestoreConflictMerge:
+				r.budgets[budget.ID] = id
+				r.merged = append(r.merged, id)
+				r.result.MergedBudgets++
+				continue
+			case RestoreConflictRename:
+				name = uniqueBudgetName(byName, name)
+			}
+		}
+
+		restored := &models.Budget{
+			ID:                uuid.New(),
+			UserID:            r.userID,
+			Name:              name,
+			Amount:            budget.Amount,
+			Spent:             budget.Spent,
+			Description:       budget.Description,
+			Period:            budget.Period,
+			PeriodAnchor:      budget.PeriodAnchor,
+			PeriodDays:        budget.PeriodDays,
+			PeriodStart:       budget.PeriodStart,
+			PeriodEnd:         budget.PeriodEnd,
+			RolloverPolicy:    budget.RolloverPolicy,
+			CarryOver:         budget.CarryOver,
+			ApprovalThreshold: budget.ApprovalThreshold,
+			CreatedAt:         budget.CreatedAt,
+			DeletedAt:         gormDeletedAt(budget.DeletedAt),
+		}
+		if restored.Period == "" {
+			restored.Period = models.BudgetPeriodNone
+		}
+		if restored.RolloverPolicy == "" {
+			restored.RolloverPolicy = models.RolloverReset
+		}
+		if err := budgetRepo.Create(restored); err != nil {
+			return err
+		}
+
+		if budget.DeletedAt == nil {
+			byName[strings.ToLower(name)] = restored.ID
+			r.created = append(r.created, restored.ID)
+		}
+		r.budgets[budget.ID] = restored.ID
+		created[budget.ID] = true
+		r.result.Budgets++
+	}
+
+	// Merged budgets keep their own history
+	for _, history := range bundle.BudgetHistories {
+		if !created[history.BudgetID] {
+			continue
+		}
+		err := historyRepo.Create(&models.BudgetHistory{
+			ID:          uuid.New(),
+			BudgetID:    r.budgets[history.BudgetID],
+			PeriodStart: history.PeriodStart,
+			PeriodEnd:   history.PeriodEnd,
+			Amount:      history.Amount,
+			CarryIn:     history.CarryIn,
+			Spent:       history.Spent,
+			CarryOut:    history.CarryOut,
+		})
+		if err != nil {
+			return err
+		}
+		r.result.BudgetHistories++
+	}
+	return nil
+}
+
+func (r *restore) restoreExpenses(bundle *backup.Bundle) error {
+	expenseRepo := r.service.expenseRepo.WithTx(r.tx)
+	tagRepo := r.service.tagRepo.WithTx(r.tx)
+
+	for _, expense := range bundle.Expenses {
+		budgetID, ok := r.budgets[expense.BudgetID]
+		if !ok {
+			continue
+		}
+
+		restored := &models.Expense{
+			ID:          uuid.New(),
+			UserID:      r.userID,
+			BudgetID:    budgetID,
+			CategoryID:  r.category(expense.CategoryID),
+			Amount:      expense.Amount,
+			Description: expense.Description,
+			Date:        expense.Date,
+			Status:      expense.Status,
+			CreatedAt:   expense.CreatedAt,
+			DeletedAt:   gormDeletedAt(expense.DeletedAt),
+		}
+		if restored.Status == "" {
+			restored.Status = models.ExpenseStatusApproved
+		}
+
+		tags, err := tagRepo.FindOrCreate(r.userID, normalizeTags(expense.Tags))
+		if err != nil {
+			return err
+		}
+		restored.Tags = tags
+
+		if err := expenseRepo.Create(restored); err != nil {
+			return err
+		}
+		r.result.Expenses++
+	}
+	return nil
+}
+
+func (r *restore) restoreIncomesAndTransfers(bundle *backup.Bundle) error {
+	incomeRepo := r.service.incomeRepo.WithTx(r.tx)
+	transferRepo := r.service.transferRepo.WithTx(r.tx)
+
+	for _, income := range bundle.Incomes {
+		restored := &models.Income{
+			ID:          uuid.New(),
+			UserID:      r.userID,
+			Amount:      income.Amount,
+			Description: income.Description,
+			Date:        income.Date,
+			CreatedAt:   income.CreatedAt,
+			DeletedAt:   gormDeletedAt(income.DeletedAt),
+		}
+		if income.BudgetID != nil {
+			if budgetID, ok := r.budgets[*income.BudgetID]; ok {
+				restored.BudgetID = &budgetID
+				// A merged budget keeps its own amount, so the top-up is
+				// restored as a plain income
+				restored.TopUpBudget = income.TopUpBudget && !slices.Contains(r.merged, budgetID)
+			}
+		}
+		if err := incomeRepo.Create(restored); err != nil {
+			return err
+		}
+		r.result.Incomes++
+	}
+
+	for _, transfer := range bundle.Transfers {
+		fromID, fromOK := r.budgets[transfer.FromBudgetID]
+		toID, toOK := r.budgets[transfer.ToBudgetID]
+		if !fromOK || !toOK {
+			continue
+		}
+		// The allocation it moved never reached a merged budget
+		if slices.Contains(r.merged, fromID) || slices.Contains(r.merged, toID) {
+			continue
+		}
+
+		err := transferRepo.Create(&models.Transfer{
+			ID:           uuid.New(),
+			UserID:       r.userID,
+			FromBudgetID: fromID,
+			ToBudgetID:   toID,
+			Amount:       transfer.Amount,
+			Description:  transfer.Description,
+			Date:         transfer.Date,
+			CreatedAt:    transfer.CreatedAt,
+			DeletedAt:    gormDeletedAt(transfer.DeletedAt),
+		})
+		if err != nil {
+			return err
+		}
+		r.result.Transfers++
+	}
+	return nil
+}
+
+func (r *restore) restoreImportRules(bundle *backup.Bundle) error {
+	ruleRepo := r.service.ruleRepo.WithTx(r.tx)
+
+	for _, rule := range bundle.ImportRules {
+		budgetID, ok := r.budgets[rule.BudgetID]
+		if !ok {
+			continue
+		}
+
+		err := ruleRepo.Create(&models.ImportRule{
+			ID:         uuid.New(),
+			UserID:     r.userID,
+			Pattern:    rule.Pattern,
+			BudgetID:   budgetID,
+			CategoryID: r.category(rule.CategoryID),
+			Priority:   rule.Priority,
+		})
+		if err != nil {
+			return err
+		}
+		r.result.ImportRules++
+	}
+	return nil
+}
+
+// settleBudgets rolls restored budgets forward to the current period and
+// recomputes Spent from the expenses that now belong to them.
+func (r *restore) settleBudgets(*backup.Bundle) error {
+	ledger := &budgetLedger{
+		budgetRepo:  r.service.budgetRepo.WithTx(r.tx),
+		expenseRepo: r.service.expenseRepo.WithTx(r.tx),
+		historyRepo: r.service.historyRepo.WithTx(r.tx),
+		alertRepo:   r.service.alertRepo.WithTx(r.tx),
+	}
+
+	ids := append(append([]uuid.UUID{}, r.created...), r.merged...)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	budgets, err := ledger.lock(time.Now(), ids...)
+	if err != nil {
+		return err
+	}
+	for _, budget := range budgets {
+		if err := ledger.recomputeSpent(budget); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateBundle rejects a bundle with values the ledger cannot work with
+// before anything is written.
+func validateBundle(bundle *backup.Bundle) error {
+	for i := range bundle.Budgets {
+		if err := validateRestoredBudget(&bundle.Budgets[i]); err != nil {
+			return err
+		}
+	}
+	for _, expense := range bundle.Expenses {
+		switch expense.Status {
+		case "", models.ExpenseStatusApproved, models.ExpenseStatusPending, models.ExpenseStatusRejected:
+		default:
+			return errors.New("invalid expense status")
+		}
+	}
+	return nil
+}
+
+// validateRestoredBudget checks the schedule of a bundle budget: a periodic
+// budget needs its anchor and a complete current period.
+func validateRestoredBudget(budget *backup.Budget) error {
+	switch budget.RolloverPolicy {
+	case "", models.RolloverReset, models.RolloverCarrySurplus, models.RolloverCarryDeficit:
+	default:
+		return errors.New("invalid rollover policy")
+	}
+
+	switch budget.Period {
+	case "", models.BudgetPeriodNone:
+		if budget.PeriodStart != nil || budget.PeriodEnd != nil {
+			return errors.New("invalid budget period")
+		}
+		return nil
+	case models.BudgetPeriodWeekly, models.BudgetPeriodMonthly, models.BudgetPeriodYearly:
+	case models.BudgetPeriodCustom:
+		if budget.PeriodDays < 1 {
+			return errors.New("invalid budget period")
+		}
+	default:
+		return errors.New("invalid budget period")
+	}
+
+	if budget.PeriodAnchor == nil || budget.PeriodStart == nil || budget.PeriodEnd == nil || !budget.PeriodStart.Before(*budget.PeriodEnd) {
+		return errors.New("invalid budget period")
+	}
+	return nil
+}
+
+func (r *restore) category(id *uuid.UUID) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+	if mapped, ok := r.categories[*id]; ok {
+		return &mapped
+	}
+	return nil
+}
+
+func categoryKey(parentID *uuid.UUID, name string) string {
+	parent := ""
+	if parentID != nil {
+		parent = parentID.String()
+	}
+	return parent + "/" + strings.ToLower(name)
+}
+
+func containsCategory(categories []backup.Category, id uuid.UUID) bool {
+	for _, category := range categories {
+		if category.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueBudgetName(taken map[string]uuid.UUID, name string) string {
+	candidate := name + " (restored)"
+	for i := 2; ; i++ {
+		if _, ok := taken[strings.ToLower(candidate)]; !ok {
+			return candidate
+		}
+		candidate = name + " (restored " + strconv.Itoa(i) + ")"
+	}
+}
+
+func deletedAt(value gorm.DeletedAt) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+func gormDeletedAt(value *time.Time) gorm.DeletedAt {
+	if value == nil {
+		return gorm.DeletedAt{}
+	}
+	return gorm.DeletedAt{Time: *value, Valid: true}
+}
@@ -0,0 +1,474 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/Alvarras/dompet-g0/internal/webhook"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	// webhookBatchSize is how many due deliveries one dispatcher run sends
+	webhookBatchSize     = 100
+	defaultDeliveryLimit = 20
+)
+
+// WebhookPolicy controls how failed webhook deliveries are retried.
+type WebhookPolicy struct {
+	// MaxAttempts is how often a delivery is tried before it is given up
+	MaxAttempts int
+	// Each failed attempt doubles the wait before the next one, starting at
+	// BackoffBase and capped at BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Timeout bounds a single attempt; a delivery being sent is not picked
+	// up by another dispatcher run until it has passed
+	Timeout time.Duration
+}
+
+// backoff returns how long to wait after the given number of failed attempts.
+func (p WebhookPolicy) backoff(attempts int) time.Duration {
+	delay := p.BackoffBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if p.BackoffMax > 0 && delay >= p.BackoffMax {
+			return p.BackoffMax
+		}
+	}
+	if p.BackoffMax > 0 && delay > p.BackoffMax {
+		return p.BackoffMax
+	}
+	return delay
+}
+
+// BudgetAlertService manages the alert rules of budgets and sends their
+// webhooks. Alerts are evaluated by the budget ledger whenever Spent or the
+// allocation of a budget changes, which queues a delivery in the same
+// transaction; the dispatcher sends queued deliveries in the background.
+type BudgetAlertService struct {
+	db         *gorm.DB
+	alertRepo  *repositories.BudgetAlertRepository
+	budgetRepo *repositories.BudgetRepository
+	memberRepo *repositories.BudgetMemberRepository
+	sender     *webhook.Sender
+	policy     WebhookPolicy
+	workspace  WorkspaceScope
+}
+
+func NewBudgetAlertService(db *gorm.DB, alertRepo *repositories.BudgetAlertRepository, budgetRepo *repositories.BudgetRepository, memberRepo *repositories.BudgetMemberRepository, sender *webhook.Sender, policy WebhookPolicy) *BudgetAlertService {
+	return &BudgetAlertService{
+		db:         db,
+		alertRepo:  alertRepo,
+		budgetRepo: budgetRepo,
+		memberRepo: memberRepo,
+		sender:     sender,
+		policy:     policy,
+	}
+}
+
+// InWorkspace returns a copy of the service that only sees the given
+// workspace and acts with the caller's role in it.
+func (s *BudgetAlertService) InWorkspace(workspace WorkspaceScope) *BudgetAlertService {
+	return &BudgetAlertService{
+		db:         repositories.ScopeWorkspace(s.db, workspace.ID),
+		alertRepo:  s.alertRepo.InWorkspace(workspace.ID),
+		budgetRepo: s.budgetRepo.InWorkspace(workspace.ID),
+		memberRepo: s.memberRepo.InWorkspace(workspace.ID),
+		sender:     s.sender,
+		policy:     s.policy,
+		workspace:  workspace,
+	}
+}
+
+// CreateAlert adds an alert to a budget. A threshold the budget has already
+// reached only fires after spending drops below it and crosses it again.
+func (s *BudgetAlertService) CreateAlert(userID uuid.UUID, budgetID uuid.UUID, req *requests.CreateBudgetAlertRequest) (*responses.CreatedBudgetAlertResponse, error) {
+	budget, err := s.managedBudget(userID, budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sender.CheckURL(req.URL); err != nil {
+		return nil, errors.New("webhook url not allowed")
+	}
+
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	alert := &models.BudgetAlert{
+		ID:        uuid.New(),
+		BudgetID:  budget.ID,
+		UserID:    userID,
+		Threshold: req.Threshold,
+		URL:       req.URL,
+		Secret:    webhookSecretPrefix + token,
+		Active:    req.Active == nil || *req.Active,
+	}
+	alert.Triggered = alert.Reached(budget)
+
+	if err := s.alertRepo.Create(alert); err != nil {
+		return nil, err
+	}
+
+	return &responses.CreatedBudgetAlertResponse{
+		BudgetAlertResponse: toBudgetAlertResponse(alert),
+		Secret:              alert.Secret,
+	}, nil
+}
+
+func (s *BudgetAlertService) GetAlerts(userID uuid.UUID, budgetID uuid.UUID) (*responses.BudgetAlertListResponse, error) {
+	if _, err := s.managedBudget(userID, budgetID); err != nil {
+		return nil, err
+	}
+
+	alerts, err := s.alertRepo.FindByBudgetID(budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	alertResponses := make([]responses.BudgetAlertResponse, 0, len(alerts))
+	for i := range alerts {
+		alertResponses = append(alertResponses, toBudgetAlertResponse(&alerts[i]))
+	}
+
+	return &responses.BudgetAlertListResponse{
+		Alerts: alertResponses,
+		Total:  len(alertResponses),
+	}, nil
+}
+
+// UpdateAlert changes an alert. Like a new alert, a changed threshold that
+// is already reached does not fire until it is crossed again.
+func (s *BudgetAlertService) UpdateAlert(userID uuid.UUID, budgetID uuid.UUID, alertID uuid.UUID, req *requests.UpdateBudgetAlertRequest) (*responses.BudgetAlertResponse, error) {
+	budget, err := s.managedBudget(userID, budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	alert, err := s.findAlert(budgetID, alertID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sender.CheckURL(req.URL); err != nil {
+		return nil, errors.New("webhook url not allowed")
+	}
+
+	alert.Threshold = req.Threshold
+	alert.URL = req.URL
+	if req.Active != nil {
+		alert.Active = *req.Active
+	}
+	alert.Triggered = alert.Reached(budget)
+
+	if err := s.alertRepo.Update(alert); err != nil {
+		return nil, err
+	}
+
+	response := toBudgetAlertResponse(alert)
+	return &response, nil
+}
+
+// DeleteAlert removes an alert together with its delivery log.
+func (s *BudgetAlertService) DeleteAlert(userID uuid.UUID, budgetID uuid.UUID, alertID uuid.UUID) error {
+	if _, err := s.managedBudget(userID, budgetID); err != nil {
+		return err
+	}
+
+	if _, err := s.findAlert(budgetID, alertID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.alertRepo.WithTx(tx).Delete(alertID)
+	})
+}
+
+// TestAlert sends a test webhook with the current figures of the budget
+// right away and returns how it went. A failed test is retried like any
+// other delivery.
+func (s *BudgetAlertService) TestAlert(userID uuid.UUID, budgetID uuid.UUID, alertID uuid.UUID) (*responses.WebhookDeliveryResponse, error) {
+	budget, err := s.managedBudget(userID, budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	alert, err := s.findAlert(budgetID, alertID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	delivery, err := newWebhookDelivery(alert, budget, models.WebhookEventTest, now)
+	if err != nil {
+		return nil, err
+	}
+	// Created as claimed, so the dispatcher leaves it to this request
+	lease := now.Add(s.policy.Timeout)
+	delivery.NextAttemptAt = &lease
+	if err := s.alertRepo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	delivery.Alert = *alert
+	if err := s.deliver(delivery, now); err != nil {
+		return nil, err
+	}
+
+	response := toWebhookDeliveryResponse(delivery)
+	return &response, nil
+}
+
+// GetDeliveries returns the latest deliveries of an alert, newest first.
+func (s *BudgetAlertService) GetDeliveries(userID uuid.UUID, budgetID uuid.UUID, alertID uuid.UUID, query *requests.WebhookDeliveryListQuery) (*responses.WebhookDeliveryListResponse, error) {
+	if _, err := s.managedBudget(userID, budgetID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findAlert(budgetID, alertID); err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+
+	deliveries, err := s.alertRepo.FindDeliveriesByAlertID(alertID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryResponses := make([]responses.WebhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		deliveryResponses = append(deliveryResponses, toWebhookDeliveryResponse(&deliveries[i]))
+	}
+
+	return &responses.WebhookDeliveryListResponse{
+		Deliveries: deliveryResponses,
+		Total:      len(deliveryResponses),
+	}, nil
+}
+
+// DeliverDueWebhooks sends every queued delivery whose next attempt is due
+// at now. It is run periodically in the background and returns how many
+// deliveries succeeded.
+func (s *BudgetAlertService) DeliverDueWebhooks(now time.Time) (int, error) {
+	deliveries, err := s.alertRepo.FindDueDeliveries(now, webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		// Another dispatcher may have picked the same delivery
+		claimed, err := s.alertRepo.ClaimDelivery(delivery, now.Add(s.policy.Timeout))
+		if err != nil {
+			return delivered, err
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.deliver(delivery, now); err != nil {
+			return delivered, err
+		}
+		if delivery.Status == models.WebhookDelivered {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// deliver makes one attempt at sending a delivery and records the outcome,
+// scheduling a retry with exponential backoff when it failed.
+func (s *BudgetAlertService) deliver(delivery *models.WebhookDelivery, now time.Time) error {
+	statusCode, err := s.sender.Send(webhook.Message{
+		URL:        delivery.Alert.URL,
+		Secret:     delivery.Alert.Secret,
+		Event:      delivery.Event,
+		DeliveryID: delivery.ID.String(),
+		Body:       []byte(delivery.Payload),
+	}, now)
+
+	delivery.Attempts++
+	delivery.StatusCode = statusCode
+	delivery.Error = ""
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case delivery.Attempts >= s.policy.MaxAttempts:
+		delivery.Status = models.WebhookFailed
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = nil
+	default:
+		delivery.Error = err.Error()
+		next := now.Add(s.policy.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	}
+
+	return s.alertRepo.UpdateDelivery(delivery)
+}
+
+func (s *BudgetAlertService) managedBudget(userID uuid.UUID, budgetID uuid.UUID) (*models.Budget, error) {
+	budget, err := s.budgetRepo.FindByID(budgetID)
+	if err != nil {
+		return nil, errors.New("budget not found")
+	}
+	if err := authorizeBudget(s.memberRepo, s.workspace, budget, userID, models.BudgetActionManage); err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+func (s *BudgetAlertService) findAlert(budgetID uuid.UUID, alertID uuid.UUID) (*models.BudgetAlert, error) {
+	alert, err := s.alertRepo.FindByID(alertID)
+	if err != nil || alert.BudgetID != budgetID {
+		return nil, errors.New("alert not found")
+	}
+	return alert, nil
+}
+
+// checkAlerts queues a webhook for every active alert of a locked budget
+// whose threshold its spending has just reached, and re-arms the alerts it
+// has dropped back below.
+func (l *budgetLedger) checkAlerts(budget *models.Budget) error {
+	alerts, err := l.alertRepo.FindActiveByBudgetID(budget.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range alerts {
+		alert := &alerts[i]
+		reached := alert.Reached(budget)
+		if reached == alert.Triggered {
+			continue
+		}
+
+		if err := l.alertRepo.SetTriggered(alert.ID, reached); err != nil {
+			return err
+		}
+		if !reached {
+			continue
+		}
+
+		delivery, err := newWebhookDelivery(alert, budget, models.WebhookEventThresholdReached, now)
+		if err != nil {
+			return err
+		}
+		if err := l.alertRepo.CreateDelivery(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// webhookPayload is the JSON body of every webhook.
+type webhookPayload struct {
+	ID        uuid.UUID     `json:"id"`
+	Event     string        `json:"event"`
+	CreatedAt time.Time     `json:"created_at"`
+	AlertID   uuid.UUID     `json:"alert_id"`
+	Threshold int           `json:"threshold"`
+	Budget    webhookBudget `json:"budget"`
+}
+
+type webhookBudget struct {
+	ID          uuid.UUID    `json:"id"`
+	Name        string       `json:"name"`
+	Available   models.Money `json:"available"`
+	Spent       models.Money `json:"spent"`
+	Remaining   models.Money `json:"remaining"`
+	PercentUsed float64      `json:"percent_used"`
+	PeriodStart *time.Time   `json:"period_start"`
+	PeriodEnd   *time.Time   `json:"period_end"`
+}
+
+// newWebhookDelivery queues a webhook for an alert describing the budget as
+// it is now. WorkspaceID is set explicitly as the background jobs that
+// change budgets are not bound to a workspace.
+func newWebhookDelivery(alert *models.BudgetAlert, budget *models.Budget, event string, now time.Time) (*models.WebhookDelivery, error) {
+	id := uuid.New()
+
+	var percent float64
+	if available := budget.Available(); available > 0 {
+		percent = math.Round(float64(budget.Spent)*10000/float64(available)) / 100
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		ID:        id,
+		Event:     event,
+		CreatedAt: now,
+		AlertID:   alert.ID,
+		Threshold: alert.Threshold,
+		Budget: webhookBudget{
+			ID:          budget.ID,
+			Name:        budget.Name,
+			Available:   budget.Available(),
+			Spent:       budget.Spent,
+			Remaining:   budget.Remaining(),
+			PercentUsed: percent,
+			PeriodStart: budget.PeriodStart,
+			PeriodEnd:   budget.PeriodEnd,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookDelivery{
+		ID:            id,
+		WorkspaceID:   alert.WorkspaceID,
+		AlertID:       alert.ID,
+		BudgetID:      budget.ID,
+		Event:         event,
+		Payload:       string(payload),
+		Status:        models.WebhookPending,
+		NextAttemptAt: &now,
+	}, nil
+}
+
+func toBudgetAlertResponse(alert *models.BudgetAlert) responses.BudgetAlertResponse {
+	return responses.BudgetAlertResponse{
+		ID:        alert.ID,
+		BudgetID:  alert.BudgetID,
+		Threshold: alert.Threshold,
+		URL:       alert.URL,
+		Active:    alert.Active,
+		Triggered: alert.Triggered,
+		CreatedAt: alert.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(delivery *models.WebhookDelivery) responses.WebhookDeliveryResponse {
+	return responses.WebhookDeliveryResponse{
+		ID:            delivery.ID,
+		AlertID:       delivery.AlertID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		StatusCode:    delivery.StatusCode,
+		Error:         delivery.Error,
+		DeliveredAt:   delivery.DeliveredAt,
+		CreatedAt:     delivery.CreatedAt,
+	}
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/mailer"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// budgetInvitationTTL is how long an invitation to a shared budget stays valid
+const budgetInvitationTTL = 7 * 24 * time.Hour
+
+// BudgetMemberService shares budgets with other users. The owner invites
+// members by email and decides their role; members may leave on their own.
+type BudgetMemberService struct {
+	db             *gorm.DB
+	budgetRepo     *repositories.BudgetRepository
+	memberRepo     *repositories.BudgetMemberRepository
+	invitationRepo *repositories.BudgetInvitationRepository
+	userRepo       *repositories.UserRepository
+	mailer         mailer.Mailer
+	linkBaseURL    string
+	workspace      WorkspaceScope
+}
+
+func NewBudgetMemberService(db *gorm.DB, budgetRepo *repositories.BudgetRepository, memberRepo *repositories.BudgetMemberRepository, invitationRepo *repositories.BudgetInvitationRepository, userRepo *repositories.UserRepository, mailer mailer.Mailer, linkBaseURL string) *BudgetMemberService {
+	return &BudgetMemberService{
+		db:             db,
+		budgetRepo:     budgetRepo,
+		memberRepo:     memberRepo,
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		mailer:         mailer,
+		linkBaseURL:    strings.TrimRight(linkBaseURL, "/"),
+	}
+}
+
+// InWorkspace returns a copy of the service that only sees the given
+// workspace and acts with the caller's role in it.
+func (s *BudgetMemberService) InWorkspace(workspace WorkspaceScope) *BudgetMemberService {
+	return &BudgetMemberService{
+		db:             repositories.ScopeWorkspace(s.db, workspace.ID),
+		budgetRepo:     s.budgetRepo.InWorkspace(workspace.ID),
+		memberRepo:     s.memberRepo.InWorkspace(workspace.ID),
+		invitationRepo: s.invitationRepo.InWorkspace(workspace.ID),
+		userRepo:       s.userRepo,
+		mailer:         s.mailer,
+		linkBaseURL:    s.linkBaseURL,
+		workspace:      workspace,
+	}
+}
+
+// InviteMember emails an invitation to join a budget. Inviting the same
+// address again replaces the earlier invitation.
+func (s *BudgetMemberService) InviteMember(userID uuid.UUID, budgetID uuid.UUID, req *requests.InviteBudgetMemberRequest) (*responses.BudgetInvitationResponse, error) {
+	budget, err := s.findBudget(userID, budgetID, models.BudgetActionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	inviter, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	email := normalizeEmail(req.Email)
+	if existing, _ := s.userRepo.FindByEmail(email); existing != nil {
+		role, err := budgetRole(s.memberRepo, s.workspace, budget, existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		if role != "" {
+			return nil, errors.New("already a member")
+		}
+	}
+
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	invitation := &models.BudgetInvitation{
+		ID:        uuid.New(),
+		BudgetID:  budget.ID,
+		InvitedBy: userID,
+		Email:     email,
+		Role:      req.Role,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(budgetInvitationTTL),
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		invitationRepo := s.invitationRepo.WithTx(tx)
+		if err := invitationRepo.DeletePending(budget.ID, email); err != nil {
+			return err
+		}
+		return invitationRepo.Create(invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Undangan budget " + budget.Name,
+		Body: fmt.Sprintf("Halo,\n\n%s mengundang Anda ke budget \"%s\" sebagai %s. Buka tautan berikut untuk bergabung:\n%s\n\nTautan berlaku selama 7 hari. Masuk atau daftar dengan alamat email ini untuk menerimanya.\n",
+			inviter.Name, budget.Name, invitation.Role, mailLink(s.linkBaseURL, "/invitations/accept", token)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toBudgetInvitationResponse(invitation)
+	return &response, nil
+}
+
+// AcceptInvitation adds the user to the budget an invitation was sent for.
+// The invitation must have been sent to the user's own email address. The
+// token names the budget, so this works from any workspace.
+func (s *BudgetMemberService) AcceptInvitation(userID uuid.UUID, req *requests.AcceptInvitationRequest) (*responses.BudgetResponse, error) {
+	var budget *models.Budget
+	var member *models.BudgetMember
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		invitationRepo := s.invitationRepo.WithTx(tx)
+		invitation, err := invitationRepo.FindByHashForUpdate(utils.HashToken(req.Token))
+		now := time.Now()
+		// The budget is not loaded when it was deleted in the meantime
+		if err != nil || !invitation.Pending(now) || invitation.Budget.ID == uuid.Nil {
+			return errors.New("invalid or expired token")
+		}
+
+		user, err := s.userRepo.WithTx(tx).FindByID(userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+		if !strings.EqualFold(user.Email, invitation.Email) {
+			return errors.New("invitation was sent to another email")
+		}
+
+		budget = &invitation.Budget
+		memberRepo := s.memberRepo.WithTx(tx)
+		role, err := budgetRole(memberRepo, s.workspace, budget, userID)
+		if err != nil {
+			return err
+		}
+		if role != "" {
+			return errors.New("already a member")
+		}
+
+		member = &models.BudgetMember{
+			ID:          uuid.New(),
+			WorkspaceID: budget.WorkspaceID,
+			BudgetID:    budget.ID,
+			UserID:      userID,
+			Role:        invitation.Role,
+		}
+		if err := memberRepo.Create(member); err != nil {
+			return err
+		}
+
+		invitation.AcceptedAt = &now
+		return invitationRepo.Update(invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toBudgetResponse(budget)
+	response.Role = member.Role
+	return &response, nil
+}
+
+// GetMembers lists who a budget is shared with. Every member may see the
+// list; pending invitations are only shown to those who manage the budget.
+func (s *BudgetMemberService) GetMembers(userID uuid.UUID, budgetID uuid.UUID) (*responses.BudgetMemberListResponse, error) {
+	budget, err := s.findBudget(userID, budgetID, models.BudgetActionView)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.userRepo.FindByID(budget.UserID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.memberRepo.FindByBudgetID(budget.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &responses.BudgetMemberListResponse{
+		Members: []responses.BudgetMemberResponse{{
+			UserID:   owner.ID,
+			Name:     owner.Name,
+			Email:    owner.Email,
+			Role:     models.BudgetRoleOwner,
+			JoinedAt: budget.CreatedAt,
+		}},
+	}
+	for _, member := range members {
+		response.Members = append(response.Members, responses.BudgetMemberResponse{
+			UserID:   member.UserID,
+			Name:     member.User.Name,
+			Email:    member.User.Email,
+			Role:     member.Role,
+			JoinedAt: member.CreatedAt,
+		})
+	}
+
+	role, err := budgetRole(s.memberRepo, s.workspace, budget, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role.Allows(models.BudgetActionManage) {
+		invitations, err := s.invitationRepo.FindPendingByBudgetID(budget.ID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		for _, invitation := range invitations {
+			response.Invitations = append(response.Invitations, toBudgetInvitationResponse(&invitation))
+		}
+	}
+	return response, nil
+}
+
+func (s *BudgetMemberService) UpdateMemberRole(userID uuid.UUID, budgetID uuid.UUID, memberID uuid.UUID, req *requests.UpdateBudgetMemberRequest) (*responses.BudgetMemberResponse, error) {
+	budget, err := s.findBudget(userID, budgetID, models.BudgetActionManage)
+	if err != nil {
+		return nil, err
+	}
+	if memberID == budget.UserID {
+		return nil, errors.New("cannot change the owner")
+	}
+
+	member, err := s.memberRepo.Find(budget.ID, memberID)
+	if err != nil {
+		return nil, errors.New("member not found")
+	}
+	member.Role = req.Role
+	if err := s.memberRepo.Update(member); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(memberID)
+	if err != nil {
+		return nil, err
+	}
+	return &responses.BudgetMemberResponse{
+		UserID:   user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		Role:     member.Role,
+		JoinedAt: member.CreatedAt,
+	}, nil
+}
+
+// RemoveMember takes a member off a budget. The owner may remove anyone;
+// other members may only remove themselves. Expenses the member recorded
+// stay in the budget.
+func (s *BudgetMemberService) RemoveMember(userID uuid.UUID, budgetID uuid.UUID, memberID uuid.UUID) error {
+	action := models.BudgetActionManage
+	if memberID == userID {
+		action = models.BudgetActionView
+	}
+	budget, err := s.findBudget(userID, budgetID, action)
+	if err != nil {
+		return err
+	}
+	if memberID == budget.UserID {
+		return errors.New("cannot remove the owner")
+	}
+
+	if _, err := s.memberRepo.Find(budget.ID, memberID); err != nil {
+		return errors.New("member not found")
+	}
+	return s.memberRepo.Delete(budget.ID, memberID)
+}
+
+func (s *BudgetMemberService) RevokeInvitation(userID uuid.UUID, budgetID uuid.UUID, invitationID uuid.UUID) error {
+	budget, err := s.findBudget(userID, budgetID, models.BudgetActionManage)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := s.invitationRepo.FindByID(invitationID)
+	if err != nil || invitation.BudgetID != budget.ID || invitation.AcceptedAt != nil {
+		return errors.New("invitation not found")
+	}
+	return s.invitationRepo.Delete(invitation.ID)
+}
+
+// PurgeExpiredInvitations drops invitations that can no longer be accepted.
+func (s *BudgetMemberService) PurgeExpiredInvitations(now time.Time) (int64, error) {
+	return s.invitationRepo.DeleteExpired(now)
+}
+
+// findBudget loads a budget and checks that the user may perform the action.
+func (s *BudgetMemberService) findBudget(userID uuid.UUID, budgetID uuid.UUID, action models.BudgetAction) (*models.Budget, error) {
+	budget, err := s.budgetRepo.FindByID(budgetID)
+	if err != nil {
+		return nil, errors.New("budget not found")
+	}
+	if err := authorizeBudget(s.memberRepo, s.workspace, budget, userID, action); err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+func toBudgetInvitationResponse(invitation *models.BudgetInvitation) responses.BudgetInvitationResponse {
+	return responses.BudgetInvitationResponse{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// budgetRole returns the role of a user in a budget, or an empty role for
+// users the budget is not shared with. Members of the budget's workspace get
+// at least the role their workspace role grants.
+func budgetRole(memberRepo *repositories.BudgetMemberRepository, workspace WorkspaceScope, budget *models.Budget, userID uuid.UUID) (models.BudgetRole, error) {
+	if budget.UserID == userID {
+		return models.BudgetRoleOwner, nil
+	}
+
+	granted := workspace.budgetRole(budget)
+	if granted == models.BudgetRoleOwner {
+		return granted, nil
+	}
+
+	member, err := memberRepo.Find(budget.ID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return granted, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strongerRole(member.Role, granted), nil
+}
+
+// strongerRole returns whichever of two roles allows more.
+func strongerRole(a, b models.BudgetRole) models.BudgetRole {
+	for _, action := range []models.BudgetAction{models.BudgetActionManage, models.BudgetActionSpend, models.BudgetActionView} {
+		if a.Allows(action) != b.Allows(action) {
+			if a.Allows(action) {
+				return a
+			}
+			return b
+		}
+	}
+	return a
+}
+
+// authorizeBudget checks that the user's role in the budget allows the action.
+func authorizeBudget(memberRepo *repositories.BudgetMemberRepository, workspace WorkspaceScope, budget *models.Budget, userID uuid.UUID, action models.BudgetAction) error {
+	role, err := budgetRole(memberRepo, workspace, budget, userID)
+	if err != nil {
+		return err
+	}
+	if !role.Allows(action) {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// authorizeExpense checks that the user may change an expense in a budget.
+// Editors may change what they spent themselves; only the owner may change
+// what other members spent.
+func authorizeExpense(memberRepo *repositories.BudgetMemberRepository, workspace WorkspaceScope, budget *models.Budget, expense *models.Expense, userID uuid.UUID) error {
+	action := models.BudgetActionSpend
+	if expense.UserID != userID {
+		action = models.BudgetActionManage
+	}
+	return authorizeBudget(memberRepo, workspace, budget, userID, action)
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// budgetLedger bundles the transaction-bound repositories that move money
+// in and out of a budget, so every service applies Spent the same way.
+type budgetLedger struct {
+	budgetRepo  *repositories.BudgetRepository
+	expenseRepo *repositories.ExpenseRepository
+	historyRepo *repositories.BudgetHistoryRepository
+	alertRepo   *repositories.BudgetAlertRepository
+}
+
+// rollover closes every period of a locked budget that has ended by now,
+// records it in the history and starts the period that contains now.
+// It reports whether anything changed.
+func (l *budgetLedger) rollover(budget *models.Budget, now time.Time) (bool, error) {
+	if !budget.RolloverDue(now) {
+		return false, nil
+	}
+
+	closed, err := l.advance(budget, now)
+	if err != nil {
+		return false, err
+	}
+	for i := range closed {
+		if err := l.historyRepo.Create(&closed[i]); err != nil {
+			return false, err
+		}
+	}
+
+	if err := l.budgetRepo.Update(budget); err != nil {
+		return false, err
+	}
+	return true, l.checkAlerts(budget)
+}
+
+// advance moves a budget whose rollover is due to the period containing now
+// and returns the periods it closed, oldest first. Nothing is written, so
+// readers can show the current period without waiting for the rollover.
+func (l *budgetLedger) advance(budget *models.Budget, now time.Time) ([]models.BudgetHistory, error) {
+	var closed []models.BudgetHistory
+	start, end := *budget.PeriodStart, *budget.PeriodEnd
+	carry := budget.CarryOver
+	for !now.Before(end) {
+		spent, err := l.expenseRepo.SumByBudgetIDBetween(budget.ID, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		history := models.BudgetHistory{
+			ID:          uuid.New(),
+			BudgetID:    budget.ID,
+			PeriodStart: start,
+			PeriodEnd:   end,
+			Amount:      budget.Amount,
+			CarryIn:     carry,
+			Spent:       spent,
+		}
+		carry = carryOver(budget.RolloverPolicy, budget.Amount+carry-spent)
+		history.CarryOut = carry
+		closed = append(closed, history)
+
+		start, end = budget.NextPeriod(start)
+	}
+
+	// Expenses dated ahead of time already belong to the new period
+	spent, err := l.expenseRepo.SumByBudgetIDBetween(budget.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	budget.PeriodStart = &start
+	budget.PeriodEnd = &end
+	budget.CarryOver = carry
+	budget.Spent = spent
+	return closed, nil
+}
+
+// applySpent adds amount to the period of a locked budget that date falls in.
+// Only the current period is reflected in Budget.Spent; back-dated expenses
+// adjust the matching history entry instead.
+func (l *budgetLedger) applySpent(budget *models.Budget, date time.Time, amount models.Money) error {
+	if err := l.addSpent(budget, date, amount); err != nil {
+		return err
+	}
+	return l.checkAlerts(budget)
+}
+
+// addSpent is applySpent without evaluating alerts, for callers that move
+// several amounts at once and check alerts on the net result.
+func (l *budgetLedger) addSpent(budget *models.Budget, date time.Time, amount models.Money) error {
+	if budget.InCurrentPeriod(date) {
+		applied, err := l.budgetRepo.UpdateSpent(budget.ID, amount)
+		if err != nil {
+			return err
+		}
+		if !applied {
+			return ErrInsufficientBudget
+		}
+		budget.Spent += amount
+		return nil
+	}
+
+	if budget.PeriodStart != nil && date.Before(*budget.PeriodStart) {
+		return l.historyRepo.AddSpentAt(budget.ID, date, amount)
+	}
+
+	// Future periods are summed from the expenses when they start
+	return nil
+}
+
+// adjustAllocation changes how much a locked budget may spend. Periodic
+// budgets only change for the current period, through CarryOver, so the
+// regular Amount of future periods stays as configured.
+func (l *budgetLedger) adjustAllocation(budget *models.Budget, delta models.Money) error {
+	if budget.IsPeriodic() {
+		budget.CarryOver += delta
+	} else {
+		budget.Amount += delta
+	}
+	if err := l.budgetRepo.Update(budget); err != nil {
+		return err
+	}
+	return l.checkAlerts(budget)
+}
+
+// recomputeSpent sums the expenses of a locked budget's current period from
+// scratch, for when expenses were written without going through applySpent.
+func (l *budgetLedger) recomputeSpent(budget *models.Budget) error {
+	var spent models.Money
+	var err error
+	if budget.IsPeriodic() && budget.PeriodStart != nil && budget.PeriodEnd != nil {
+		spent, err = l.expenseRepo.SumByBudgetIDBetween(budget.ID, *budget.PeriodStart, *budget.PeriodEnd)
+	} else {
+		spent, err = l.expenseRepo.SumByBudgetID(budget.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	budget.Spent = spent
+	if err := l.budgetRepo.Update(budget); err != nil {
+		return err
+	}
+	return l.checkAlerts(budget)
+}
+
+// resetPeriod recomputes the current period of a budget from scratch, used
+// when a budget is created or its period settings change.
+func (l *budgetLedger) resetPeriod(budget *models.Budget, now time.Time) error {
+	budget.CarryOver = 0
+	if !budget.IsPeriodic() {
+		budget.PeriodStart = nil
+		budget.PeriodEnd = nil
+		spent, err := l.expenseRepo.SumByBudgetID(budget.ID)
+		budget.Spent = spent
+		return err
+	}
+
+	start, end := budget.PeriodContaining(now)
+	budget.PeriodStart = &start
+	budget.PeriodEnd = &end
+
+	spent, err := l.expenseRepo.SumByBudgetIDBetween(budget.ID, start, end)
+	budget.Spent = spent
+	return err
+}
+
+// lock locks the given budgets in ID order, so two transactions touching the
+// same pair of budgets cannot deadlock, and rolls each one over if it is due.
+func (l *budgetLedger) lock(now time.Time, ids ...uuid.UUID) (map[uuid.UUID]*models.Budget, error) {
+	sorted := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(sorted, id) {
+			sorted = append(sorted, id)
+		}
+	}
+	slices.SortFunc(sorted, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	budgets := make(map[uuid.UUID]*models.Budget, len(sorted))
+	for _, id := range sorted {
+		budget, err := l.budgetRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := l.rollover(budget, now); err != nil {
+			return nil, err
+		}
+		budgets[id] = budget
+	}
+	return budgets, nil
+}
+
+func carryOver(policy models.RolloverPolicy, remaining models.Money) models.Money {
+	switch policy {
+	case models.RolloverCarrySurplus:
+		return max(remaining, 0)
+	case models.RolloverCarryDeficit:
+		return min(remaining, 0)
+	default:
+		return 0
+	}
+}
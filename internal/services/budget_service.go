@@ -2,97 +2,290 @@ package services
 
 import (
 	"errors"
+	"slices"
+	"time"
 
 	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
 	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
 	"github.com/Alvarras/dompet-g0/internal/models"
 	"github.com/Alvarras/dompet-g0/internal/repositories"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type BudgetService struct {
-	budgetRepo *repositories.BudgetRepository
+	db          *gorm.DB
+	budgetRepo  *repositories.BudgetRepository
+	expenseRepo *repositories.ExpenseRepository
+	historyRepo *repositories.BudgetHistoryRepository
+	memberRepo  *repositories.BudgetMemberRepository
+	alertRepo   *repositories.BudgetAlertRepository
+	workspace   WorkspaceScope
+	// unscoped is the service InWorkspace was called on, used to reach
+	// budgets shared with the user from other workspaces
+	unscoped *BudgetService
 }
 
-func NewBudgetService(budgetRepo *repositories.BudgetRepository) *BudgetService {
+func NewBudgetService(db *gorm.DB, budgetRepo *repositories.BudgetRepository, expenseRepo *repositories.ExpenseRepository, historyRepo *repositories.BudgetHistoryRepository, memberRepo *repositories.BudgetMemberRepository, alertRepo *repositories.BudgetAlertRepository) *BudgetService {
 	return &BudgetService{
-		budgetRepo: budgetRepo,
+		db:          db,
+		budgetRepo:  budgetRepo,
+		expenseRepo: expenseRepo,
+		historyRepo: historyRepo,
+		memberRepo:  memberRepo,
+		alertRepo:   alertRepo,
+	}
+}
+
+// InWorkspace returns a copy of the service that only sees the given
+// workspace and acts with the caller's role in it.
+func (s *BudgetService) InWorkspace(workspace WorkspaceScope) *BudgetService {
+	unscoped := s
+	if s.unscoped != nil {
+		unscoped = s.unscoped
+	}
+	return &BudgetService{
+		db:          repositories.ScopeWorkspace(s.db, workspace.ID),
+		budgetRepo:  s.budgetRepo.InWorkspace(workspace.ID),
+		expenseRepo: s.expenseRepo.InWorkspace(workspace.ID),
+		historyRepo: s.historyRepo.InWorkspace(workspace.ID),
+		memberRepo:  s.memberRepo.InWorkspace(workspace.ID),
+		alertRepo:   s.alertRepo.InWorkspace(workspace.ID),
+		workspace:   workspace,
+		unscoped:    unscoped,
+	}
+}
+
+func (s *BudgetService) ledger(tx *gorm.DB) *budgetLedger {
+	return &budgetLedger{
+		budgetRepo:  s.budgetRepo.WithTx(tx),
+		expenseRepo: s.expenseRepo.WithTx(tx),
+		historyRepo: s.historyRepo.WithTx(tx),
+		alertRepo:   s.alertRepo.WithTx(tx),
 	}
 }
 
 func (s *BudgetService) CreateBudget(userID uuid.UUID, req *requests.CreateBudgetRequest) (*responses.BudgetResponse, error) {
+	if !s.workspace.mayCreateBudgets() {
+		return nil, errors.New("unauthorized")
+	}
+
 	budget := &models.Budget{
-		ID:          uuid.New(),
-		UserID:      userID,
-		Name:        req.Name,
-		Amount:      req.Amount,
-		Description: req.Description,
+		ID:                uuid.New(),
+		UserID:            userID,
+		Name:              req.Name,
+		Amount:            req.Amount,
+		Description:       req.Description,
+		ApprovalThreshold: req.ApprovalThreshold,
+	}
+
+	now := time.Now()
+	if err := applyPeriodSettings(budget, req.Period, req.StartDate, req.EndDate, req.RolloverPolicy, now); err != nil {
+		return nil, err
+	}
+	if budget.IsPeriodic() {
+		start, end := budget.PeriodContaining(now)
+		budget.PeriodStart = &start
+		budget.PeriodEnd = &end
 	}
 
 	if err := s.budgetRepo.Create(budget); err != nil {
 		return nil, err
 	}
 
-	return &responses.BudgetResponse{
-		ID:          budget.ID,
-		Name:        budget.Name,
-		Amount:      budget.Amount,
-		Spent:       budget.Spent,
-		Remaining:   budget.Amount - budget.Spent,
-		Description: budget.Description,
-	}, nil
+	role, err := budgetRole(s.memberRepo, s.workspace, budget, userID)
+	if err != nil {
+		return nil, err
+	}
+	response := toBudgetResponse(budget)
+	response.Role = role
+	return &response, nil
 }
 
+// GetBudgets lists the budgets of the user together with the budgets other
+// users shared with the user. Workspace members see every budget of the
+// workspace. The personal workspace also lists the budgets shared with the
+// user from other workspaces, which are used with that workspace's ID.
 func (s *BudgetService) GetBudgets(userID uuid.UUID) (*responses.BudgetListResponse, error) {
-	budgets, err := s.budgetRepo.FindByUserID(userID)
+	var budgets []models.Budget
+	var err error
+	if s.workspace.Role.BudgetRole() != "" {
+		budgets, err = s.budgetRepo.FindByWorkspaceID(s.workspace.ID)
+	} else {
+		budgets, err = s.budgetRepo.FindAccessibleByUserID(userID)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	var budgetResponses []responses.BudgetResponse
-	for _, budget := range budgets {
-		budgetResponses = append(budgetResponses, responses.BudgetResponse{
-			ID:          budget.ID,
-			Name:        budget.Name,
-			Amount:      budget.Amount,
-			Spent:       budget.Spent,
-			Remaining:   budget.Amount - budget.Spent,
-			Description: budget.Description,
+	memberships, err := s.memberRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	roles := make(map[uuid.UUID]models.BudgetRole, len(memberships))
+	for _, membership := range memberships {
+		roles[membership.BudgetID] = membership.Role
+	}
+
+	budgetIDs := make([]uuid.UUID, 0, len(budgets))
+	for i := range budgets {
+		budgetIDs = append(budgetIDs, budgets[i].ID)
+	}
+	histories, err := s.historyRepo.FindByBudgetIDs(budgetIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Show every budget in its current period even if the scheduler has not
+	// rolled it over yet; the rollover itself is left to the scheduler
+	now := time.Now()
+	ledger := s.ledger(s.db)
+	for i := range budgets {
+		if !budgets[i].RolloverDue(now) {
+			continue
+		}
+		closed, err := ledger.advance(&budgets[i], now)
+		if err != nil {
+			return nil, err
+		}
+		histories = append(histories, closed...)
+	}
+	slices.SortStableFunc(histories, func(a, b models.BudgetHistory) int {
+		return b.PeriodStart.Compare(a.PeriodStart)
+	})
+	historyByBudget := make(map[uuid.UUID][]responses.BudgetPeriodResponse)
+	for _, history := range histories {
+		historyByBudget[history.BudgetID] = append(historyByBudget[history.BudgetID], responses.BudgetPeriodResponse{
+			PeriodStart: history.PeriodStart,
+			PeriodEnd:   history.PeriodEnd,
+			Amount:      history.Amount,
+			CarryIn:     history.CarryIn,
+			Spent:       history.Spent,
+			Remaining:   history.Amount + history.CarryIn - history.Spent,
+			CarryOut:    history.CarryOut,
 		})
 	}
 
+	var budgetResponses []responses.BudgetResponse
+	for i := range budgets {
+		response := toBudgetResponse(&budgets[i])
+		response.History = historyByBudget[budgets[i].ID]
+		response.Role = models.BudgetRoleOwner
+		if budgets[i].UserID != userID {
+			response.Role = strongerRole(roles[budgets[i].ID], s.workspace.budgetRole(&budgets[i]))
+		}
+		budgetResponses = append(budgetResponses, response)
+	}
+
+	if s.unscoped != nil && s.workspace.ID == userID {
+		shared, err := s.unscoped.sharedBudgets(userID)
+		if err != nil {
+			return nil, err
+		}
+		budgetResponses = append(budgetResponses, shared...)
+	}
+
 	return &responses.BudgetListResponse{
 		Budgets: budgetResponses,
 		Total:   len(budgetResponses),
 	}, nil
 }
 
-func (s *BudgetService) UpdateBudget(userID uuid.UUID, budgetID uuid.UUID, req *requests.UpdateBudgetRequest) (*responses.BudgetResponse, error) {
-	budget, err := s.budgetRepo.FindByID(budgetID)
+// sharedBudgets lists the budgets shared with the user from workspaces other
+// than the personal one, as a guest of each workspace sees them.
+func (s *BudgetService) sharedBudgets(userID uuid.UUID) ([]responses.BudgetResponse, error) {
+	workspaceIDs, err := s.memberRepo.FindWorkspaceIDsByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	if budget.UserID != userID {
-		return nil, errors.New("unauthorized")
+	var shared []responses.BudgetResponse
+	for _, workspaceID := range workspaceIDs {
+		if workspaceID == userID {
+			continue
+		}
+		listed, err := s.InWorkspace(WorkspaceScope{ID: workspaceID, Role: models.WorkspaceRoleGuest}).GetBudgets(userID)
+		if err != nil {
+			return nil, err
+		}
+		shared = append(shared, listed.Budgets...)
 	}
+	return shared, nil
+}
 
-	budget.Name = req.Name
-	budget.Amount = req.Amount
-	budget.Description = req.Description
+func (s *BudgetService) UpdateBudget(userID uuid.UUID, budgetID uuid.UUID, req *requests.UpdateBudgetRequest) (*responses.BudgetResponse, error) {
+	var budget *models.Budget
+	var role models.BudgetRole
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		ledger := s.ledger(tx)
+		now := time.Now()
 
-	if err := s.budgetRepo.Update(budget); err != nil {
+		// Lock the budget so Save cannot overwrite a concurrent change to Spent
+		budgets, err := ledger.lock(now, budgetID)
+		if err != nil {
+			return err
+		}
+
+		budget = budgets[budgetID]
+		role, err = budgetRole(s.memberRepo.WithTx(tx), s.workspace, budget, userID)
+		if err != nil {
+			return err
+		}
+		if !role.Allows(models.BudgetActionManage) {
+			return ErrUnauthorized
+		}
+
+		budget.Name = req.Name
+		budget.Amount = req.Amount
+		budget.Description = req.Description
+		if req.ApprovalThreshold != nil {
+			budget.ApprovalThreshold = *req.ApprovalThreshold
+		}
+
+		// Fields left out of the request keep the current schedule
+		period, startDate, endDate, policy := req.Period, req.StartDate, req.EndDate, req.RolloverPolicy
+		if period == "" {
+			period = budget.Period
+		}
+		if policy == "" {
+			policy = budget.RolloverPolicy
+		}
+		if period == budget.Period && startDate == nil && budget.PeriodAnchor != nil {
+			startDate = budget.PeriodAnchor
+			if endDate == nil && budget.Period == models.BudgetPeriodCustom {
+				end := budget.PeriodAnchor.AddDate(0, 0, budget.PeriodDays)
+				endDate = &end
+			}
+		}
+
+		before := *budget
+		if err := applyPeriodSettings(budget, period, startDate, endDate, policy, now); err != nil {
+			return err
+		}
+
+		// A different schedule starts over from the period containing now
+		if periodChanged(&before, budget) {
+			if err := ledger.historyRepo.DeleteByBudgetID(budget.ID); err != nil {
+				return err
+			}
+			if err := ledger.resetPeriod(budget, now); err != nil {
+				return err
+			}
+		}
+
+		if err := ledger.budgetRepo.Update(budget); err != nil {
+			return err
+		}
+		// A smaller amount can push spending over a threshold
+		return ledger.checkAlerts(budget)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &responses.BudgetResponse{
-		ID:          budget.ID,
-		Name:        budget.Name,
-		Amount:      budget.Amount,
-		Spent:       budget.Spent,
-		Remaining:   budget.Amount - budget.Spent,
-		Description: budget.Description,
-	}, nil
+	response := toBudgetResponse(budget)
+	response.Role = role
+	return &response, nil
 }
 
 func (s *BudgetService) DeleteBudget(userID uuid.UUID, budgetID uuid.UUID) error {
@@ -101,9 +294,126 @@ func (s *BudgetService) DeleteBudget(userID uuid.UUID, budgetID uuid.UUID) error
 		return err
 	}
 
-	if budget.UserID != userID {
-		return errors.New("unauthorized")
+	if err := authorizeBudget(s.memberRepo, s.workspace, budget, userID, models.BudgetActionManage); err != nil {
+		return err
 	}
 
 	return s.budgetRepo.Delete(budgetID)
 }
+
+// RolloverDueBudgets starts a new period for every budget whose current
+// period has ended. It is run periodically in the background so figures
+// are fresh even for budgets nobody touched.
+func (s *BudgetService) RolloverDueBudgets(now time.Time) (int, error) {
+	budgets, err := s.budgetRepo.FindDueForRollover(now)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, budget := range budgets {
+		if _, err := s.rollover(budget.ID, now); err != nil {
+			return 0, err
+		}
+	}
+	return len(budgets), nil
+}
+
+func (s *BudgetService) rollover(budgetID uuid.UUID, now time.Time) (*models.Budget, error) {
+	var budget *models.Budget
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		budgets, err := s.ledger(tx).lock(now, budgetID)
+		if err != nil {
+			return err
+		}
+		budget = budgets[budgetID]
+		return nil
+	})
+	return budget, err
+}
+
+// applyPeriodSettings validates and stores the schedule of a budget. Without
+// a start date, weekly, monthly and yearly budgets follow the calendar.
+func applyPeriodSettings(budget *models.Budget, period models.BudgetPeriod, startDate, endDate *time.Time, policy models.RolloverPolicy, now time.Time) error {
+	if period == "" {
+		period = models.BudgetPeriodNone
+	}
+	if policy == "" {
+		policy = models.RolloverReset
+	}
+
+	budget.Period = period
+	budget.RolloverPolicy = policy
+	budget.PeriodDays = 0
+
+	if period == models.BudgetPeriodNone {
+		budget.PeriodAnchor = nil
+		return nil
+	}
+
+	var anchor time.Time
+	if startDate != nil {
+		anchor = *startDate
+	} else {
+		anchor = calendarPeriodStart(period, now)
+	}
+
+	if anchor.After(now) {
+		return errors.New("start date cannot be in the future")
+	}
+
+	if period == models.BudgetPeriodCustom {
+		if startDate == nil || endDate == nil || !endDate.After(*startDate) {
+			return errors.New("custom period needs a start date before its end date")
+		}
+		budget.PeriodDays = int(endDate.Sub(*startDate).Hours()/24 + 0.5)
+		if budget.PeriodDays < 1 {
+			return errors.New("custom period must be at least one day")
+		}
+	}
+
+	budget.PeriodAnchor = &anchor
+	return nil
+}
+
+func calendarPeriodStart(period models.BudgetPeriod, now time.Time) time.Time {
+	year, month, day := now.Date()
+	switch period {
+	case models.BudgetPeriodWeekly:
+		// Weeks start on Monday
+		offset := (int(now.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, now.Location())
+	case models.BudgetPeriodYearly:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, now.Location())
+	default:
+		return time.Date(year, month, 1, 0, 0, 0, 0, now.Location())
+	}
+}
+
+func periodChanged(before, after *models.Budget) bool {
+	if before.Period != after.Period || before.PeriodDays != after.PeriodDays {
+		return true
+	}
+	if (before.PeriodAnchor == nil) != (after.PeriodAnchor == nil) {
+		return true
+	}
+	return before.PeriodAnchor != nil && !before.PeriodAnchor.Equal(*after.PeriodAnchor)
+}
+
+func toBudgetResponse(budget *models.Budget) responses.BudgetResponse {
+	return responses.BudgetResponse{
+		ID:                budget.ID,
+		WorkspaceID:       budget.WorkspaceID,
+		OwnerID:           budget.UserID,
+		Name:              budget.Name,
+		Amount:            budget.Amount,
+		Spent:             budget.Spent,
+		Remaining:         budget.Remaining(),
+		Description:       budget.Description,
+		Period:            budget.Period,
+		RolloverPolicy:    budget.RolloverPolicy,
+		PeriodStart:       budget.PeriodStart,
+		PeriodEnd:         budget.PeriodEnd,
+		CarryOver:         budget.CarryOver,
+		ApprovalThreshold: budget.ApprovalThreshold,
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CategoryService struct {
+	db           *gorm.DB
+	categoryRepo *repositories.CategoryRepository
+	expenseRepo  *repositories.ExpenseRepository
+	tagRepo      *repositories.TagRepository
+}
+
+func NewCategoryService(db *gorm.DB, categoryRepo *repositories.CategoryRepository, expenseRepo *repositories.ExpenseRepository, tagRepo *repositories.TagRepository) *CategoryService {
+	return &CategoryService{
+		db:           db,
+		categoryRepo: categoryRepo,
+		expenseRepo:  expenseRepo,
+		tagRepo:      tagRepo,
+	}
+}
+
+// InWorkspace returns a copy of the service that only sees the given workspace.
+func (s *CategoryService) InWorkspace(workspace WorkspaceScope) *CategoryService {
+	return &CategoryService{
+		db:           repositories.ScopeWorkspace(s.db, workspace.ID),
+		categoryRepo: s.categoryRepo.InWorkspace(workspace.ID),
+		expenseRepo:  s.expenseRepo.InWorkspace(workspace.ID),
+		tagRepo:      s.tagRepo.InWorkspace(workspace.ID),
+	}
+}
+
+func (s *CategoryService) CreateCategory(userID uuid.UUID, req *requests.CreateCategoryRequest) (*responses.CategoryResponse, error) {
+	category := &models.Category{
+		ID:          uuid.New(),
+		UserID:      userID,
+		ParentID:    req.ParentID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+
+	if req.ParentID != nil {
+		parent, err := s.categoryRepo.FindByID(*req.ParentID)
+		if err != nil || parent.UserID != userID {
+			return nil, errors.New("parent category not found")
+		}
+	}
+
+	if err := s.categoryRepo.Create(category); err != nil {
+		return nil, err
+	}
+
+	response := toCategoryResponse(category)
+	return &response, nil
+}
+
+func (s *CategoryService) GetCategories(userID uuid.UUID) (*responses.CategoryListResponse, error) {
+	categories, err := s.categoryRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &responses.CategoryListResponse{
+		Categories: buildCategoryTree(categories, nil),
+		Total:      len(categories),
+	}, nil
+}
+
+func (s *CategoryService) UpdateCategory(userID uuid.UUID, categoryID uuid.UUID, req *requests.UpdateCategoryRequest) (*responses.CategoryResponse, error) {
+	category, err := s.categoryRepo.FindByID(categoryID)
+	if err != nil {
+		return nil, errors.New("category not found")
+	}
+
+	if category.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+
+	if req.ParentID != nil {
+		categories, err := s.categoryRepo.FindByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+
+		// A category cannot be moved below itself or one of its descendants
+		descendants := descendantCategoryIDs(categories, categoryID)
+		for _, id := range descendants {
+			if id == *req.ParentID {
+				return nil, errors.New("category cannot be its own ancestor")
+			}
+		}
+
+		parent, err := s.categoryRepo.FindByID(*req.ParentID)
+		if err != nil || parent.UserID != userID {
+			return nil, errors.New("parent category not found")
+		}
+	}
+
+	category.Name = req.Name
+	category.ParentID = req.ParentID
+	category.Description = req.Description
+
+	if err := s.categoryRepo.Update(category); err != nil {
+		return nil, err
+	}
+
+	response := toCategoryResponse(category)
+	return &response, nil
+}
+
+// DeleteCategory removes a category. Its subcategories move up to its parent
+// and its expenses become uncategorized.
+func (s *CategoryService) DeleteCategory(userID uuid.UUID, categoryID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		categoryRepo := s.categoryRepo.WithTx(tx)
+
+		category, err := categoryRepo.FindByID(categoryID)
+		if err != nil {
+			return errors.New("category not found")
+		}
+
+		if category.UserID != userID {
+			return errors.New("unauthorized")
+		}
+
+		if err := categoryRepo.ReparentChildren(categoryID, category.ParentID); err != nil {
+			return err
+		}
+		if err := s.expenseRepo.WithTx(tx).ClearCategory(categoryID); err != nil {
+			return err
+		}
+		return categoryRepo.Delete(categoryID)
+	})
+}
+
+func (s *CategoryService) GetTags(userID uuid.UUID) (*responses.TagListResponse, error) {
+	tags, err := s.tagRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := tagNames(tags)
+	return &responses.TagListResponse{
+		Tags:  names,
+		Total: len(names),
+	}, nil
+}
+
+// descendantCategoryIDs returns rootID and the IDs of every category below
+// it, or nothing when rootID is not among the given categories.
+func descendantCategoryIDs(categories []models.Category, rootID uuid.UUID) []uuid.UUID {
+	children := make(map[uuid.UUID][]uuid.UUID)
+	found := false
+	for _, category := range categories {
+		if category.ID == rootID {
+			found = true
+		}
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	ids := []uuid.UUID{rootID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+func buildCategoryTree(categories []models.Category, parentID *uuid.UUID) []responses.CategoryResponse {
+	var tree []responses.CategoryResponse
+	for i := range categories {
+		category := &categories[i]
+		if (parentID == nil) != (category.ParentID == nil) {
+			continue
+		}
+		if parentID != nil && *parentID != *category.ParentID {
+			continue
+		}
+
+		response := toCategoryResponse(category)
+		response.Children = buildCategoryTree(categories, &category.ID)
+		tree = append(tree, response)
+	}
+	return tree
+}
+
+func toCategoryResponse(category *models.Category) responses.CategoryResponse {
+	return responses.CategoryResponse{
+		ID:          category.ID,
+		ParentID:    category.ParentID,
+		Name:        category.Name,
+		Description: category.Description,
+	}
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetPendingApprovals lists the expenses waiting for approval in every
+// budget the user may manage, paged like GET /expenses but oldest first
+// unless another sort is asked for.
+func (s *ExpenseService) GetPendingApprovals(userID uuid.UUID, query *requests.ExpenseListQuery) (*responses.ExpenseListResponse, error) {
+	pendingQuery := *query
+	pendingQuery.Status = models.ExpenseStatusPending
+	if pendingQuery.Sort == "" {
+		pendingQuery.Sort = string(repositories.ExpenseSortDateAsc)
+	}
+	filter, page, err := s.listOptions(userID, &pendingQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	var budgets []models.Budget
+	if s.workspace.Role.BudgetRole() != "" {
+		budgets, err = s.budgetRepo.FindByWorkspaceID(s.workspace.ID)
+	} else {
+		budgets, err = s.budgetRepo.FindAccessibleByUserID(userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	budgetIDs := make([]uuid.UUID, 0, len(budgets))
+	for i := range budgets {
+		role, err := budgetRole(s.memberRepo, s.workspace, &budgets[i], userID)
+		if err != nil {
+			return nil, err
+		}
+		if role.Allows(models.BudgetActionManage) {
+			budgetIDs = append(budgetIDs, budgets[i].ID)
+		}
+	}
+
+	total, err := s.expenseRepo.CountPendingByBudgetIDs(budgetIDs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to know whether another page follows
+	page.Limit++
+	expenses, err := s.expenseRepo.FindPendingByBudgetIDs(budgetIDs, filter, page)
+	if err != nil {
+		return nil, err
+	}
+	expenses, nextCursor := splitPage(expenses, page)
+
+	expenseResponses := make([]responses.ExpenseResponse, 0, len(expenses))
+	for _, expense := range expenses {
+		expenseResponses = append(expenseResponses, toExpenseResponse(&expense, &expense.Budget))
+	}
+
+	return &responses.ExpenseListResponse{
+		Expenses:   expenseResponses,
+		Total:      int(total),
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// ApproveExpense lets a pending expense count against its budget. The budget
+// must still have room for it.
+func (s *ExpenseService) ApproveExpense(userID uuid.UUID, expenseID uuid.UUID, req *requests.ApproveExpenseRequest) (*responses.ExpenseApprovalResponse, error) {
+	return s.review(userID, expenseID, models.ExpenseApprovalApproved, req.Comment)
+}
+
+// RejectExpense turns down a pending expense. It stays on record but never
+// counts against the budget unless it is changed and approved later.
+func (s *ExpenseService) RejectExpense(userID uuid.UUID, expenseID uuid.UUID, req *requests.RejectExpenseRequest) (*responses.ExpenseApprovalResponse, error) {
+	return s.review(userID, expenseID, models.ExpenseApprovalRejected, req.Comment)
+}
+
+func (s *ExpenseService) review(userID uuid.UUID, expenseID uuid.UUID, action models.ExpenseApprovalAction, comment string) (*responses.ExpenseApprovalResponse, error) {
+	var approval *models.ExpenseApproval
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		ledger := s.ledger(tx)
+
+		// Lock the expense so it cannot be reviewed twice
+		expense, err := ledger.expenseRepo.FindByIDForUpdate(expenseID)
+		if err != nil {
+			return errors.New("expense not found")
+		}
+
+		budgets, err := ledger.lock(time.Now(), expense.BudgetID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBudgetNotFound
+		}
+		if err != nil {
+			return err
+		}
+		budget := budgets[expense.BudgetID]
+
+		if err := authorizeBudget(s.memberRepo.WithTx(tx), s.workspace, budget, userID, models.BudgetActionManage); err != nil {
+			return err
+		}
+		if expense.Status != models.ExpenseStatusPending {
+			return errors.New("expense is not pending")
+		}
+
+		expense.Status = models.ExpenseStatusRejected
+		if action == models.ExpenseApprovalApproved {
+			if budget.InCurrentPeriod(expense.Date) && budget.Remaining() < expense.Amount {
+				return ErrInsufficientBudget
+			}
+			if err := ledger.applySpent(budget, expense.Date, expense.Amount); err != nil {
+				return err
+			}
+			expense.Status = models.ExpenseStatusApproved
+		}
+
+		if err := ledger.expenseRepo.UpdateStatus(expense.ID, expense.Status); err != nil {
+			return err
+		}
+
+		approval = newExpenseApproval(expense, userID, action, comment)
+		return s.approvalRepo.WithTx(tx).Create(approval)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toExpenseApprovalResponse(approval)
+	return &response, nil
+}
+
+// GetApprovalHistory returns every approval step of an expense, oldest first.
+func (s *ExpenseService) GetApprovalHistory(userID uuid.UUID, expenseID uuid.UUID) (*responses.ExpenseApprovalListResponse, error) {
+	expense, err := s.expenseRepo.FindByID(expenseID)
+	if err != nil {
+		return nil, errors.New("expense not found")
+	}
+
+	if expense.UserID != userID {
+		if err := authorizeBudget(s.memberRepo, s.workspace, &expense.Budget, userID, models.BudgetActionView); err != nil {
+			return nil, err
+		}
+	}
+
+	approvals, err := s.approvalRepo.FindByExpenseID(expenseID)
+	if err != nil {
+		return nil, err
+	}
+
+	approvalResponses := make([]responses.ExpenseApprovalResponse, 0, len(approvals))
+	for i := range approvals {
+		approvalResponses = append(approvalResponses, toExpenseApprovalResponse(&approvals[i]))
+	}
+
+	return &responses.ExpenseApprovalListResponse{
+		ExpenseID: expenseID,
+		Status:    expense.Status,
+		Approvals: approvalResponses,
+		Total:     len(approvalResponses),
+	}, nil
+}
+
+func newExpenseApproval(expense *models.Expense, userID uuid.UUID, action models.ExpenseApprovalAction, comment string) *models.ExpenseApproval {
+	return &models.ExpenseApproval{
+		ID:        uuid.New(),
+		ExpenseID: expense.ID,
+		BudgetID:  expense.BudgetID,
+		UserID:    userID,
+		Action:    action,
+		Amount:    expense.Amount,
+		Comment:   comment,
+	}
+}
+
+func toExpenseApprovalResponse(approval *models.ExpenseApproval) responses.ExpenseApprovalResponse {
+	return responses.ExpenseApprovalResponse{
+		ID:        approval.ID,
+		ExpenseID: approval.ExpenseID,
+		BudgetID:  approval.BudgetID,
+		UserID:    approval.UserID,
+		UserName:  approval.User.Name,
+		Action:    approval.Action,
+		Amount:    approval.Amount,
+		Comment:   approval.Comment,
+		CreatedAt: approval.CreatedAt,
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+)
+
+const defaultExpensePageSize = 50
+
+// expenseCursor is the opaque next_cursor handed to clients. It remembers the
+// sort order so a cursor cannot be replayed against a different ordering.
+type expenseCursor struct {
+	Sort   repositories.ExpenseSort `json:"s"`
+	Date   time.Time                `json:"d"`
+	Amount models.Money             `json:"a"`
+	ID     uuid.UUID                `json:"id"`
+}
+
+// listOptions turns the query string of an expense listing into a repository
+// filter and page. Filtering by a category includes all of its subcategories.
+func (s *ExpenseService) listOptions(userID uuid.UUID, query *requests.ExpenseListQuery) (repositories.ExpenseFilter, repositories.ExpensePage, error) {
+	filter := repositories.ExpenseFilter{
+		Tag:       normalizeTag(query.Tag),
+		MinAmount: query.MinAmount,
+		MaxAmount: query.MaxAmount,
+		Search:    strings.TrimSpace(query.Search),
+		Status:    query.Status,
+	}
+	if query.From != nil {
+		filter.From = *query.From
+	}
+	if query.To != nil {
+		filter.To = *query.To
+	}
+
+	if query.CategoryID != nil {
+		categories, err := s.categoryRepo.FindByUserID(userID)
+		if err != nil {
+			return filter, repositories.ExpensePage{}, err
+		}
+		filter.CategoryIDs = descendantCategoryIDs(categories, *query.CategoryID)
+		if len(filter.CategoryIDs) == 0 {
+			return filter, repositories.ExpensePage{}, errors.New("category not found")
+		}
+	}
+
+	page := repositories.ExpensePage{
+		Sort:  repositories.ExpenseSort(query.Sort),
+		Limit: query.Limit,
+	}
+	if page.Sort == "" {
+		page.Sort = repositories.ExpenseSortDateDesc
+	}
+	if page.Limit <= 0 {
+		page.Limit = defaultExpensePageSize
+	}
+
+	if query.Cursor != "" {
+		after, err := decodeExpenseCursor(query.Cursor, page.Sort)
+		if err != nil {
+			return filter, page, err
+		}
+		page.After = after
+	}
+
+	return filter, page, nil
+}
+
+// splitPage trims the extra row fetched beyond the page size and returns the
+// cursor of the next page, or an empty cursor when this is the last page.
+func splitPage(expenses []models.Expense, page repositories.ExpensePage) ([]models.Expense, string) {
+	size := page.Limit - 1
+	if len(expenses) <= size {
+		return expenses, ""
+	}
+
+	expenses = expenses[:size]
+	last := expenses[size-1]
+	return expenses, encodeExpenseCursor(expenseCursor{
+		Sort:   page.Sort,
+		Date:   last.Date,
+		Amount: last.Amount,
+		ID:     last.ID,
+	})
+}
+
+func encodeExpenseCursor(cursor expenseCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeExpenseCursor(value string, sort repositories.ExpenseSort) (*repositories.ExpenseCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	var cursor expenseCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != sort || cursor.ID == uuid.Nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	return &repositories.ExpenseCursor{
+		Date:   cursor.Date,
+		Amount: cursor.Amount,
+		ID:     cursor.ID,
+	}, nil
+}
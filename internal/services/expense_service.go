@@ -2,6 +2,9 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
@@ -9,36 +12,89 @@ import (
 	"github.com/Alvarras/dompet-g0/internal/models"
 	"github.com/Alvarras/dompet-g0/internal/repositories"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Errors an expense is refused with. Callers that act on the refusal, like
+// the recurring expense scheduler, match them with errors.Is.
+var (
+	ErrBudgetNotFound     = errors.New("budget not found")
+	ErrCategoryNotFound   = errors.New("category not found")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrInsufficientBudget = errors.New("insufficient budget")
 )
 
 type ExpenseService struct {
-	expenseRepo *repositories.ExpenseRepository
-	budgetRepo  *repositories.BudgetRepository
+	db           *gorm.DB
+	expenseRepo  *repositories.ExpenseRepository
+	budgetRepo   *repositories.BudgetRepository
+	historyRepo  *repositories.BudgetHistoryRepository
+	categoryRepo *repositories.CategoryRepository
+	tagRepo      *repositories.TagRepository
+	memberRepo   *repositories.BudgetMemberRepository
+	approvalRepo *repositories.ExpenseApprovalRepository
+	alertRepo    *repositories.BudgetAlertRepository
+	workspace    WorkspaceScope
 }
 
-func NewExpenseService(expenseRepo *repositories.ExpenseRepository, budgetRepo *repositories.BudgetRepository) *ExpenseService {
+func NewExpenseService(db *gorm.DB, expenseRepo *repositories.ExpenseRepository, budgetRepo *repositories.BudgetRepository, historyRepo *repositories.BudgetHistoryRepository, categoryRepo *repositories.CategoryRepository, tagRepo *repositories.TagRepository, memberRepo *repositories.BudgetMemberRepository, approvalRepo *repositories.ExpenseApprovalRepository, alertRepo *repositories.BudgetAlertRepository) *ExpenseService {
 	return &ExpenseService{
-		expenseRepo: expenseRepo,
-		budgetRepo:  budgetRepo,
+		db:           db,
+		expenseRepo:  expenseRepo,
+		budgetRepo:   budgetRepo,
+		historyRepo:  historyRepo,
+		categoryRepo: categoryRepo,
+		tagRepo:      tagRepo,
+		memberRepo:   memberRepo,
+		approvalRepo: approvalRepo,
+		alertRepo:    alertRepo,
 	}
 }
 
-func (s *ExpenseService) CreateExpense(userID uuid.UUID, req *requests.CreateExpenseRequest) (*responses.CreateExpenseResponse, error) {
-	// Check if budget exists and belongs to user
-	budget, err := s.budgetRepo.FindByID(req.BudgetID)
-	if err != nil {
-		return nil, errors.New("budget not found")
+// InWorkspace returns a copy of the service that only sees the given
+// workspace and acts with the caller's role in it.
+func (s *ExpenseService) InWorkspace(workspace WorkspaceScope) *ExpenseService {
+	return &ExpenseService{
+		db:           repositories.ScopeWorkspace(s.db, workspace.ID),
+		expenseRepo:  s.expenseRepo.InWorkspace(workspace.ID),
+		budgetRepo:   s.budgetRepo.InWorkspace(workspace.ID),
+		historyRepo:  s.historyRepo.InWorkspace(workspace.ID),
+		categoryRepo: s.categoryRepo.InWorkspace(workspace.ID),
+		tagRepo:      s.tagRepo.InWorkspace(workspace.ID),
+		memberRepo:   s.memberRepo.InWorkspace(workspace.ID),
+		approvalRepo: s.approvalRepo.InWorkspace(workspace.ID),
+		alertRepo:    s.alertRepo.InWorkspace(workspace.ID),
+		workspace:    workspace,
 	}
+}
 
-	if budget.UserID != userID {
-		return nil, errors.New("unauthorized")
+// withTx returns a copy of the service whose writes join the given
+// transaction instead of starting their own.
+func (s *ExpenseService) withTx(tx *gorm.DB) *ExpenseService {
+	return &ExpenseService{
+		db:           tx.Session(&gorm.Session{DisableNestedTransaction: true}),
+		expenseRepo:  s.expenseRepo.WithTx(tx),
+		budgetRepo:   s.budgetRepo.WithTx(tx),
+		historyRepo:  s.historyRepo.WithTx(tx),
+		categoryRepo: s.categoryRepo.WithTx(tx),
+		tagRepo:      s.tagRepo.WithTx(tx),
+		memberRepo:   s.memberRepo.WithTx(tx),
+		approvalRepo: s.approvalRepo.WithTx(tx),
+		alertRepo:    s.alertRepo.WithTx(tx),
+		workspace:    s.workspace,
 	}
+}
 
-	// Check if there's enough budget
-	if budget.Amount-budget.Spent < req.Amount {
-		return nil, errors.New("insufficient budget")
+func (s *ExpenseService) ledger(tx *gorm.DB) *budgetLedger {
+	return &budgetLedger{
+		budgetRepo:  s.budgetRepo.WithTx(tx),
+		expenseRepo: s.expenseRepo.WithTx(tx),
+		historyRepo: s.historyRepo.WithTx(tx),
+		alertRepo:   s.alertRepo.WithTx(tx),
 	}
+}
 
+func (s *ExpenseService) CreateExpense(userID uuid.UUID, req *requests.CreateExpenseRequest) (*responses.CreateExpenseResponse, error) {
 	// Set current time if no date is provided
 	if req.Date.IsZero() {
 		req.Date = time.Now()
@@ -48,171 +104,474 @@ func (s *ExpenseService) CreateExpense(userID uuid.UUID, req *requests.CreateExp
 		ID:          uuid.New(),
 		UserID:      userID,
 		BudgetID:    req.BudgetID,
+		CategoryID:  req.CategoryID,
 		Amount:      req.Amount,
 		Description: req.Description,
 		Date:        req.Date,
+		Status:      models.ExpenseStatusApproved,
 	}
 
-	if err := s.expenseRepo.Create(expense); err != nil {
+	var budget *models.Budget
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		ledger := s.ledger(tx)
+
+		if err := s.classify(tx, userID, expense, req.CategoryID, req.Tags); err != nil {
+			return err
+		}
+
+		// Lock the budget row so concurrent expenses are checked one at a time
+		budgets, err := ledger.lock(time.Now(), req.BudgetID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBudgetNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		budget = budgets[req.BudgetID]
+		return s.submit(tx, ledger, budget, userID, expense)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Update budget spent amount
-	if err := s.budgetRepo.UpdateSpent(req.BudgetID, req.Amount); err != nil {
+	response := toCreateExpenseResponse(expense, budget)
+	return &response, nil
+}
+
+// CreateExpenses records a batch of expenses in a single transaction: either
+// every expense is created or none is. Errors name the failing row.
+func (s *ExpenseService) CreateExpenses(userID uuid.UUID, reqs []requests.CreateExpenseRequest) ([]responses.CreateExpenseResponse, error) {
+	now := time.Now()
+	expenses := make([]*models.Expense, len(reqs))
+	budgetIDs := make([]uuid.UUID, len(reqs))
+
+	var budgets map[uuid.UUID]*models.Budget
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		ledger := s.ledger(tx)
+
+		for i, req := range reqs {
+			if req.Date.IsZero() {
+				req.Date = now
+			}
+			expenses[i] = &models.Expense{
+				ID:          uuid.New(),
+				UserID:      userID,
+				BudgetID:    req.BudgetID,
+				CategoryID:  req.CategoryID,
+				Amount:      req.Amount,
+				Description: req.Description,
+				Date:        req.Date,
+				Status:      models.ExpenseStatusApproved,
+			}
+			budgetIDs[i] = req.BudgetID
+
+			if err := s.classify(tx, userID, expenses[i], req.CategoryID, req.Tags); err != nil {
+				return &RowError{Row: i + 1, Err: err}
+			}
+		}
+
+		// Lock every budget of the batch up front, in the usual order
+		var err error
+		budgets, err = ledger.lock(now, budgetIDs...)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBudgetNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		for i, expense := range expenses {
+			if err := s.submit(tx, ledger, budgets[expense.BudgetID], userID, expense); err != nil {
+				return &RowError{Row: i + 1, Err: err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &responses.CreateExpenseResponse{
-		ID:          expense.ID,
-		BudgetID:    expense.BudgetID,
-		BudgetName:  budget.Name,
-		Amount:      expense.Amount,
-		Description: expense.Description,
-		Date:        expense.Date,
-	}, nil
+	created := make([]responses.CreateExpenseResponse, 0, len(expenses))
+	for _, expense := range expenses {
+		created = append(created, toCreateExpenseResponse(expense, budgets[expense.BudgetID]))
+	}
+	return created, nil
+}
+
+// RowError reports which row of a batch failed, counting from 1.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
 }
 
-func (s *ExpenseService) GetExpenses(userID uuid.UUID) (*responses.ExpenseListResponse, error) {
-	expenses, err := s.expenseRepo.FindByUserID(userID)
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// submit records a new expense against a budget locked by the caller. Expenses
+// the budget wants approved are stored as pending and do not count yet.
+func (s *ExpenseService) submit(tx *gorm.DB, ledger *budgetLedger, budget *models.Budget, userID uuid.UUID, expense *models.Expense) error {
+	role, err := budgetRole(s.memberRepo.WithTx(tx), s.workspace, budget, userID)
+	if err != nil {
+		return err
+	}
+	if !role.Allows(models.BudgetActionSpend) {
+		return ErrUnauthorized
+	}
+
+	if !budget.NeedsApproval(role, expense.Amount) {
+		return recordExpense(ledger, budget, expense)
+	}
+
+	expense.Status = models.ExpenseStatusPending
+	if err := ledger.expenseRepo.Create(expense); err != nil {
+		return err
+	}
+	return s.approvalRepo.WithTx(tx).Create(newExpenseApproval(expense, userID, models.ExpenseApprovalSubmitted, ""))
+}
+
+// recordExpense stores a new expense against a budget locked by the caller,
+// who has already checked that the user may spend from it.
+func recordExpense(ledger *budgetLedger, budget *models.Budget, expense *models.Expense) error {
+	// Check if there's enough budget
+	if budget.InCurrentPeriod(expense.Date) && budget.Remaining() < expense.Amount {
+		return ErrInsufficientBudget
+	}
+
+	// Update budget spent amount first; it refuses what no longer fits
+	if err := ledger.applySpent(budget, expense.Date, expense.Amount); err != nil {
+		return err
+	}
+	return ledger.expenseRepo.Create(expense)
+}
+
+func (s *ExpenseService) GetExpenses(userID uuid.UUID, query *requests.ExpenseListQuery) (*responses.ExpenseListResponse, error) {
+	filter, page, err := s.listOptions(userID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expenses of other members in shared budgets are listed as well, and
+	// workspace members see every expense of the workspace
+	count, find := s.expenseRepo.CountVisibleToUser, s.expenseRepo.FindVisibleToUser
+	listID := userID
+	if s.workspace.Role.BudgetRole() != "" {
+		count, find = s.expenseRepo.CountByWorkspaceID, s.expenseRepo.FindByWorkspaceID
+		listID = s.workspace.ID
+	}
+
+	total, err := count(listID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to know whether another page follows
+	page.Limit++
+	expenses, err := find(listID, filter, page)
 	if err != nil {
 		return nil, err
 	}
+	expenses, nextCursor := splitPage(expenses, page)
 
+	// Budgets come preloaded in one batched query for the whole page
 	var expenseResponses []responses.ExpenseResponse
 	for _, expense := range expenses {
-		// Get budget information
-		budget, err := s.budgetRepo.FindByID(expense.BudgetID)
-		if err != nil {
-			return nil, err
-		}
-
-		expenseResponses = append(expenseResponses, responses.ExpenseResponse{
-			ID:              expense.ID,
-			BudgetID:        expense.BudgetID,
-			BudgetName:      expense.Budget.Name,
-			Amount:          expense.Amount,
-			Description:     expense.Description,
-			Date:            expense.Date,
-			BudgetRemaining: budget.Amount - budget.Spent,
-			BudgetSpent:     budget.Spent,
-			BudgetTotal:     budget.Amount,
-		})
+		expenseResponses = append(expenseResponses, toExpenseResponse(&expense, &expense.Budget))
 	}
 
 	return &responses.ExpenseListResponse{
-		Expenses: expenseResponses,
-		Total:    len(expenseResponses),
+		Expenses:   expenseResponses,
+		Total:      int(total),
+		NextCursor: nextCursor,
 	}, nil
 }
 
-func (s *ExpenseService) GetExpensesByBudget(userID uuid.UUID, budgetID uuid.UUID) (*responses.ExpenseListResponse, error) {
-	// Check if budget belongs to user
+func (s *ExpenseService) GetExpensesByBudget(userID uuid.UUID, budgetID uuid.UUID, query *requests.ExpenseListQuery) (*responses.ExpenseListResponse, error) {
+	// Check if budget is shared with user
 	budget, err := s.budgetRepo.FindByID(budgetID)
 	if err != nil {
-		return nil, errors.New("budget not found")
+		return nil, ErrBudgetNotFound
+	}
+
+	if err := authorizeBudget(s.memberRepo, s.workspace, budget, userID, models.BudgetActionView); err != nil {
+		return nil, err
+	}
+
+	filter, page, err := s.listOptions(userID, query)
+	if err != nil {
+		return nil, err
 	}
 
-	if budget.UserID != userID {
-		return nil, errors.New("unauthorized")
+	total, err := s.expenseRepo.CountByBudgetID(budgetID, filter)
+	if err != nil {
+		return nil, err
 	}
 
-	expenses, err := s.expenseRepo.FindByBudgetID(budgetID)
+	page.Limit++
+	expenses, err := s.expenseRepo.FindByBudgetID(budgetID, filter, page)
 	if err != nil {
 		return nil, err
 	}
+	expenses, nextCursor := splitPage(expenses, page)
 
 	var expenseResponses []responses.ExpenseResponse
 	for _, expense := range expenses {
-		expenseResponses = append(expenseResponses, responses.ExpenseResponse{
-			ID:              expense.ID,
-			BudgetID:        expense.BudgetID,
-			BudgetName:      expense.Budget.Name,
-			Amount:          expense.Amount,
-			Description:     expense.Description,
-			Date:            expense.Date,
-			BudgetRemaining: budget.Amount - budget.Spent,
-			BudgetSpent:     budget.Spent,
-			BudgetTotal:     budget.Amount,
-		})
+		expenseResponses = append(expenseResponses, toExpenseResponse(&expense, budget))
 	}
 
 	return &responses.ExpenseListResponse{
-		Expenses: expenseResponses,
-		Total:    len(expenseResponses),
+		Expenses:   expenseResponses,
+		Total:      int(total),
+		NextCursor: nextCursor,
 	}, nil
 }
 
 func (s *ExpenseService) DeleteExpense(userID uuid.UUID, expenseID uuid.UUID) error {
-	expense, err := s.expenseRepo.FindByID(expenseID)
-	if err != nil {
-		return err
-	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		ledger := s.ledger(tx)
 
-	if expense.UserID != userID {
-		return errors.New("unauthorized")
-	}
+		// Lock the expense so a concurrent delete cannot refund it twice
+		expense, err := ledger.expenseRepo.FindByIDForUpdate(expenseID)
+		if err != nil {
+			return err
+		}
 
-	// Update budget spent amount
-	if err := s.budgetRepo.UpdateSpent(expense.BudgetID, -expense.Amount); err != nil {
-		return err
-	}
+		budgets, err := ledger.lock(time.Now(), expense.BudgetID)
+		if err != nil {
+			return err
+		}
+
+		if err := authorizeExpense(s.memberRepo.WithTx(tx), s.workspace, budgets[expense.BudgetID], expense, userID); err != nil {
+			return err
+		}
 
-	return s.expenseRepo.Delete(expenseID)
+		// Only the delete that removed the row refunds it
+		deleted, err := ledger.expenseRepo.Delete(expenseID)
+		if err != nil {
+			return err
+		}
+		if deleted != 1 {
+			return gorm.ErrRecordNotFound
+		}
+
+		// Expenses that never counted have nothing to refund
+		if expense.Status != models.ExpenseStatusApproved {
+			return nil
+		}
+
+		// Update budget spent amount
+		return ledger.applySpent(budgets[expense.BudgetID], expense.Date, -expense.Amount)
+	})
 }
 
 func (s *ExpenseService) UpdateExpense(userID uuid.UUID, expenseID uuid.UUID, req *requests.UpdateExpenseRequest) (*responses.UpdateExpenseResponse, error) {
-	// Get existing expense
-	expense, err := s.expenseRepo.FindByID(expenseID)
+	var expense *models.Expense
+	var budget *models.Budget
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		ledger := s.ledger(tx)
+
+		// Get existing expense
+		var err error
+		expense, err = ledger.expenseRepo.FindByIDForUpdate(expenseID)
+		if err != nil {
+			return errors.New("expense not found")
+		}
+
+		// Lock the old and the new budget together so a move is all-or-nothing
+		budgets, err := ledger.lock(time.Now(), expense.BudgetID, req.BudgetID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBudgetNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Check if user may change the expense and spend from the new budget
+		memberRepo := s.memberRepo.WithTx(tx)
+		if err := authorizeExpense(memberRepo, s.workspace, budgets[expense.BudgetID], expense, userID); err != nil {
+			return err
+		}
+		budget = budgets[req.BudgetID]
+		role, err := budgetRole(memberRepo, s.workspace, budget, userID)
+		if err != nil {
+			return err
+		}
+		if !role.Allows(models.BudgetActionSpend) {
+			return ErrUnauthorized
+		}
+
+		// Categories and tags belong to the member who spent
+		if err := s.classify(tx, expense.UserID, expense, req.CategoryID, req.Tags); err != nil {
+			return err
+		}
+
+		date := expense.Date
+		if !req.Date.IsZero() {
+			date = req.Date
+		}
+
+		// Take the old amount out of its budget first, so the check below sees
+		// the delta for the same budget and the full amount for a new one
+		previous := expense.Status
+		if previous == models.ExpenseStatusApproved {
+			if err := ledger.addSpent(budgets[expense.BudgetID], expense.Date, -expense.Amount); err != nil {
+				return err
+			}
+		}
+
+		// A change that needs approval goes back to the approvers, and a
+		// pending or rejected expense counts again once it no longer does
+		expense.Status = models.ExpenseStatusApproved
+		if budget.NeedsApproval(role, req.Amount) {
+			expense.Status = models.ExpenseStatusPending
+		} else {
+			if budget.InCurrentPeriod(date) && budget.Remaining() < req.Amount {
+				return ErrInsufficientBudget
+			}
+
+			if err := ledger.addSpent(budget, date, req.Amount); err != nil {
+				return err
+			}
+		}
+
+		// Alerts only see the net change, so an edit that keeps a budget
+		// over a threshold does not fire it again
+		for _, touched := range budgets {
+			if err := ledger.checkAlerts(touched); err != nil {
+				return err
+			}
+		}
+
+		// Update expense
+		expense.BudgetID = req.BudgetID
+		expense.Amount = req.Amount
+		expense.Description = req.Description
+		expense.Date = date
+
+		if err := ledger.expenseRepo.Update(expense); err != nil {
+			return err
+		}
+		if err := ledger.expenseRepo.ReplaceTags(expense, expense.Tags); err != nil {
+			return err
+		}
+
+		approvalRepo := s.approvalRepo.WithTx(tx)
+		switch {
+		case expense.Status == models.ExpenseStatusPending:
+			return approvalRepo.Create(newExpenseApproval(expense, userID, models.ExpenseApprovalSubmitted, ""))
+		case previous != models.ExpenseStatusApproved && role.Allows(models.BudgetActionManage):
+			return approvalRepo.Create(newExpenseApproval(expense, userID, models.ExpenseApprovalApproved, ""))
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errors.New("expense not found")
+		return nil, err
 	}
 
-	// Check if expense belongs to user
-	if expense.UserID != userID {
-		return nil, errors.New("unauthorized")
-	}
+	return &responses.UpdateExpenseResponse{
+		ID:           expense.ID,
+		BudgetID:     expense.BudgetID,
+		BudgetName:   budget.Name,
+		SpentBy:      expense.UserID,
+		CategoryID:   expense.CategoryID,
+		CategoryName: categoryName(expense.Category),
+		Amount:       expense.Amount,
+		Description:  expense.Description,
+		Date:         expense.Date,
+		Status:       expense.Status,
+		Tags:         tagNames(expense.Tags),
+	}, nil
+}
 
-	// Check if budget exists and belongs to user
-	budget, err := s.budgetRepo.FindByID(req.BudgetID)
-	if err != nil {
-		return nil, errors.New("budget not found")
+// classify checks that the category belongs to the user who spent and resolves the
+// tag names into tag rows, creating new tags on first use.
+func (s *ExpenseService) classify(tx *gorm.DB, userID uuid.UUID, expense *models.Expense, categoryID *uuid.UUID, tags []string) error {
+	expense.CategoryID = categoryID
+	expense.Category = nil
+	if categoryID != nil {
+		category, err := s.categoryRepo.WithTx(tx).FindByID(*categoryID)
+		if err != nil || category.UserID != userID {
+			return ErrCategoryNotFound
+		}
+		expense.Category = category
 	}
 
-	if budget.UserID != userID {
-		return nil, errors.New("unauthorized")
+	resolved, err := s.tagRepo.WithTx(tx).FindOrCreate(userID, normalizeTags(tags))
+	if err != nil {
+		return err
 	}
+	expense.Tags = resolved
+	return nil
+}
 
-	// Calculate budget adjustment
-	budgetAdjustment := req.Amount - expense.Amount
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
 
-	// Check if there's enough budget for the adjustment
-	if budget.Amount-budget.Spent < budgetAdjustment {
-		return nil, errors.New("insufficient budget")
+// normalizeTags lowercases and trims tag names and drops duplicates.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
 	}
+	return normalized
+}
 
-	// Update expense
-	expense.BudgetID = req.BudgetID
-	expense.Amount = req.Amount
-	expense.Description = req.Description
-	if !req.Date.IsZero() {
-		expense.Date = req.Date
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
 	}
+	return names
+}
 
-	if err := s.expenseRepo.Update(expense); err != nil {
-		return nil, err
+func toCreateExpenseResponse(expense *models.Expense, budget *models.Budget) responses.CreateExpenseResponse {
+	return responses.CreateExpenseResponse{
+		ID:           expense.ID,
+		BudgetID:     expense.BudgetID,
+		BudgetName:   budget.Name,
+		SpentBy:      expense.UserID,
+		CategoryID:   expense.CategoryID,
+		CategoryName: categoryName(expense.Category),
+		Amount:       expense.Amount,
+		Description:  expense.Description,
+		Date:         expense.Date,
+		Status:       expense.Status,
+		Tags:         tagNames(expense.Tags),
 	}
+}
 
-	// Update budget spent amount
-	if err := s.budgetRepo.UpdateSpent(req.BudgetID, budgetAdjustment); err != nil {
-		return nil, err
+func toExpenseResponse(expense *models.Expense, budget *models.Budget) responses.ExpenseResponse {
+	return responses.ExpenseResponse{
+		ID:              expense.ID,
+		BudgetID:        expense.BudgetID,
+		BudgetName:      budget.Name,
+		SpentBy:         expense.UserID,
+		SpentByName:     expense.User.Name,
+		CategoryID:      expense.CategoryID,
+		CategoryName:    categoryName(expense.Category),
+		Amount:          expense.Amount,
+		Description:     expense.Description,
+		Date:            expense.Date,
+		Status:          expense.Status,
+		BudgetRemaining: budget.Remaining(),
+		BudgetSpent:     budget.Spent,
+		BudgetTotal:     budget.Amount,
+		Tags:            tagNames(expense.Tags),
 	}
+}
 
-	return &responses.UpdateExpenseResponse{
-		ID:          expense.ID,
-		BudgetID:    expense.BudgetID,
-		BudgetName:  budget.Name,
-		Amount:      expense.Amount,
-		Description: expense.Description,
-		Date:        expense.Date,
-	}, nil
+func categoryName(category *models.Category) string {
+	if category == nil {
+		return ""
+	}
+	return category.Name
 }
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/exporters"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// exportBatchSize is how many expenses are loaded and written at a time
+const exportBatchSize = 500
+
+type ExportService struct {
+	budgetRepo  *repositories.BudgetRepository
+	expenseRepo *repositories.ExpenseRepository
+}
+
+func NewExportService(budgetRepo *repositories.BudgetRepository, expenseRepo *repositories.ExpenseRepository) *ExportService {
+	return &ExportService{
+		budgetRepo:  budgetRepo,
+		expenseRepo: expenseRepo,
+	}
+}
+
+// InWorkspace returns a copy of the service that only sees the given workspace.
+func (s *ExportService) InWorkspace(workspace WorkspaceScope) *ExportService {
+	return &ExportService{
+		budgetRepo:  s.budgetRepo.InWorkspace(workspace.ID),
+		expenseRepo: s.expenseRepo.InWorkspace(workspace.ID),
+	}
+}
+
+// Export writes all budgets of a user and their expenses dated in [from, to)
+// to w, oldest expense first. Expenses are paged through with a keyset
+// cursor so memory use does not grow with the number of expenses.
+func (s *ExportService) Export(userID uuid.UUID, format exporters.Format, from, to time.Time, w io.Writer) error {
+	writer, err := exporters.NewWriter(format, w, time.Now())
+	if err != nil {
+		return err
+	}
+
+	budgets, err := s.budgetRepo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteBudgets(budgets); err != nil {
+		return err
+	}
+
+	// Expenses waiting for approval or rejected were never spent
+	filter := repositories.ExpenseFilter{From: from, To: to, Status: models.ExpenseStatusApproved}
+	page := repositories.ExpensePage{Sort: repositories.ExpenseSortDateAsc, Limit: exportBatchSize}
+	for {
+		expenses, err := s.expenseRepo.FindByUserID(userID, filter, page)
+		if err != nil {
+			return err
+		}
+		if err := writer.WriteExpenses(expenses); err != nil {
+			return err
+		}
+		flush(w)
+
+		if len(expenses) < exportBatchSize {
+			break
+		}
+		last := expenses[len(expenses)-1]
+		page.After = &repositories.ExpenseCursor{Date: last.Date, Amount: last.Amount, ID: last.ID}
+	}
+
+	return writer.Close()
+}
+
+func flush(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
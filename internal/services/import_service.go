@@ -0,0 +1,343 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/importers"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// ImportService turns bank statements into expenses. Previewing is read-only;
+// committing goes through ExpenseService so budgets stay consistent.
+type ImportService struct {
+	expenseService *ExpenseService
+	expenseRepo    *repositories.ExpenseRepository
+	budgetRepo     *repositories.BudgetRepository
+	categoryRepo   *repositories.CategoryRepository
+	ruleRepo       *repositories.ImportRuleRepository
+	memberRepo     *repositories.BudgetMemberRepository
+	workspace      WorkspaceScope
+}
+
+func NewImportService(expenseService *ExpenseService, expenseRepo *repositories.ExpenseRepository, budgetRepo *repositories.BudgetRepository, categoryRepo *repositories.CategoryRepository, ruleRepo *repositories.ImportRuleRepository, memberRepo *repositories.BudgetMemberRepository) *ImportService {
+	return &ImportService{
+		expenseService: expenseService,
+		expenseRepo:    expenseRepo,
+		budgetRepo:     budgetRepo,
+		categoryRepo:   categoryRepo,
+		ruleRepo:       ruleRepo,
+		memberRepo:     memberRepo,
+	}
+}
+
+// InWorkspace returns a copy of the service that only sees the given
+// workspace and acts with the caller's role in it.
+func (s *ImportService) InWorkspace(workspace WorkspaceScope) *ImportService {
+	return &ImportService{
+		expenseService: s.expenseService.InWorkspace(workspace),
+		expenseRepo:    s.expenseRepo.InWorkspace(workspace.ID),
+		budgetRepo:     s.budgetRepo.InWorkspace(workspace.ID),
+		categoryRepo:   s.categoryRepo.InWorkspace(workspace.ID),
+		ruleRepo:       s.ruleRepo.InWorkspace(workspace.ID),
+		memberRepo:     s.memberRepo.InWorkspace(workspace.ID),
+		workspace:      workspace,
+	}
+}
+
+// duplicateKey identifies an expense by day, amount and description.
+type duplicateKey struct {
+	day         string
+	amount      models.Money
+	description string
+}
+
+func newDuplicateKey(date time.Time, amount models.Money, description string) duplicateKey {
+	return duplicateKey{
+		day:         date.In(time.Local).Format(time.DateOnly),
+		amount:      amount,
+		description: strings.Join(strings.Fields(strings.ToLower(description)), " "),
+	}
+}
+
+func (s *ImportService) PreviewImport(userID uuid.UUID, file io.Reader, filename string, req *requests.ImportPreviewRequest) (*responses.ImportPreviewResponse, error) {
+	if req.DefaultBudgetID != nil {
+		if err := s.checkBudget(userID, *req.DefaultBudgetID); err != nil {
+			return nil, err
+		}
+	}
+
+	format := importers.Format(req.Format)
+	if format == "" {
+		format = importers.DetectFormat(filename)
+	}
+
+	opts := importers.Options{
+		DateColumn:        req.DateColumn,
+		AmountColumn:      req.AmountColumn,
+		DescriptionColumn: req.DescriptionColumn,
+		DateFormat:        req.DateFormat,
+		DecimalComma:      req.DecimalComma,
+		NegativeExpenses:  req.NegativeExpenses,
+	}
+	if req.Delimiter != "" {
+		opts.Delimiter = []rune(req.Delimiter)[0]
+	}
+
+	rows, err := importers.Parse(format, file, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.ruleRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]time.Time, 0, len(rows))
+	for _, row := range rows {
+		if row.Error == "" {
+			dates = append(dates, row.Date)
+		}
+	}
+	existing, err := s.existingExpenses(userID, dates)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &responses.ImportPreviewResponse{
+		Format: string(format),
+		Rows:   []responses.ImportRowResponse{},
+	}
+	for _, row := range rows {
+		response := responses.ImportRowResponse{
+			Line:        row.Line,
+			Date:        row.Date,
+			Amount:      row.Amount,
+			Description: row.Description,
+			BudgetID:    req.DefaultBudgetID,
+			Error:       row.Error,
+		}
+
+		if row.Error != "" {
+			preview.Invalid++
+		} else {
+			response.Duplicate = existing.take(newDuplicateKey(row.Date, row.Amount, row.Description))
+			if response.Duplicate {
+				preview.Duplicates++
+			}
+		}
+
+		if rule := matchImportRule(rules, row.Description); rule != nil {
+			response.BudgetID = &rule.BudgetID
+			response.CategoryID = rule.CategoryID
+			response.RuleID = &rule.ID
+		}
+
+		preview.Rows = append(preview.Rows, response)
+	}
+	preview.Total = len(preview.Rows)
+
+	return preview, nil
+}
+
+// CommitImport creates the expenses of the given rows atomically.
+func (s *ImportService) CommitImport(userID uuid.UUID, req *requests.ImportCommitRequest) (*responses.ImportCommitResponse, error) {
+	var existing duplicateCounter
+	if req.SkipDuplicates {
+		dates := make([]time.Time, 0, len(req.Rows))
+		for _, row := range req.Rows {
+			dates = append(dates, row.Date)
+		}
+		var err error
+		if existing, err = s.existingExpenses(userID, dates); err != nil {
+			return nil, err
+		}
+	}
+
+	expenses := make([]requests.CreateExpenseRequest, 0, len(req.Rows))
+	rowNumbers := make([]int, 0, len(req.Rows))
+	for i, row := range req.Rows {
+		if req.SkipDuplicates && existing.take(newDuplicateKey(row.Date, row.Amount, row.Description)) {
+			continue
+		}
+		rowNumbers = append(rowNumbers, i+1)
+		expenses = append(expenses, requests.CreateExpenseRequest{
+			BudgetID:    row.BudgetID,
+			CategoryID:  row.CategoryID,
+			Amount:      row.Amount,
+			Description: row.Description,
+			Date:        row.Date,
+			Tags:        row.Tags,
+		})
+	}
+
+	result := &responses.ImportCommitResponse{
+		Skipped:  len(req.Rows) - len(expenses),
+		Expenses: []responses.CreateExpenseResponse{},
+	}
+	if len(expenses) == 0 {
+		return result, nil
+	}
+
+	created, err := s.expenseService.CreateExpenses(userID, expenses)
+	if err != nil {
+		// Report the row as numbered in the request, not after skipping
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			rowErr.Row = rowNumbers[rowErr.Row-1]
+		}
+		return nil, err
+	}
+	result.Imported = len(created)
+	result.Expenses = created
+	return result, nil
+}
+
+func (s *ImportService) CreateRule(userID uuid.UUID, req *requests.CreateImportRuleRequest) (*responses.ImportRuleResponse, error) {
+	if err := s.checkBudget(userID, req.BudgetID); err != nil {
+		return nil, err
+	}
+
+	if req.CategoryID != nil {
+		category, err := s.categoryRepo.FindByID(*req.CategoryID)
+		if err != nil || category.UserID != userID {
+			return nil, errors.New("category not found")
+		}
+	}
+
+	rule := &models.ImportRule{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Pattern:    strings.TrimSpace(req.Pattern),
+		BudgetID:   req.BudgetID,
+		CategoryID: req.CategoryID,
+		Priority:   req.Priority,
+	}
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+
+	response := toImportRuleResponse(rule)
+	return &response, nil
+}
+
+func (s *ImportService) GetRules(userID uuid.UUID) (*responses.ImportRuleListResponse, error) {
+	rules, err := s.ruleRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleResponses := []responses.ImportRuleResponse{}
+	for i := range rules {
+		ruleResponses = append(ruleResponses, toImportRuleResponse(&rules[i]))
+	}
+
+	return &responses.ImportRuleListResponse{
+		Rules: ruleResponses,
+		Total: len(ruleResponses),
+	}, nil
+}
+
+func (s *ImportService) DeleteRule(userID uuid.UUID, ruleID uuid.UUID) error {
+	rule, err := s.ruleRepo.FindByID(ruleID)
+	if err != nil {
+		return errors.New("rule not found")
+	}
+
+	if rule.UserID != userID {
+		return errors.New("unauthorized")
+	}
+
+	return s.ruleRepo.Delete(ruleID)
+}
+
+// duplicateCounter counts existing expenses per key, so two identical
+// statement rows only match as many expenses as there really are.
+type duplicateCounter map[duplicateKey]int
+
+func (c duplicateCounter) take(key duplicateKey) bool {
+	if c[key] == 0 {
+		return false
+	}
+	c[key]--
+	return true
+}
+
+func (s *ImportService) existingExpenses(userID uuid.UUID, dates []time.Time) (duplicateCounter, error) {
+	counter := duplicateCounter{}
+	if len(dates) == 0 {
+		return counter, nil
+	}
+
+	from, to := dates[0], dates[0]
+	for _, date := range dates {
+		if date.Before(from) {
+			from = date
+		}
+		if date.After(to) {
+			to = date
+		}
+	}
+	from = calendarDay(from)
+	to = calendarDay(to).AddDate(0, 0, 1)
+
+	expenses, err := s.expenseRepo.FindByUserID(userID, repositories.ExpenseFilter{From: from, To: to}, repositories.ExpensePage{})
+	if err != nil {
+		return nil, err
+	}
+	for _, expense := range expenses {
+		counter[newDuplicateKey(expense.Date, expense.Amount, expense.Description)]++
+	}
+	return counter, nil
+}
+
+func calendarDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// matchImportRule returns the first rule whose pattern occurs in the
+// description. Rules are already sorted by priority.
+func matchImportRule(rules []models.ImportRule, description string) *models.ImportRule {
+	description = strings.ToLower(description)
+	for i := range rules {
+		if strings.Contains(description, strings.ToLower(rules[i].Pattern)) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func toImportRuleResponse(rule *models.ImportRule) responses.ImportRuleResponse {
+	return responses.ImportRuleResponse{
+		ID:         rule.ID,
+		Pattern:    rule.Pattern,
+		BudgetID:   rule.BudgetID,
+		CategoryID: rule.CategoryID,
+		Priority:   rule.Priority,
+	}
+}
+
+// checkBudget makes sure the user may record expenses in a budget. Budgets
+// the user cannot spend from are reported as not found.
+func (s *ImportService) checkBudget(userID, budgetID uuid.UUID) error {
+	budget, err := s.budgetRepo.FindByID(budgetID)
+	if err != nil {
+		return errors.New("budget not found")
+	}
+	role, err := budgetRole(s.memberRepo, s.workspace, budget, userID)
+	if err != nil {
+		return err
+	}
+	if !role.Allows(models.BudgetActionSpend) {
+		return errors.New("budget not found")
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// loginAttemptRetention is how long sign-in attempts are kept
+const loginAttemptRetention = 90 * 24 * time.Hour
+
+// LoginPolicy controls how failed sign-ins are throttled.
+type LoginPolicy struct {
+	// MaxFailures wrong passwords or codes in a row lock the account for
+	// LockoutDuration. Zero disables lockout.
+	MaxFailures     int
+	LockoutDuration time.Duration
+	// Each failure within Window doubles the wait before the next attempt,
+	// starting at BackoffBase and capped at BackoffMax
+	Window      time.Duration
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// IPThreshold failures from one IP address are allowed within Window
+	// before backoff applies to it, as many users may share an address
+	IPThreshold int
+}
+
+// LoginBlockedError is returned when a sign-in is refused before the
+// credentials are checked.
+type LoginBlockedError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return e.Reason
+}
+
+// backoff returns how long to wait after the given number of failures,
+// the first free of which cost nothing.
+func (p LoginPolicy) backoff(failures int64, free int) time.Duration {
+	over := failures - int64(free)
+	if over <= 0 || p.BackoffBase <= 0 {
+		return 0
+	}
+	delay := p.BackoffBase
+	for i := int64(1); i < over; i++ {
+		delay *= 2
+		if p.BackoffMax > 0 && delay >= p.BackoffMax {
+			return p.BackoffMax
+		}
+	}
+	if p.BackoffMax > 0 && delay > p.BackoffMax {
+		return p.BackoffMax
+	}
+	return delay
+}
+
+// checkLoginThrottle refuses and records an attempt that comes too soon
+// after earlier failures for the same email or from the same IP address.
+func (s *AuthService) checkLoginThrottle(email, ip, userAgent string, now time.Time) error {
+	since := now.Add(-s.loginPolicy.Window)
+
+	failures, last, err := s.loginAttemptRepo.FailuresByEmail(email, since)
+	if err != nil {
+		return err
+	}
+	wait := last.Add(s.loginPolicy.backoff(failures, 0)).Sub(now)
+
+	failures, last, err = s.loginAttemptRepo.FailuresByIP(ip, since)
+	if err != nil {
+		return err
+	}
+	if ipWait := last.Add(s.loginPolicy.backoff(failures, s.loginPolicy.IPThreshold)).Sub(now); ipWait > wait {
+		wait = ipWait
+	}
+
+	if wait > 0 {
+		return s.failLogin(nil, email, ip, userAgent, models.LoginThrottled,
+			&LoginBlockedError{Reason: "too many login attempts", RetryAfter: wait})
+	}
+	return nil
+}
+
+// checkLocked refuses sign-ins to a locked account without looking at the
+// credentials.
+func checkLocked(user *models.User, now time.Time) error {
+	if user.Locked(now) {
+		return &LoginBlockedError{Reason: "account temporarily locked", RetryAfter: user.LockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// verifyPassword checks the current password of a signed-in user before a
+// sensitive account change. Wrong passwords are throttled, counted and lock
+// the account like failed logins, so a stolen session cannot be used to
+// guess the password.
+func (s *AuthService) verifyPassword(userID uuid.UUID, password, ip, userAgent string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	email := normalizeEmail(user.Email)
+	now := time.Now()
+	if err := s.checkLoginThrottle(email, ip, userAgent, now); err != nil {
+		return err
+	}
+	if err := checkLocked(user, now); err != nil {
+		return s.failLogin(user, email, ip, userAgent, models.LoginLocked, err)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			locked, err := s.userRepo.WithTx(tx).FindByIDForUpdate(user.ID)
+			if err != nil {
+				return err
+			}
+			return s.registerLoginFailure(tx, locked, now)
+		})
+		if err != nil {
+			return err
+		}
+		return s.failLogin(user, email, ip, userAgent, models.LoginInvalidCredentials, errors.New("invalid password"))
+	}
+	return nil
+}
+
+// registerLoginFailure counts a wrong password or code against the user and
+// locks the account once the limit is reached.
+func (s *AuthService) registerLoginFailure(tx *gorm.DB, user *models.User, now time.Time) error {
+	user.FailedLogins++
+	if s.loginPolicy.MaxFailures > 0 && user.FailedLogins >= s.loginPolicy.MaxFailures {
+		until := now.Add(s.loginPolicy.LockoutDuration)
+		user.LockedUntil = &until
+		user.FailedLogins = 0
+	}
+	return s.userRepo.WithTx(tx).Update(user)
+}
+
+// resetLoginFailures clears the failure count after a successful sign-in.
+func (s *AuthService) resetLoginFailures(tx *gorm.DB, user *models.User) error {
+	if user.FailedLogins == 0 && user.LockedUntil == nil {
+		return nil
+	}
+	user.FailedLogins = 0
+	user.LockedUntil = nil
+	return s.userRepo.WithTx(tx).Update(user)
+}
+
+func (s *AuthService) recordLoginAttempt(user *models.User, email, ip, userAgent string, result models.LoginAttemptResult) error {
+	attempt := &models.LoginAttempt{
+		ID:        uuid.New(),
+		Email:     email,
+		IP:        ip,
+		UserAgent: truncate(userAgent, 255),
+		Success:   result == models.LoginSucceeded,
+		Result:    result,
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	return s.loginAttemptRepo.Create(attempt)
+}
+
+// failLogin records a refused attempt and returns the reason it was refused,
+// or the error from recording it.
+func (s *AuthService) failLogin(user *models.User, email, ip, userAgent string, result models.LoginAttemptResult, reason error) error {
+	if err := s.recordLoginAttempt(user, email, ip, userAgent, result); err != nil {
+		return err
+	}
+	return reason
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// truncate cuts s to at most n bytes without splitting a character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/mailer"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// emailChangeTTL is how long an email change confirmation link stays valid
+const emailChangeTTL = 24 * time.Hour
+
+// defaultSignInPageSize is how many sign-ins GET /me/sessions shows
+const defaultSignInPageSize = 20
+
+type ProfileService struct {
+	db               *gorm.DB
+	userRepo         *repositories.UserRepository
+	userTokenRepo    *repositories.UserTokenRepository
+	loginAttemptRepo *repositories.LoginAttemptRepository
+	workspaceRepo    *repositories.WorkspaceRepository
+	memberRepo       *repositories.WorkspaceMemberRepository
+	authService      *AuthService
+	transactions     *TransactionService
+	mailer           mailer.Mailer
+	linkBaseURL      string
+}
+
+func NewProfileService(db *gorm.DB, userRepo *repositories.UserRepository, userTokenRepo *repositories.UserTokenRepository, loginAttemptRepo *repositories.LoginAttemptRepository, workspaceRepo *repositories.WorkspaceRepository, memberRepo *repositories.WorkspaceMemberRepository, authService *AuthService, transactions *TransactionService, mailer mailer.Mailer, linkBaseURL string) *ProfileService {
+	return &ProfileService{
+		db:               db,
+		userRepo:         userRepo,
+		userTokenRepo:    userTokenRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		workspaceRepo:    workspaceRepo,
+		memberRepo:       memberRepo,
+		authService:      authService,
+		transactions:     transactions,
+		mailer:           mailer,
+		linkBaseURL:      strings.TrimRight(linkBaseURL, "/"),
+	}
+}
+
+func (s *ProfileService) GetProfile(userID uuid.UUID) (*responses.ProfileResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return toProfileResponse(user), nil
+}
+
+func (s *ProfileService) UpdateProfile(userID uuid.UUID, req *requests.UpdateProfileRequest) (*responses.ProfileResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	user.Name = req.Name
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return toProfileResponse(user), nil
+}
+
+// GetSignIns lists the user's latest sign-in attempts, including failed
+// ones, so unknown activity can be spotted.
+func (s *ProfileService) GetSignIns(userID uuid.UUID, query *requests.SignInListQuery) ([]responses.SignInResponse, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSignInPageSize
+	}
+
+	attempts, err := s.loginAttemptRepo.FindRecentByUserID(userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]responses.SignInResponse, len(attempts))
+	for i, attempt := range attempts {
+		response[i] = responses.SignInResponse{
+			ID:        attempt.ID,
+			IP:        attempt.IP,
+			UserAgent: attempt.UserAgent,
+			Success:   attempt.Success,
+			Result:    string(attempt.Result),
+			CreatedAt: attempt.CreatedAt,
+		}
+	}
+	return response, nil
+}
+
+// ChangePassword replaces the password and signs the user out everywhere:
+// all refresh tokens are revoked and access tokens issued before now stop
+// working. The caller gets a fresh session in return.
+func (s *ProfileService) ChangePassword(userID uuid.UUID, req *requests.ChangePasswordRequest, ip, userAgent string) (*responses.AuthResponse, error) {
+	if err := s.authService.verifyPassword(userID, req.CurrentPassword, ip, userAgent); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		user, err = s.userRepo.WithTx(tx).FindByID(userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+
+		return s.authService.revokeSessions(tx, user, string(hashedPassword))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authService.StartSession(user)
+}
+
+// RequestEmailChange sends a confirmation link to the new address. The
+// email only changes once the link is used.
+func (s *ProfileService) RequestEmailChange(userID uuid.UUID, req *requests.ChangeEmailRequest, ip, userAgent string) error {
+	if err := s.authService.verifyPassword(userID, req.Password, ip, userAgent); err != nil {
+		return err
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	newEmail := normalizeEmail(req.NewEmail)
+	if newEmail == normalizeEmail(user.Email) {
+		return errors.New("email is unchanged")
+	}
+	if existing, _ := s.userRepo.FindByEmail(newEmail); existing != nil {
+		return errors.New("email already in use")
+	}
+
+	token, err := s.authService.createUserToken(user.ID, models.UserTokenEmailChange, emailChangeTTL, newEmail)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mailer.Message{
+		To:      newEmail,
+		Subject: "Konfirmasi perubahan email",
+		Body: fmt.Sprintf("Halo %s,\n\nBuka tautan berikut untuk memakai alamat ini di akun Anda:\n%s\n\nTautan berlaku selama 24 jam. Abaikan email ini jika Anda tidak memintanya.\n",
+			user.Name, mailLink(s.linkBaseURL, "/confirm-email", token)),
+	})
+}
+
+// ConfirmEmailChange applies the email change a confirmation token was
+// issued for and lets the old address know.
+func (s *ProfileService) ConfirmEmailChange(req *requests.ConfirmEmailRequest) (*responses.ProfileResponse, error) {
+	var user *models.User
+	var oldEmail string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		userTokenRepo := s.userTokenRepo.WithTx(tx)
+		token, err := userTokenRepo.FindByHashForUpdate(utils.HashToken(req.Token), models.UserTokenEmailChange)
+		if err != nil || token.UsedAt != nil || !time.Now().Before(token.ExpiresAt) {
+			return errors.New("invalid or expired token")
+		}
+
+		userRepo := s.userRepo.WithTx(tx)
+		user, err = userRepo.FindByID(token.UserID)
+		if err != nil {
+			return errors.New("invalid or expired token")
+		}
+		newEmail := normalizeEmail(token.NewEmail)
+		if existing, _ := userRepo.FindByEmail(newEmail); existing != nil {
+			return errors.New("email already in use")
+		}
+
+		now := time.Now()
+		token.UsedAt = &now
+		if err := userTokenRepo.Update(token); err != nil {
+			return err
+		}
+
+		// Following the link proves the new address works
+		oldEmail = user.Email
+		user.Email = newEmail
+		user.EmailVerifiedAt = &now
+		return userRepo.Update(user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The change is done; a failed notice should not undo it
+	_ = s.mailer.Send(mailer.Message{
+		To:      oldEmail,
+		Subject: "Email akun Anda telah diubah",
+		Body:    fmt.Sprintf("Halo %s,\n\nEmail akun Anda telah diubah menjadi %s. Hubungi kami jika Anda tidak melakukan perubahan ini.\n", user.Name, user.Email),
+	})
+
+	return toProfileResponse(user), nil
+}
+
+// DeleteAccount permanently removes the user and all of their data. It is
+// refused while the user is the last owner of a workspace other members
+// still use; workspaces nobody else is in are deleted with the account.
+func (s *ProfileService) DeleteAccount(userID uuid.UUID, req *requests.DeleteAccountRequest, ip, userAgent string) error {
+	if err := s.authService.verifyPassword(userID, req.Password, ip, userAgent); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := s.userRepo.WithTx(tx)
+		user, err := userRepo.FindByID(userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+
+		memberRepo := s.memberRepo.WithTx(tx)
+		memberships, err := memberRepo.FindByUserID(user.ID)
+		if err != nil {
+			return err
+		}
+		for _, membership := range memberships {
+			if membership.Workspace.Personal {
+				continue
+			}
+			members, err := memberRepo.FindByWorkspaceID(membership.WorkspaceID)
+			if err != nil {
+				return err
+			}
+			if len(members) == 1 {
+				if err := s.workspaceRepo.WithTx(tx).DeleteWithData(membership.WorkspaceID); err != nil {
+					return err
+				}
+				continue
+			}
+			if membership.Role == models.WorkspaceRoleOwner {
+				if err := keepOwner(memberRepo, membership.WorkspaceID); err != nil {
+					return errors.New("transfer workspace ownership first")
+				}
+			}
+		}
+
+		if err := userRepo.HandOverBudgets(user.ID); err != nil {
+			return err
+		}
+		if err := s.transactions.releaseAllocations(tx, user.ID); err != nil {
+			return err
+		}
+		return userRepo.DeleteWithData(user.ID)
+	})
+}
+
+func toProfileResponse(user *models.User) *responses.ProfileResponse {
+	return &responses.ProfileResponse{
+		ID:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		EmailVerified: user.EmailVerified(),
+		CreatedAt:     user.CreatedAt,
+	}
+}
@@ -0,0 +1,561 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// bookingRefusals are the errors an occurrence is recorded as failed for.
+// They are all raised before the expense is written.
+var bookingRefusals = []error{ErrWorkspaceNotFound, ErrBudgetNotFound, ErrCategoryNotFound, ErrUnauthorized, ErrInsufficientBudget}
+
+const (
+	defaultUpcomingDays = 30
+	// maxOccurrencesPerRun caps how many missed occurrences of one recurring
+	// expense a single scheduler run catches up on
+	maxOccurrencesPerRun = 100
+	// maxUpcomingPerRecurring caps how many occurrences of one recurring
+	// expense are listed or searched ahead
+	maxUpcomingPerRecurring = 400
+)
+
+// RecurringExpenseService keeps recurring expense templates and books their
+// occurrences through ExpenseService, so every occurrence gets the same
+// budget checks and approval rules as an expense recorded by hand.
+type RecurringExpenseService struct {
+	db             *gorm.DB
+	recurringRepo  *repositories.RecurringExpenseRepository
+	budgetRepo     *repositories.BudgetRepository
+	categoryRepo   *repositories.CategoryRepository
+	memberRepo     *repositories.BudgetMemberRepository
+	expenseService *ExpenseService
+	workspaces     *WorkspaceService
+	workspace      WorkspaceScope
+}
+
+func NewRecurringExpenseService(db *gorm.DB, recurringRepo *repositories.RecurringExpenseRepository, budgetRepo *repositories.BudgetRepository, categoryRepo *repositories.CategoryRepository, memberRepo *repositories.BudgetMemberRepository, expenseService *ExpenseService, workspaces *WorkspaceService) *RecurringExpenseService {
+	return &RecurringExpenseService{
+		db:             db,
+		recurringRepo:  recurringRepo,
+		budgetRepo:     budgetRepo,
+		categoryRepo:   categoryRepo,
+		memberRepo:     memberRepo,
+		expenseService: expenseService,
+		workspaces:     workspaces,
+	}
+}
+
+// InWorkspace returns a copy of the service that only sees the given
+// workspace and acts with the caller's role in it.
+func (s *RecurringExpenseService) InWorkspace(workspace WorkspaceScope) *RecurringExpenseService {
+	return &RecurringExpenseService{
+		db:             repositories.ScopeWorkspace(s.db, workspace.ID),
+		recurringRepo:  s.recurringRepo.InWorkspace(workspace.ID),
+		budgetRepo:     s.budgetRepo.InWorkspace(workspace.ID),
+		categoryRepo:   s.categoryRepo.InWorkspace(workspace.ID),
+		memberRepo:     s.memberRepo.InWorkspace(workspace.ID),
+		expenseService: s.expenseService.InWorkspace(workspace),
+		workspaces:     s.workspaces,
+		workspace:      workspace,
+	}
+}
+
+func (s *RecurringExpenseService) CreateRecurringExpense(userID uuid.UUID, req *requests.CreateRecurringExpenseRequest) (*responses.RecurringExpenseResponse, error) {
+	budget, err := s.checkTemplate(userID, req.BudgetID, req.CategoryID, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	recurring := &models.RecurringExpense{
+		ID:          uuid.New(),
+		UserID:      userID,
+		BudgetID:    req.BudgetID,
+		CategoryID:  req.CategoryID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Tags:        normalizeTags(req.Tags),
+		Frequency:   req.Frequency,
+		Interval:    max(req.Interval, 1),
+		StartDate:   req.StartDate.Truncate(time.Second),
+		EndDate:     req.EndDate,
+		Count:       req.Count,
+	}
+	recurring.Advance(0)
+
+	if err := s.recurringRepo.Create(recurring); err != nil {
+		return nil, err
+	}
+	recurring.Budget = *budget
+
+	response := toRecurringExpenseResponse(recurring)
+	return &response, nil
+}
+
+func (s *RecurringExpenseService) GetRecurringExpenses(userID uuid.UUID) (*responses.RecurringExpenseListResponse, error) {
+	recurring, err := s.recurringRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	recurringResponses := []responses.RecurringExpenseResponse{}
+	for i := range recurring {
+		recurringResponses = append(recurringResponses, toRecurringExpenseResponse(&recurring[i]))
+	}
+
+	return &responses.RecurringExpenseListResponse{
+		RecurringExpenses: recurringResponses,
+		Total:             len(recurringResponses),
+	}, nil
+}
+
+// UpdateRecurringExpense changes the template for every occurrence that has
+// not been booked yet. A new schedule continues after the last booked
+// occurrence and drops skips and edits of the old one.
+func (s *RecurringExpenseService) UpdateRecurringExpense(userID uuid.UUID, recurringID uuid.UUID, req *requests.UpdateRecurringExpenseRequest) (*responses.RecurringExpenseResponse, error) {
+	budget, err := s.checkTemplate(userID, req.BudgetID, req.CategoryID, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var recurring *models.RecurringExpense
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		recurringRepo := s.recurringRepo.WithTx(tx)
+
+		var err error
+		recurring, err = s.lockOwned(recurringRepo, userID, recurringID)
+		if err != nil {
+			return err
+		}
+
+		before := *recurring
+		recurring.BudgetID = req.BudgetID
+		recurring.CategoryID = req.CategoryID
+		recurring.Amount = req.Amount
+		recurring.Description = req.Description
+		recurring.Tags = normalizeTags(req.Tags)
+		recurring.Frequency = req.Frequency
+		recurring.Interval = max(req.Interval, 1)
+		recurring.StartDate = req.StartDate.Truncate(time.Second)
+		recurring.EndDate = req.EndDate
+		recurring.Count = req.Count
+
+		next := recurring.NextIndex
+		if scheduleChanged(&before, recurring) {
+			var from time.Time
+			next = 0
+			if before.NextIndex > 0 {
+				last := before.Occurrence(before.NextIndex - 1)
+				from = last.Add(time.Second)
+				next = occurrenceAfter(recurring, last)
+			}
+			if err := recurringRepo.DeletePendingOccurrences(recurring.ID, from); err != nil {
+				return err
+			}
+		}
+		recurring.Advance(next)
+
+		return recurringRepo.Update(recurring)
+	})
+	if err != nil {
+		return nil, err
+	}
+	recurring.Budget = *budget
+
+	response := toRecurringExpenseResponse(recurring)
+	return &response, nil
+}
+
+// DeleteRecurringExpense stops a recurring expense. Expenses it already
+// booked stay.
+func (s *RecurringExpenseService) DeleteRecurringExpense(userID uuid.UUID, recurringID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		recurringRepo := s.recurringRepo.WithTx(tx)
+		if _, err := s.lockOwned(recurringRepo, userID, recurringID); err != nil {
+			return err
+		}
+		return recurringRepo.Delete(recurringID)
+	})
+}
+
+// GetUpcomingOccurrences lists the occurrences of the user's recurring
+// expenses due in the next days, skipped ones included, soonest first.
+func (s *RecurringExpenseService) GetUpcomingOccurrences(userID uuid.UUID, query *requests.UpcomingOccurrencesQuery, now time.Time) (*responses.OccurrenceListResponse, error) {
+	days := query.Days
+	if days <= 0 {
+		days = defaultUpcomingDays
+	}
+	to := now.AddDate(0, 0, days)
+
+	all, err := s.recurringRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var recurring []models.RecurringExpense
+	ids := []uuid.UUID{}
+	from := to
+	for _, r := range all {
+		if r.NextDate != nil && r.NextDate.Before(to) {
+			recurring = append(recurring, r)
+			ids = append(ids, r.ID)
+			from = minTime(from, *r.NextDate)
+		}
+	}
+
+	recorded, err := s.recurringRepo.FindOccurrences(ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+	type occurrenceKey struct {
+		recurringID uuid.UUID
+		date        int64
+	}
+	changes := make(map[occurrenceKey]*models.RecurringOccurrence, len(recorded))
+	for i := range recorded {
+		changes[occurrenceKey{recorded[i].RecurringExpenseID, recorded[i].Date.Unix()}] = &recorded[i]
+	}
+
+	occurrences := []responses.OccurrenceResponse{}
+	for i := range recurring {
+		r := &recurring[i]
+		for n := r.NextIndex; n < r.NextIndex+maxUpcomingPerRecurring && r.HasOccurrence(n); n++ {
+			date := r.Occurrence(n)
+			if !date.Before(to) {
+				break
+			}
+			occurrences = append(occurrences, toOccurrenceResponse(r, date, changes[occurrenceKey{r.ID, date.Unix()}]))
+		}
+	}
+	slices.SortStableFunc(occurrences, func(a, b responses.OccurrenceResponse) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	return &responses.OccurrenceListResponse{
+		Occurrences: occurrences,
+		Total:       len(occurrences),
+	}, nil
+}
+
+// SkipOccurrence makes the scheduler pass over the occurrence on the given day.
+func (s *RecurringExpenseService) SkipOccurrence(userID uuid.UUID, recurringID uuid.UUID, day time.Time) (*responses.OccurrenceResponse, error) {
+	return s.changeOccurrence(userID, recurringID, day, func(occurrence *models.RecurringOccurrence) {
+		occurrence.Status = models.OccurrenceSkipped
+	})
+}
+
+// UpdateOccurrence changes the amount or description of the occurrence on
+// the given day only. A skipped occurrence is scheduled again.
+func (s *RecurringExpenseService) UpdateOccurrence(userID uuid.UUID, recurringID uuid.UUID, day time.Time, req *requests.UpdateOccurrenceRequest) (*responses.OccurrenceResponse, error) {
+	return s.changeOccurrence(userID, recurringID, day, func(occurrence *models.RecurringOccurrence) {
+		occurrence.Status = models.OccurrenceScheduled
+		occurrence.Amount = req.Amount
+		occurrence.Description = req.Description
+	})
+}
+
+func (s *RecurringExpenseService) changeOccurrence(userID uuid.UUID, recurringID uuid.UUID, day time.Time, change func(*models.RecurringOccurrence)) (*responses.OccurrenceResponse, error) {
+	var recurring *models.RecurringExpense
+	var occurrence *models.RecurringOccurrence
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		recurringRepo := s.recurringRepo.WithTx(tx)
+
+		// Lock the template so the scheduler cannot book the occurrence meanwhile
+		var err error
+		recurring, err = s.lockOwned(recurringRepo, userID, recurringID)
+		if err != nil {
+			return err
+		}
+
+		date, ok := upcomingOn(recurring, day)
+		if !ok {
+			return errors.New("occurrence not found")
+		}
+
+		occurrence, err = recurringRepo.FindOccurrence(recurring.ID, date)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			occurrence = &models.RecurringOccurrence{
+				ID:                 uuid.New(),
+				WorkspaceID:        recurring.WorkspaceID,
+				RecurringExpenseID: recurring.ID,
+				Date:               date,
+			}
+			change(occurrence)
+			return recurringRepo.CreateOccurrence(occurrence)
+		}
+		if err != nil {
+			return err
+		}
+		change(occurrence)
+		return recurringRepo.UpdateOccurrence(occurrence)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := s.budgetRepo.FindByID(recurring.BudgetID)
+	if err == nil {
+		recurring.Budget = *budget
+	}
+	response := toOccurrenceResponse(recurring, occurrence.Date, occurrence)
+	return &response, nil
+}
+
+// BookDueOccurrences books every occurrence due at now as an expense. It is
+// run periodically in the background. Each recurring expense is handled in
+// its own transaction that also moves it to its next occurrence, so an
+// occurrence is booked exactly once even across restarts. Occurrences that
+// are refused, for example because the budget ran out, are recorded as
+// failed and not retried. Any other error is recorded on the recurring
+// expense, which is retried on the next run, and does not stop the others.
+// It returns how many expenses were booked and the errors joined together.
+func (s *RecurringExpenseService) BookDueOccurrences(now time.Time) (int, error) {
+	ids, err := s.recurringRepo.FindDue(now)
+	if err != nil {
+		return 0, err
+	}
+
+	booked := 0
+	var failures []error
+	for _, id := range ids {
+		n, err := s.book(id, now)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("recurring expense %s: %w", id, err))
+			if err := s.recurringRepo.RecordFailure(id, err.Error(), now); err != nil {
+				failures = append(failures, err)
+			}
+			continue
+		}
+		booked += n
+	}
+	return booked, errors.Join(failures...)
+}
+
+func isBookingRefusal(err error) bool {
+	return slices.ContainsFunc(bookingRefusals, func(refusal error) bool {
+		return errors.Is(err, refusal)
+	})
+}
+
+func (s *RecurringExpenseService) book(recurringID uuid.UUID, now time.Time) (int, error) {
+	booked := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		recurringRepo := s.recurringRepo.WithTx(tx)
+		recurring, err := recurringRepo.FindByIDForUpdate(recurringID)
+		if err != nil {
+			return err
+		}
+
+		// The owner acts with whatever role they have in the workspace today
+		workspaceID, role, resolveErr := s.workspaces.ResolveWorkspace(recurring.UserID, recurring.WorkspaceID)
+		expenses := s.expenseService.withTx(tx).InWorkspace(WorkspaceScope{ID: workspaceID, Role: role})
+
+		for i := 0; i < maxOccurrencesPerRun && recurring.NextDate != nil && !recurring.NextDate.After(now); i++ {
+			date := *recurring.NextDate
+			occurrence, err := recurringRepo.FindOccurrence(recurring.ID, date)
+			isNew := errors.Is(err, gorm.ErrRecordNotFound)
+			if isNew {
+				occurrence = &models.RecurringOccurrence{
+					ID:                 uuid.New(),
+					WorkspaceID:        recurring.WorkspaceID,
+					RecurringExpenseID: recurring.ID,
+					Date:               date,
+					Status:             models.OccurrenceScheduled,
+				}
+			} else if err != nil {
+				return err
+			}
+
+			if occurrence.Status == models.OccurrenceScheduled {
+				req := &requests.CreateExpenseRequest{
+					BudgetID:    recurring.BudgetID,
+					CategoryID:  recurring.CategoryID,
+					Amount:      recurring.Amount,
+					Description: recurring.Description,
+					Date:        date,
+					Tags:        recurring.Tags,
+				}
+				if occurrence.Amount != nil {
+					req.Amount = *occurrence.Amount
+				}
+				if occurrence.Description != nil {
+					req.Description = *occurrence.Description
+				}
+
+				err := resolveErr
+				if err == nil {
+					var expense *responses.CreateExpenseResponse
+					if expense, err = expenses.CreateExpense(recurring.UserID, req); err == nil {
+						occurrence.Status = models.OccurrenceBooked
+						occurrence.ExpenseID = &expense.ID
+						booked++
+					}
+				}
+				if err != nil {
+					// Anything but a refusal is retried on the next run
+					if !isBookingRefusal(err) {
+						return err
+					}
+					occurrence.Status = models.OccurrenceFailed
+					occurrence.Error = err.Error()
+				}
+			}
+
+			if isNew {
+				err = recurringRepo.CreateOccurrence(occurrence)
+			} else {
+				err = recurringRepo.UpdateOccurrence(occurrence)
+			}
+			if err != nil {
+				return err
+			}
+
+			recurring.Advance(recurring.NextIndex + 1)
+		}
+
+		recurring.LastError = ""
+		recurring.LastFailedAt = nil
+		return recurringRepo.Update(recurring)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return booked, nil
+}
+
+// checkTemplate checks that the user may spend from the budget, owns the
+// category and that the schedule ends after it starts.
+func (s *RecurringExpenseService) checkTemplate(userID, budgetID uuid.UUID, categoryID *uuid.UUID, startDate time.Time, endDate *time.Time) (*models.Budget, error) {
+	if endDate != nil && endDate.Before(startDate) {
+		return nil, errors.New("end date is before start date")
+	}
+
+	budget, err := s.budgetRepo.FindByID(budgetID)
+	if err != nil {
+		return nil, errors.New("budget not found")
+	}
+	if err := authorizeBudget(s.memberRepo, s.workspace, budget, userID, models.BudgetActionSpend); err != nil {
+		return nil, errors.New("budget not found")
+	}
+
+	if categoryID != nil {
+		category, err := s.categoryRepo.FindByID(*categoryID)
+		if err != nil || category.UserID != userID {
+			return nil, errors.New("category not found")
+		}
+	}
+	return budget, nil
+}
+
+func (s *RecurringExpenseService) lockOwned(recurringRepo *repositories.RecurringExpenseRepository, userID, recurringID uuid.UUID) (*models.RecurringExpense, error) {
+	recurring, err := recurringRepo.FindByIDForUpdate(recurringID)
+	if err != nil {
+		return nil, errors.New("recurring expense not found")
+	}
+	if recurring.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+	return recurring, nil
+}
+
+func scheduleChanged(before, after *models.RecurringExpense) bool {
+	return before.Frequency != after.Frequency ||
+		before.Interval != after.Interval ||
+		!before.StartDate.Equal(after.StartDate) ||
+		before.Count != after.Count ||
+		(before.EndDate == nil) != (after.EndDate == nil) ||
+		(before.EndDate != nil && !before.EndDate.Equal(*after.EndDate))
+}
+
+// occurrenceAfter returns the index of the first occurrence dated after t.
+func occurrenceAfter(recurring *models.RecurringExpense, t time.Time) int {
+	n := 0
+	for recurring.HasOccurrence(n) && !recurring.Occurrence(n).After(t) {
+		n++
+	}
+	return n
+}
+
+// upcomingOn finds the occurrence not booked yet that falls on the calendar
+// day of day.
+func upcomingOn(recurring *models.RecurringExpense, day time.Time) (time.Time, bool) {
+	for n := recurring.NextIndex; n < recurring.NextIndex+maxUpcomingPerRecurring && recurring.HasOccurrence(n); n++ {
+		occurrence := recurring.Occurrence(n)
+		year, month, date := day.Date()
+		target := time.Date(year, month, date, 0, 0, 0, 0, occurrence.Location())
+		switch {
+		case calendarDay(occurrence).Equal(target):
+			return occurrence, true
+		case calendarDay(occurrence).After(target):
+			return time.Time{}, false
+		}
+	}
+	return time.Time{}, false
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func toRecurringExpenseResponse(recurring *models.RecurringExpense) responses.RecurringExpenseResponse {
+	tags := recurring.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return responses.RecurringExpenseResponse{
+		ID:           recurring.ID,
+		BudgetID:     recurring.BudgetID,
+		BudgetName:   recurring.Budget.Name,
+		CategoryID:   recurring.CategoryID,
+		Amount:       recurring.Amount,
+		Description:  recurring.Description,
+		Tags:         tags,
+		Frequency:    recurring.Frequency,
+		Interval:     recurring.Interval,
+		StartDate:    recurring.StartDate,
+		EndDate:      recurring.EndDate,
+		Count:        recurring.Count,
+		RRule:        recurring.RRule(),
+		NextDate:     recurring.NextDate,
+		LastError:    recurring.LastError,
+		LastFailedAt: recurring.LastFailedAt,
+		CreatedAt:    recurring.CreatedAt,
+	}
+}
+
+// toOccurrenceResponse describes an upcoming occurrence, applying the
+// recorded change to it if there is one.
+func toOccurrenceResponse(recurring *models.RecurringExpense, date time.Time, change *models.RecurringOccurrence) responses.OccurrenceResponse {
+	response := responses.OccurrenceResponse{
+		RecurringExpenseID: recurring.ID,
+		Date:               date,
+		BudgetID:           recurring.BudgetID,
+		BudgetName:         recurring.Budget.Name,
+		Amount:             recurring.Amount,
+		Description:        recurring.Description,
+		Status:             models.OccurrenceScheduled,
+	}
+	if change != nil {
+		response.Status = change.Status
+		if change.Amount != nil {
+			response.Amount = *change.Amount
+			response.Edited = true
+		}
+		if change.Description != nil {
+			response.Description = *change.Description
+			response.Edited = true
+		}
+	}
+	return response
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+)
+
+const topBudgetCount = 5
+
+type ReportService struct {
+	expenseRepo *repositories.ExpenseRepository
+}
+
+func NewReportService(expenseRepo *repositories.ExpenseRepository) *ReportService {
+	return &ReportService{
+		expenseRepo: expenseRepo,
+	}
+}
+
+// InWorkspace returns a copy of the service that only sees the given workspace.
+func (s *ReportService) InWorkspace(workspace WorkspaceScope) *ReportService {
+	return &ReportService{
+		expenseRepo: s.expenseRepo.InWorkspace(workspace.ID),
+	}
+}
+
+// GetSummary aggregates a user's expenses in [from, to) and compares them
+// with the period of the same length right before it.
+func (s *ReportService) GetSummary(userID uuid.UUID, query *requests.ReportSummaryQuery, now time.Time) (*responses.ReportSummaryResponse, error) {
+	from, to := reportRange(query.From, query.To, now)
+	if !to.After(from) {
+		return nil, errors.New("invalid date range")
+	}
+
+	groupBy := repositories.ExpenseGroup(query.GroupBy)
+	if groupBy == repositories.ExpenseGroupNone {
+		groupBy = repositories.ExpenseGroupMonth
+	}
+
+	filter := repositories.ExpenseFilter{From: from, To: to}
+	groups, err := s.expenseRepo.TotalsByUserID(userID, filter, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := groups
+	if groupBy != repositories.ExpenseGroupBudget {
+		budgets, err = s.expenseRepo.TotalsByUserID(userID, filter, repositories.ExpenseGroupBudget)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	previous, err := s.expenseRepo.TotalsByUserID(userID, repositories.ExpenseFilter{From: from.Add(-to.Sub(from)), To: from}, repositories.ExpenseGroupNone)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &responses.ReportSummaryResponse{
+		From:       from,
+		To:         to,
+		GroupBy:    string(groupBy),
+		Groups:     []responses.ReportGroupResponse{},
+		TopBudgets: []responses.ReportGroupResponse{},
+	}
+	for _, group := range groups {
+		summary.Groups = append(summary.Groups, toReportGroupResponse(group))
+		summary.Total += group.Total
+		summary.Count += int(group.Count)
+	}
+	summary.Average = averageMoney(summary.Total, int64(summary.Count))
+	summary.DailyAverage = averageMoney(summary.Total, int64(math.Ceil(to.Sub(from).Hours()/24)))
+
+	slices.SortStableFunc(budgets, func(a, b repositories.ExpenseGroupTotal) int {
+		return cmp.Compare(b.Total, a.Total)
+	})
+	for _, budget := range budgets[:min(len(budgets), topBudgetCount)] {
+		summary.TopBudgets = append(summary.TopBudgets, toReportGroupResponse(budget))
+	}
+
+	if len(previous) > 0 {
+		summary.PreviousTotal = previous[0].Total
+	}
+	summary.Change = summary.Total - summary.PreviousTotal
+	if summary.PreviousTotal != 0 {
+		percent := math.Round(float64(summary.Change)/float64(summary.PreviousTotal)*10000) / 100
+		summary.ChangePercent = &percent
+	}
+
+	return summary, nil
+}
+
+// reportRange defaults to the calendar month containing now.
+func reportRange(from, to *time.Time, now time.Time) (time.Time, time.Time) {
+	monthStart := calendarPeriodStart(models.BudgetPeriodMonthly, now)
+
+	var start, end time.Time
+	switch {
+	case from != nil && to != nil:
+		start, end = *from, *to
+	case from != nil:
+		start, end = *from, now
+	case to != nil:
+		start, end = to.AddDate(0, -1, 0), *to
+	default:
+		start, end = monthStart, monthStart.AddDate(0, 1, 0)
+	}
+	return start, end
+}
+
+func toReportGroupResponse(group repositories.ExpenseGroupTotal) responses.ReportGroupResponse {
+	return responses.ReportGroupResponse{
+		Key:     group.GroupKey,
+		Label:   group.GroupLabel,
+		Total:   group.Total,
+		Count:   int(group.Count),
+		Average: averageMoney(group.Total, group.Count),
+	}
+}
+
+// averageMoney divides total by count, rounding half away from zero.
+func averageMoney(total models.Money, count int64) models.Money {
+	if count <= 0 {
+		return 0
+	}
+	if total < 0 {
+		return -averageMoney(-total, count)
+	}
+	return models.Money((int64(total) + count/2) / count)
+}
@@ -0,0 +1,522 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TransactionService records incomes and transfers next to expenses and
+// exposes all three as a single ledger. Expenses are delegated to
+// ExpenseService so they keep the same budget checks.
+type TransactionService struct {
+	db             *gorm.DB
+	expenseService *ExpenseService
+	expenseRepo    *repositories.ExpenseRepository
+	budgetRepo     *repositories.BudgetRepository
+	historyRepo    *repositories.BudgetHistoryRepository
+	incomeRepo     *repositories.IncomeRepository
+	transferRepo   *repositories.TransferRepository
+	memberRepo     *repositories.BudgetMemberRepository
+	alertRepo      *repositories.BudgetAlertRepository
+	workspace      WorkspaceScope
+}
+
+func NewTransactionService(db *gorm.DB, expenseService *ExpenseService, expenseRepo *repositories.ExpenseRepository, budgetRepo *repositories.BudgetRepository, historyRepo *repositories.BudgetHistoryRepository, incomeRepo *repositories.IncomeRepository, transferRepo *repositories.TransferRepository, memberRepo *repositories.BudgetMemberRepository, alertRepo *repositories.BudgetAlertRepository) *TransactionService {
+	return &TransactionService{
+		db:             db,
+		expenseService: expenseService,
+		expenseRepo:    expenseRepo,
+		budgetRepo:     budgetRepo,
+		historyRepo:    historyRepo,
+		incomeRepo:     incomeRepo,
+		transferRepo:   transferRepo,
+		memberRepo:     memberRepo,
+		alertRepo:      alertRepo,
+	}
+}
+
+// InWorkspace returns a copy of the service that only sees the given
+// workspace and acts with the caller's role in it.
+func (s *TransactionService) InWorkspace(workspace WorkspaceScope) *TransactionService {
+	return &TransactionService{
+		db:             repositories.ScopeWorkspace(s.db, workspace.ID),
+		expenseService: s.expenseService.InWorkspace(workspace),
+		expenseRepo:    s.expenseRepo.InWorkspace(workspace.ID),
+		budgetRepo:     s.budgetRepo.InWorkspace(workspace.ID),
+		historyRepo:    s.historyRepo.InWorkspace(workspace.ID),
+		incomeRepo:     s.incomeRepo.InWorkspace(workspace.ID),
+		transferRepo:   s.transferRepo.InWorkspace(workspace.ID),
+		memberRepo:     s.memberRepo.InWorkspace(workspace.ID),
+		alertRepo:      s.alertRepo.InWorkspace(workspace.ID),
+		workspace:      workspace,
+	}
+}
+
+func (s *TransactionService) ledger(tx *gorm.DB) *budgetLedger {
+	return &budgetLedger{
+		budgetRepo:  s.budgetRepo.WithTx(tx),
+		expenseRepo: s.expenseRepo.WithTx(tx),
+		historyRepo: s.historyRepo.WithTx(tx),
+		alertRepo:   s.alertRepo.WithTx(tx),
+	}
+}
+
+func (s *TransactionService) CreateTransaction(userID uuid.UUID, req *requests.CreateTransactionRequest) (*responses.TransactionResponse, error) {
+	// Set current time if no date is provided
+	if req.Date.IsZero() {
+		req.Date = time.Now()
+	}
+
+	switch req.Type {
+	case models.TransactionIncome:
+		return s.createIncome(userID, req)
+	case models.TransactionTransfer:
+		return s.createTransfer(userID, req)
+	default:
+		return s.createExpense(userID, req)
+	}
+}
+
+func (s *TransactionService) createExpense(userID uuid.UUID, req *requests.CreateTransactionRequest) (*responses.TransactionResponse, error) {
+	if req.BudgetID == nil {
+		return nil, errors.New("budget_id is required")
+	}
+
+	expense, err := s.expenseService.CreateExpense(userID, &requests.CreateExpenseRequest{
+		BudgetID:    *req.BudgetID,
+		CategoryID:  req.CategoryID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Date:        req.Date,
+		Tags:        req.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &responses.TransactionResponse{
+		ID:          expense.ID,
+		Type:        models.TransactionExpense,
+		Amount:      expense.Amount,
+		Description: expense.Description,
+		Date:        expense.Date,
+		BudgetID:    &expense.BudgetID,
+		BudgetName:  expense.BudgetName,
+		Status:      expense.Status,
+		Tags:        expense.Tags,
+	}, nil
+}
+
+func (s *TransactionService) createIncome(userID uuid.UUID, req *requests.CreateTransactionRequest) (*responses.TransactionResponse, error) {
+	if req.TopUpBudget && req.BudgetID == nil {
+		return nil, errors.New("budget_id is required to top up a budget")
+	}
+
+	income := &models.Income{
+		ID:          uuid.New(),
+		UserID:      userID,
+		BudgetID:    req.BudgetID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Date:        req.Date,
+		TopUpBudget: req.TopUpBudget,
+	}
+
+	var budgetName string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if income.BudgetID != nil {
+			ledger := s.ledger(tx)
+			budgets, err := ledger.lock(time.Now(), *income.BudgetID)
+			if err != nil {
+				return errors.New("budget not found")
+			}
+
+			// Only the owner may raise what the budget can spend
+			budget := budgets[*income.BudgetID]
+			action := models.BudgetActionSpend
+			if income.TopUpBudget {
+				action = models.BudgetActionManage
+			}
+			if err := authorizeBudget(s.memberRepo.WithTx(tx), s.workspace, budget, userID, action); err != nil {
+				return err
+			}
+			budgetName = budget.Name
+
+			// Income can raise what the budget may spend
+			if income.TopUpBudget {
+				if err := ledger.adjustAllocation(budget, income.Amount); err != nil {
+					return err
+				}
+			}
+		}
+
+		return s.incomeRepo.WithTx(tx).Create(income)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toIncomeTransaction(income)
+	response.BudgetName = budgetName
+	return &response, nil
+}
+
+func (s *TransactionService) createTransfer(userID uuid.UUID, req *requests.CreateTransactionRequest) (*responses.TransactionResponse, error) {
+	if req.BudgetID == nil || req.ToBudgetID == nil || *req.BudgetID == *req.ToBudgetID {
+		return nil, errors.New("transfer needs two different budgets")
+	}
+
+	transfer := &models.Transfer{
+		ID:           uuid.New(),
+		UserID:       userID,
+		FromBudgetID: *req.BudgetID,
+		ToBudgetID:   *req.ToBudgetID,
+		Amount:       req.Amount,
+		Description:  req.Description,
+		Date:         req.Date,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		ledger := s.ledger(tx)
+
+		// Lock both budgets so the allocation moves all-or-nothing
+		budgets, err := ledger.lock(time.Now(), transfer.FromBudgetID, transfer.ToBudgetID)
+		if err != nil {
+			return errors.New("budget not found")
+		}
+
+		from, to := budgets[transfer.FromBudgetID], budgets[transfer.ToBudgetID]
+		memberRepo := s.memberRepo.WithTx(tx)
+		if err := authorizeBudget(memberRepo, s.workspace, from, userID, models.BudgetActionManage); err != nil {
+			return err
+		}
+		if err := authorizeBudget(memberRepo, s.workspace, to, userID, models.BudgetActionManage); err != nil {
+			return err
+		}
+
+		// Only what is left unspent can be moved
+		if from.Remaining() < transfer.Amount {
+			return errors.New("insufficient budget")
+		}
+
+		if err := ledger.adjustAllocation(from, -transfer.Amount); err != nil {
+			return err
+		}
+		if err := ledger.adjustAllocation(to, transfer.Amount); err != nil {
+			return err
+		}
+
+		transfer.FromBudget = *from
+		transfer.ToBudget = *to
+		return s.transferRepo.WithTx(tx).Create(transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toTransferTransaction(transfer)
+	return &response, nil
+}
+
+func (s *TransactionService) GetLedger(userID uuid.UUID, query *requests.TransactionListQuery) (*responses.LedgerResponse, error) {
+	var from, to time.Time
+	if query.From != nil {
+		from = *query.From
+	}
+	if query.To != nil {
+		to = *query.To
+	}
+
+	// Each kind is paged in the same order, so the newest rows of the three
+	// together are the newest of the ledger
+	page := repositories.LedgerPage{Limit: query.Limit}
+	if page.Limit <= 0 {
+		page.Limit = defaultExpensePageSize
+	}
+	if query.Cursor != "" {
+		after, err := decodeExpenseCursor(query.Cursor, repositories.ExpenseSortDateDesc)
+		if err != nil {
+			return nil, err
+		}
+		page.After = &repositories.LedgerCursor{Date: after.Date, ID: after.ID}
+	}
+	// Fetch one extra row to know whether another page follows
+	page.Limit++
+
+	transactions := []responses.TransactionResponse{}
+	ledger := &responses.LedgerResponse{}
+
+	if query.Type == "" || query.Type == models.TransactionExpense {
+		// Only approved expenses have left the budget
+		filter := repositories.ExpenseFilter{From: from, To: to, Status: models.ExpenseStatusApproved}
+		totals, err := s.expenseRepo.TotalsByUserID(userID, filter, repositories.ExpenseGroupNone)
+		if err != nil {
+			return nil, err
+		}
+		for _, total := range totals {
+			ledger.Total += int(total.Count)
+			ledger.TotalExpense += total.Total
+		}
+
+		expensePage := repositories.ExpensePage{Sort: repositories.ExpenseSortDateDesc, Limit: page.Limit}
+		if page.After != nil {
+			expensePage.After = &repositories.ExpenseCursor{Date: page.After.Date, ID: page.After.ID}
+		}
+		expenses, err := s.expenseRepo.FindByUserID(userID, filter, expensePage)
+		if err != nil {
+			return nil, err
+		}
+		for _, expense := range expenses {
+			budgetID := expense.BudgetID
+			transactions = append(transactions, responses.TransactionResponse{
+				ID:          expense.ID,
+				Type:        models.TransactionExpense,
+				Amount:      expense.Amount,
+				Description: expense.Description,
+				Date:        expense.Date,
+				BudgetID:    &budgetID,
+				BudgetName:  expense.Budget.Name,
+				Status:      expense.Status,
+				Tags:        tagNames(expense.Tags),
+			})
+		}
+	}
+
+	if query.Type == "" || query.Type == models.TransactionIncome {
+		count, total, err := s.incomeRepo.TotalByUserID(userID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		ledger.Total += int(count)
+		ledger.TotalIncome = total
+
+		incomes, err := s.incomeRepo.FindByUserID(userID, from, to, page)
+		if err != nil {
+			return nil, err
+		}
+		for i := range incomes {
+			transactions = append(transactions, toIncomeTransaction(&incomes[i]))
+		}
+	}
+
+	if query.Type == "" || query.Type == models.TransactionTransfer {
+		count, err := s.transferRepo.CountByUserID(userID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		ledger.Total += int(count)
+
+		transfers, err := s.transferRepo.FindByUserID(userID, from, to, page)
+		if err != nil {
+			return nil, err
+		}
+		for i := range transfers {
+			transactions = append(transactions, toTransferTransaction(&transfers[i]))
+		}
+	}
+
+	// Newest first across all three kinds, in the order the rows were paged
+	slices.SortFunc(transactions, func(a, b responses.TransactionResponse) int {
+		if c := b.Date.Compare(a.Date); c != 0 {
+			return c
+		}
+		return strings.Compare(b.ID.String(), a.ID.String())
+	})
+
+	size := page.Limit - 1
+	if len(transactions) > size {
+		transactions = transactions[:size]
+		last := transactions[size-1]
+		ledger.NextCursor = encodeExpenseCursor(expenseCursor{
+			Sort: repositories.ExpenseSortDateDesc,
+			Date: last.Date,
+			ID:   last.ID,
+		})
+	}
+
+	ledger.Transactions = transactions
+	ledger.NetCashFlow = ledger.TotalIncome - ledger.TotalExpense
+	return ledger, nil
+}
+
+// DeleteTransaction removes an income, transfer or expense and reverses its
+// effect on the budgets involved.
+func (s *TransactionService) DeleteTransaction(userID uuid.UUID, transactionID uuid.UUID) error {
+	handled := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		ledger := s.ledger(tx)
+
+		if income, err := s.incomeRepo.WithTx(tx).FindByIDForUpdate(transactionID); err == nil {
+			handled = true
+			if income.UserID != userID {
+				return ErrUnauthorized
+			}
+			if income.BudgetID == nil {
+				return s.incomeRepo.WithTx(tx).Delete(income.ID)
+			}
+
+			// The same role that recorded the income is needed to take it back
+			budgets, err := ledger.lock(time.Now(), *income.BudgetID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBudgetNotFound
+			}
+			if err != nil {
+				return err
+			}
+			budget := budgets[*income.BudgetID]
+			action := models.BudgetActionSpend
+			if income.TopUpBudget {
+				action = models.BudgetActionManage
+			}
+			if err := authorizeBudget(s.memberRepo.WithTx(tx), s.workspace, budget, userID, action); err != nil {
+				return err
+			}
+
+			if income.TopUpBudget {
+				if budget.Remaining() < income.Amount {
+					return ErrInsufficientBudget
+				}
+				if err := ledger.adjustAllocation(budget, -income.Amount); err != nil {
+					return err
+				}
+			}
+			return s.incomeRepo.WithTx(tx).Delete(income.ID)
+		}
+
+		if transfer, err := s.transferRepo.WithTx(tx).FindByIDForUpdate(transactionID); err == nil {
+			handled = true
+			if transfer.UserID != userID {
+				return ErrUnauthorized
+			}
+
+			budgets, err := ledger.lock(time.Now(), transfer.FromBudgetID, transfer.ToBudgetID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBudgetNotFound
+			}
+			if err != nil {
+				return err
+			}
+			from, to := budgets[transfer.FromBudgetID], budgets[transfer.ToBudgetID]
+			memberRepo := s.memberRepo.WithTx(tx)
+			for _, budget := range []*models.Budget{from, to} {
+				if err := authorizeBudget(memberRepo, s.workspace, budget, userID, models.BudgetActionManage); err != nil {
+					return err
+				}
+			}
+			if to.Remaining() < transfer.Amount {
+				return ErrInsufficientBudget
+			}
+			if err := ledger.adjustAllocation(to, -transfer.Amount); err != nil {
+				return err
+			}
+			if err := ledger.adjustAllocation(from, transfer.Amount); err != nil {
+				return err
+			}
+			return s.transferRepo.WithTx(tx).Delete(transfer.ID)
+		}
+
+		return nil
+	})
+	if err != nil || handled {
+		return err
+	}
+
+	err = s.expenseService.DeleteExpense(userID, transactionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("transaction not found")
+	}
+	return err
+}
+
+// releaseAllocations reverses what the incomes and transfers of a user added
+// to budgets of other users, before those rows are deleted together with the
+// account. Budgets the user still owns are deleted as well and are skipped.
+func (s *TransactionService) releaseAllocations(tx *gorm.DB, userID uuid.UUID) error {
+	ledger := s.ledger(tx)
+	now := time.Now()
+
+	adjust := func(budgetID uuid.UUID, delta models.Money) error {
+		budgets, err := ledger.lock(now, budgetID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		budget := budgets[budgetID]
+		if budget.UserID == userID {
+			return nil
+		}
+		return ledger.adjustAllocation(budget, delta)
+	}
+
+	incomes, err := s.incomeRepo.WithTx(tx).FindAllByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, income := range incomes {
+		if income.DeletedAt.Valid || !income.TopUpBudget || income.BudgetID == nil {
+			continue
+		}
+		if err := adjust(*income.BudgetID, -income.Amount); err != nil {
+			return err
+		}
+	}
+
+	transfers, err := s.transferRepo.WithTx(tx).FindAllByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, transfer := range transfers {
+		if transfer.DeletedAt.Valid {
+			continue
+		}
+		if err := adjust(transfer.ToBudgetID, -transfer.Amount); err != nil {
+			return err
+		}
+		if err := adjust(transfer.FromBudgetID, transfer.Amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toIncomeTransaction(income *models.Income) responses.TransactionResponse {
+	response := responses.TransactionResponse{
+		ID:          income.ID,
+		Type:        models.TransactionIncome,
+		Amount:      income.Amount,
+		Description: income.Description,
+		Date:        income.Date,
+		BudgetID:    income.BudgetID,
+		TopUpBudget: income.TopUpBudget,
+	}
+	if income.Budget != nil {
+		response.BudgetName = income.Budget.Name
+	}
+	return response
+}
+
+func toTransferTransaction(transfer *models.Transfer) responses.TransactionResponse {
+	fromID, toID := transfer.FromBudgetID, transfer.ToBudgetID
+	return responses.TransactionResponse{
+		ID:           transfer.ID,
+		Type:         models.TransactionTransfer,
+		Amount:       transfer.Amount,
+		Description:  transfer.Description,
+		Date:         transfer.Date,
+		BudgetID:     &fromID,
+		BudgetName:   transfer.FromBudget.Name,
+		ToBudgetID:   &toID,
+		ToBudgetName: transfer.ToBudget.Name,
+	}
+}
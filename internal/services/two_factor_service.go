@@ -0,0 +1,213 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount is how many recovery codes a user gets at a time
+const recoveryCodeCount = 10
+
+type TwoFactorService struct {
+	db               *gorm.DB
+	userRepo         *repositories.UserRepository
+	recoveryCodeRepo *repositories.RecoveryCodeRepository
+	authService      *AuthService
+	issuer           string
+}
+
+func NewTwoFactorService(db *gorm.DB, userRepo *repositories.UserRepository, recoveryCodeRepo *repositories.RecoveryCodeRepository, authService *AuthService, issuer string) *TwoFactorService {
+	return &TwoFactorService{
+		db:               db,
+		userRepo:         userRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		authService:      authService,
+		issuer:           issuer,
+	}
+}
+
+func (s *TwoFactorService) GetStatus(userID uuid.UUID) (*responses.TwoFactorStatusResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	response := &responses.TwoFactorStatusResponse{Enabled: user.TwoFactorEnabled()}
+	if response.Enabled {
+		response.RecoveryCodesLeft, err = s.recoveryCodeRepo.CountUnused(user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// Setup starts enrollment by generating a new secret. 2FA is not enforced
+// until the secret is confirmed with Enable, so calling Setup again simply
+// replaces an unconfirmed secret.
+func (s *TwoFactorService) Setup(userID uuid.UUID) (*responses.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TwoFactorEnabled() {
+		return nil, errors.New("two-factor authentication already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return &responses.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable confirms enrollment with a code from the authenticator app and
+// returns the first set of recovery codes. They are only shown this once.
+func (s *TwoFactorService) Enable(userID uuid.UUID, req *requests.EnableTwoFactorRequest) (*responses.RecoveryCodesResponse, error) {
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := s.userRepo.WithTx(tx)
+		user, err := userRepo.FindByIDForUpdate(userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+		if user.TwoFactorEnabled() {
+			return errors.New("two-factor authentication already enabled")
+		}
+		if user.TOTPSecret == "" {
+			return errors.New("two-factor setup not started")
+		}
+
+		step, ok := utils.ValidateTOTP(user.TOTPSecret, strings.TrimSpace(req.Code), time.Now(), user.TOTPLastStep)
+		if !ok {
+			return errors.New("invalid code")
+		}
+		now := time.Now()
+		user.TOTPEnabledAt = &now
+		user.TOTPLastStep = step
+		if err := userRepo.Update(user); err != nil {
+			return err
+		}
+
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &responses.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns 2FA off. It needs both the password and a second factor.
+func (s *TwoFactorService) Disable(userID uuid.UUID, req *requests.DisableTwoFactorRequest, ip, userAgent string) error {
+	if err := s.authService.verifyPassword(userID, req.Password, ip, userAgent); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := s.userRepo.WithTx(tx)
+		user, err := userRepo.FindByIDForUpdate(userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+		if !user.TwoFactorEnabled() {
+			return errors.New("two-factor authentication not enabled")
+		}
+		if err := s.authService.checkSecondFactor(tx, user, req.Code); err != nil {
+			return err
+		}
+
+		user.TOTPSecret = ""
+		user.TOTPEnabledAt = nil
+		user.TOTPLastStep = 0
+		if err := userRepo.Update(user); err != nil {
+			return err
+		}
+		return s.recoveryCodeRepo.WithTx(tx).DeleteByUserID(user.ID)
+	})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes, used or not.
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uuid.UUID, req *requests.RegenerateRecoveryCodesRequest) (*responses.RecoveryCodesResponse, error) {
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.userRepo.WithTx(tx).FindByIDForUpdate(userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+		if !user.TwoFactorEnabled() {
+			return errors.New("two-factor authentication not enabled")
+		}
+		if err := s.authService.checkSecondFactor(tx, user, req.Code); err != nil {
+			return err
+		}
+
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &responses.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *TwoFactorService) replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	stored := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		stored[i] = models.RecoveryCode{
+			ID:       uuid.New(),
+			UserID:   userID,
+			CodeHash: utils.HashToken(normalizeRecoveryCode(code)),
+		}
+	}
+
+	if err := s.recoveryCodeRepo.WithTx(tx).Replace(userID, stored); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code like "k7qm-3xwa".
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return code[:4] + "-" + code[4:], nil
+}
+
+// normalizeRecoveryCode ignores case, dashes and spaces, as users often
+// retype codes by hand.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkspaceScope is the workspace a request works in together with the
+// caller's role there. Services returned by InWorkspace only see data of
+// that workspace.
+type WorkspaceScope struct {
+	ID   uuid.UUID
+	Role models.WorkspaceRole
+}
+
+// budgetRole is the role the caller's workspace role grants on a budget.
+func (w WorkspaceScope) budgetRole(budget *models.Budget) models.BudgetRole {
+	if budget.WorkspaceID != w.ID {
+		return ""
+	}
+	return w.Role.BudgetRole()
+}
+
+// mayCreateBudgets reports whether the caller may add budgets to the
+// workspace. Services used outside of a request have no role and are not
+// restricted.
+func (w WorkspaceScope) mayCreateBudgets() bool {
+	return w.Role == "" || w.Role.BudgetRole().Allows(models.BudgetActionSpend)
+}
+
+// ErrWorkspaceNotFound is returned for workspaces the user is neither a
+// member nor a guest of.
+var ErrWorkspaceNotFound = errors.New("workspace not found")
+
+// WorkspaceService manages workspaces and their members, and resolves the
+// workspace each request works in.
+type WorkspaceService struct {
+	db               *gorm.DB
+	workspaceRepo    *repositories.WorkspaceRepository
+	memberRepo       *repositories.WorkspaceMemberRepository
+	budgetMemberRepo *repositories.BudgetMemberRepository
+	userRepo         *repositories.UserRepository
+}
+
+func NewWorkspaceService(db *gorm.DB, workspaceRepo *repositories.WorkspaceRepository, memberRepo *repositories.WorkspaceMemberRepository, budgetMemberRepo *repositories.BudgetMemberRepository, userRepo *repositories.UserRepository) *WorkspaceService {
+	return &WorkspaceService{
+		db:               db,
+		workspaceRepo:    workspaceRepo,
+		memberRepo:       memberRepo,
+		budgetMemberRepo: budgetMemberRepo,
+		userRepo:         userRepo,
+	}
+}
+
+// ResolveWorkspace returns the workspace a request of the user works in and
+// the user's role there. Without a workspace ID it is the user's personal
+// workspace, which is created on first use. Users who are no members but
+// were invited to a budget of the workspace get in as guests.
+func (s *WorkspaceService) ResolveWorkspace(userID uuid.UUID, workspaceID uuid.UUID) (uuid.UUID, models.WorkspaceRole, error) {
+	if workspaceID == uuid.Nil || workspaceID == userID {
+		if err := s.ensurePersonal(userID); err != nil {
+			return uuid.Nil, "", err
+		}
+		return userID, models.WorkspaceRoleOwner, nil
+	}
+
+	member, err := s.memberRepo.Find(workspaceID, userID)
+	if err == nil {
+		return workspaceID, member.Role, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return uuid.Nil, "", err
+	}
+
+	guest, err := s.budgetMemberRepo.ExistsInWorkspace(workspaceID, userID)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	if !guest {
+		return uuid.Nil, "", ErrWorkspaceNotFound
+	}
+	return workspaceID, models.WorkspaceRoleGuest, nil
+}
+
+// GetWorkspaces lists the workspaces the user is a member or guest of,
+// personal workspace first.
+func (s *WorkspaceService) GetWorkspaces(userID uuid.UUID) (*responses.WorkspaceListResponse, error) {
+	if err := s.ensurePersonal(userID); err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.memberRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	guestIDs, err := s.budgetMemberRepo.FindWorkspaceIDsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &responses.WorkspaceListResponse{Workspaces: []responses.WorkspaceResponse{}}
+	joined := make(map[uuid.UUID]bool, len(memberships))
+	for _, membership := range memberships {
+		joined[membership.WorkspaceID] = true
+		response.Workspaces = append(response.Workspaces, toWorkspaceResponse(&membership.Workspace, membership.Role))
+	}
+
+	var guestOnly []uuid.UUID
+	for _, id := range guestIDs {
+		if !joined[id] {
+			guestOnly = append(guestOnly, id)
+		}
+	}
+	guestWorkspaces, err := s.workspaceRepo.FindByIDs(guestOnly)
+	if err != nil {
+		return nil, err
+	}
+	for _, workspace := range guestWorkspaces {
+		response.Workspaces = append(response.Workspaces, toWorkspaceResponse(&workspace, models.WorkspaceRoleGuest))
+	}
+
+	sort.SliceStable(response.Workspaces, func(i, j int) bool {
+		a, b := response.Workspaces[i], response.Workspaces[j]
+		if a.Personal != b.Personal {
+			return a.Personal
+		}
+		return a.Name < b.Name
+	})
+	return response, nil
+}
+
+// CreateWorkspace creates a shared workspace with the user as its owner.
+func (s *WorkspaceService) CreateWorkspace(userID uuid.UUID, req *requests.CreateWorkspaceRequest) (*responses.WorkspaceResponse, error) {
+	workspace := &models.Workspace{
+		ID:   uuid.New(),
+		Name: req.Name,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.workspaceRepo.WithTx(tx).Create(workspace); err != nil {
+			return err
+		}
+		return s.memberRepo.WithTx(tx).Create(&models.WorkspaceMember{
+			ID:          uuid.New(),
+			WorkspaceID: workspace.ID,
+			UserID:      userID,
+			Role:        models.WorkspaceRoleOwner,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toWorkspaceResponse(workspace, models.WorkspaceRoleOwner)
+	return &response, nil
+}
+
+func (s *WorkspaceService) UpdateWorkspace(userID uuid.UUID, workspaceID uuid.UUID, req *requests.UpdateWorkspaceRequest) (*responses.WorkspaceResponse, error) {
+	if _, err := s.membership(userID, workspaceID, true); err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil {
+		return nil, errors.New("workspace not found")
+	}
+	workspace.Name = req.Name
+	if err := s.workspaceRepo.Update(workspace); err != nil {
+		return nil, err
+	}
+
+	response := toWorkspaceResponse(workspace, models.WorkspaceRoleOwner)
+	return &response, nil
+}
+
+// DeleteWorkspace removes a shared workspace. Budgets, incomes and
+// transfers have to be deleted first so nothing is lost by accident.
+func (s *WorkspaceService) DeleteWorkspace(userID uuid.UUID, workspaceID uuid.UUID) error {
+	if _, err := s.membership(userID, workspaceID, true); err != nil {
+		return err
+	}
+
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil {
+		return errors.New("workspace not found")
+	}
+	if workspace.Personal {
+		return errors.New("cannot delete a personal workspace")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		workspaceRepo := s.workspaceRepo.WithTx(tx)
+		hasRecords, err := workspaceRepo.HasRecords(workspaceID)
+		if err != nil {
+			return err
+		}
+		if hasRecords {
+			return errors.New("workspace is not empty")
+		}
+		return workspaceRepo.DeleteWithData(workspaceID)
+	})
+}
+
+// GetMembers lists the members of a workspace. Every member may see the list.
+func (s *WorkspaceService) GetMembers(userID uuid.UUID, workspaceID uuid.UUID) (*responses.WorkspaceMemberListResponse, error) {
+	if _, err := s.membership(userID, workspaceID, false); err != nil {
+		return nil, err
+	}
+
+	members, err := s.memberRepo.FindByWorkspaceID(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &responses.WorkspaceMemberListResponse{Members: []responses.WorkspaceMemberResponse{}}
+	for _, member := range members {
+		response.Members = append(response.Members, toWorkspaceMemberResponse(&member, &member.User))
+	}
+	return response, nil
+}
+
+// AddMember gives a registered user access to a shared workspace.
+func (s *WorkspaceService) AddMember(userID uuid.UUID, workspaceID uuid.UUID, req *requests.AddWorkspaceMemberRequest) (*responses.WorkspaceMemberResponse, error) {
+	if _, err := s.membership(userID, workspaceID, true); err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil {
+		return nil, errors.New("workspace not found")
+	}
+	if workspace.Personal {
+		return nil, errors.New("personal workspaces cannot be shared")
+	}
+
+	user, err := s.userRepo.FindByEmail(normalizeEmail(req.Email))
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if _, err := s.memberRepo.Find(workspaceID, user.ID); err == nil {
+		return nil, errors.New("already a member")
+	}
+
+	member := &models.WorkspaceMember{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		UserID:      user.ID,
+		Role:        req.Role,
+	}
+	if err := s.memberRepo.Create(member); err != nil {
+		return nil, err
+	}
+
+	response := toWorkspaceMemberResponse(member, user)
+	return &response, nil
+}
+
+func (s *WorkspaceService) UpdateMemberRole(userID uuid.UUID, workspaceID uuid.UUID, memberID uuid.UUID, req *requests.UpdateWorkspaceMemberRequest) (*responses.WorkspaceMemberResponse, error) {
+	if _, err := s.membership(userID, workspaceID, true); err != nil {
+		return nil, err
+	}
+
+	var member *models.WorkspaceMember
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		memberRepo := s.memberRepo.WithTx(tx)
+		var err error
+		member, err = memberRepo.Find(workspaceID, memberID)
+		if err != nil {
+			return errors.New("member not found")
+		}
+		if member.Role == models.WorkspaceRoleOwner && req.Role != models.WorkspaceRoleOwner {
+			if err := keepOwner(memberRepo, workspaceID); err != nil {
+				return err
+			}
+		}
+
+		member.Role = req.Role
+		return memberRepo.Update(member)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(memberID)
+	if err != nil {
+		return nil, err
+	}
+	response := toWorkspaceMemberResponse(member, user)
+	return &response, nil
+}
+
+// RemoveMember takes a member off a workspace. Owners may remove anyone;
+// other members may only remove themselves. What the member recorded stays
+// in the workspace.
+func (s *WorkspaceService) RemoveMember(userID uuid.UUID, workspaceID uuid.UUID, memberID uuid.UUID) error {
+	if _, err := s.membership(userID, workspaceID, memberID != userID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		memberRepo := s.memberRepo.WithTx(tx)
+		member, err := memberRepo.Find(workspaceID, memberID)
+		if err != nil {
+			return errors.New("member not found")
+		}
+		if member.Role == models.WorkspaceRoleOwner {
+			if err := keepOwner(memberRepo, workspaceID); err != nil {
+				return err
+			}
+		}
+		return memberRepo.Delete(workspaceID, memberID)
+	})
+}
+
+// membership checks that the user is a member of the workspace and, if
+// requested, one of its owners.
+func (s *WorkspaceService) membership(userID uuid.UUID, workspaceID uuid.UUID, owner bool) (*models.WorkspaceMember, error) {
+	member, err := s.memberRepo.Find(workspaceID, userID)
+	if err != nil {
+		return nil, errors.New("workspace not found")
+	}
+	if owner && member.Role != models.WorkspaceRoleOwner {
+		return nil, errors.New("unauthorized")
+	}
+	return member, nil
+}
+
+// ensurePersonal creates the personal workspace of a user unless it exists.
+func (s *WorkspaceService) ensurePersonal(userID uuid.UUID) error {
+	if _, err := s.memberRepo.Find(userID, userID); err == nil {
+		return nil
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.workspaceRepo.WithTx(tx).Create(&models.Workspace{ID: userID, Name: user.Name, Personal: true}); err != nil {
+			return err
+		}
+		return s.memberRepo.WithTx(tx).Create(&models.WorkspaceMember{
+			ID:          uuid.New(),
+			WorkspaceID: userID,
+			UserID:      userID,
+			Role:        models.WorkspaceRoleOwner,
+		})
+	})
+	// A concurrent request may have created it first
+	if err != nil {
+		if _, found := s.memberRepo.Find(userID, userID); found == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// keepOwner fails when the workspace would be left without an owner.
+func keepOwner(memberRepo *repositories.WorkspaceMemberRepository, workspaceID uuid.UUID) error {
+	owners, err := memberRepo.CountOwners(workspaceID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return errors.New("workspace needs an owner")
+	}
+	return nil
+}
+
+func toWorkspaceResponse(workspace *models.Workspace, role models.WorkspaceRole) responses.WorkspaceResponse {
+	return responses.WorkspaceResponse{
+		ID:        workspace.ID,
+		Name:      workspace.Name,
+		Personal:  workspace.Personal,
+		Role:      role,
+		CreatedAt: workspace.CreatedAt,
+	}
+}
+
+func toWorkspaceMemberResponse(member *models.WorkspaceMember, user *models.User) responses.WorkspaceMemberResponse {
+	return responses.WorkspaceMemberResponse{
+		UserID:   user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		Role:     member.Role,
+		JoinedAt: member.CreatedAt,
+	}
+}
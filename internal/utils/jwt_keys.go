@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// KeySet holds the key tokens are signed with and every key a token may be
+// verified with. During a rotation the previous public keys stay in the set
+// until the tokens they signed have expired.
+type KeySet struct {
+	method     jwt.SigningMethod
+	signingKey any
+	signingKID string
+	verifiers  map[string]verificationKey
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    any
+}
+
+// NewHMACKeySet creates a key set that signs and verifies with a shared secret.
+func NewHMACKeySet(secret string) (*KeySet, error) {
+	if secret == "" {
+		return nil, errors.New("JWT_SECRET is required for HS256")
+	}
+	return &KeySet{
+		method:     jwt.SigningMethodHS256,
+		signingKey: []byte(secret),
+		verifiers:  map[string]verificationKey{"": {method: jwt.SigningMethodHS256, key: []byte(secret)}},
+	}, nil
+}
+
+// LoadKeySet reads a PEM private key to sign with and optional PEM public
+// keys that are still accepted. A key ID defaults to the thumbprint of its
+// public key; a verification entry may set one explicitly as "kid=path".
+func LoadKeySet(algorithm, signingKeyFile, keyID string, verificationKeyFiles []string) (*KeySet, error) {
+	if signingKeyFile == "" {
+		return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE is required for %s", algorithm)
+	}
+
+	privateKey, err := readPrivateKey(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	method, publicKey, err := methodFor(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if method.Alg() != algorithm {
+		return nil, fmt.Errorf("%s holds a %s key, not %s", signingKeyFile, method.Alg(), algorithm)
+	}
+	if keyID == "" {
+		if keyID, err = thumbprint(publicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := &KeySet{
+		method:     method,
+		signingKey: privateKey,
+		signingKID: keyID,
+		verifiers:  map[string]verificationKey{keyID: {method: method, key: publicKey}},
+	}
+
+	for _, entry := range verificationKeyFiles {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, path, hasKID := strings.Cut(entry, "=")
+		if !hasKID {
+			kid, path = "", entry
+		}
+
+		publicKey, err := readPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		method, _, err := methodFor(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		if kid == "" {
+			if kid, err = thumbprint(publicKey); err != nil {
+				return nil, err
+			}
+		}
+		keys.verifiers[kid] = verificationKey{method: method, key: publicKey}
+	}
+
+	return keys, nil
+}
+
+// Sign signs the claims with the current signing key.
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.signingKID != "" {
+		token.Header["kid"] = k.signingKID
+	}
+	return token.SignedString(k.signingKey)
+}
+
+// keyFunc picks the verification key named by the kid header and refuses a
+// token whose algorithm does not match that key.
+func (k *KeySet) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	verifier, ok := k.verifiers[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != verifier.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return verifier.key, nil
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the public verification keys. Shared HMAC secrets are never
+// published, so an HS256 key set returns no keys.
+func (k *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for kid, verifier := range k.verifiers {
+		switch key := verifier.key.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: AlgorithmRS256,
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: AlgorithmEdDSA,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+
+	// Signing key first, the rest in a stable order
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		if (jwks.Keys[i].Kid == k.signingKID) != (jwks.Keys[j].Kid == k.signingKID) {
+			return jwks.Keys[i].Kid == k.signingKID
+		}
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
+}
+
+func methodFor(key any) (jwt.SigningMethod, crypto.PublicKey, error) {
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, &key.PublicKey, nil
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, key, nil
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, key.Public(), nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, key, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM block", path)
+	}
+	return block, nil
+}
+
+func readPrivateKey(path string) (any, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+// readPublicKey also accepts a private key file and uses its public half.
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		privateKey, err := readPrivateKey(path)
+		if err != nil {
+			return nil, err
+		}
+		_, publicKey, err := methodFor(privateKey)
+		return publicKey, err
+	}
+}
+
+// thumbprint derives a stable key ID from the DER encoded public key.
+func thumbprint(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12]), nil
+}
@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -8,34 +12,65 @@ import (
 	"github.com/google/uuid"
 )
 
+// Token audiences. Every token is signed with the same keys, so anyone
+// verifying tokens against the JWKS must check that aud is the access token
+// audience; MFA tokens only prove the password and are good for nothing else.
+const (
+	AccessTokenAudience = "dompet-api"
+	MFATokenAudience    = "dompet-mfa"
+)
+
 type JWTClaims struct {
 	UserID uuid.UUID `json:"user_id"`
 	Email  string    `json:"email"`
+	// Limited marks a token of an unverified account that may only read
+	Limited bool `json:"limited,omitempty"`
+	// TokenVersion is the user's session generation at issue time; revoking
+	// every session bumps the user's version and so rejects this token
+	TokenVersion int `json:"token_version"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uuid.UUID, email string, secret string, expiration time.Duration) (string, error) {
-	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+func GenerateToken(userID uuid.UUID, email string, keys *KeySet, expiration time.Duration) (string, error) {
+	return GenerateTokenWithClaims(JWTClaims{UserID: userID, Email: email}, keys, expiration)
+}
+
+// GenerateTokenWithClaims signs the given claims as an access token after
+// setting a fresh jti, the issue time and the expiry.
+func GenerateTokenWithClaims(claims JWTClaims, keys *KeySet, expiration time.Duration) (string, error) {
+	return signToken(claims, AccessTokenAudience, keys, expiration)
+}
+
+// GenerateMFAToken signs a token that can only be exchanged for a session
+// together with a second factor.
+func GenerateMFAToken(userID uuid.UUID, email string, keys *KeySet, expiration time.Duration) (string, error) {
+	return signToken(JWTClaims{UserID: userID, Email: email}, MFATokenAudience, keys, expiration)
+}
+
+func signToken(claims JWTClaims, audience string, keys *KeySet, expiration time.Duration) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiration)),
+		IssuedAt:  jwt.NewNumericDate(now),
 	}
+	return keys.Sign(claims)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+// ValidateToken parses an access token. Tokens of any other audience, such
+// as MFA tokens, are rejected.
+func ValidateToken(tokenString string, keys *KeySet) (*JWTClaims, error) {
+	return parseToken(tokenString, AccessTokenAudience, keys)
 }
 
-func ValidateToken(tokenString string, secret string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secret), nil
-	})
+// ValidateMFAToken parses a token issued by GenerateMFAToken.
+func ValidateMFAToken(tokenString string, keys *KeySet) (*JWTClaims, error) {
+	return parseToken(tokenString, MFATokenAudience, keys)
+}
 
+func parseToken(tokenString string, audience string, keys *KeySet) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, keys.keyFunc, jwt.WithAudience(audience))
 	if err != nil {
 		return nil, err
 	}
@@ -46,3 +81,31 @@ func ValidateToken(tokenString string, secret string) (*JWTClaims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// GenerateRandomToken returns a random opaque token, e.g. a refresh token.
+func GenerateRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex SHA-256 of a token, which is what gets stored.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyPrefix starts every API key, so it can be told apart from a JWT and
+// found by secret scanners.
+const APIKeyPrefix = "dpk_"
+
+// GenerateAPIKey returns a new random API key.
+func GenerateAPIKey() (string, error) {
+	token, err := GenerateRandomToken()
+	if err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + token, nil
+}
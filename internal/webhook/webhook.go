@@ -0,0 +1,178 @@
+// Package webhook posts signed JSON events to user-configured URLs.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Headers sent with every webhook. The signature covers the timestamp and
+// the body, so receivers can reject replayed or altered requests.
+const (
+	HeaderEvent     = "X-Dompet-Event"
+	HeaderDelivery  = "X-Dompet-Delivery"
+	HeaderTimestamp = "X-Dompet-Timestamp"
+	HeaderSignature = "X-Dompet-Signature"
+)
+
+type Message struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+// ErrBlockedAddress is returned for webhook URLs that point into loopback,
+// private or otherwise internal networks.
+var ErrBlockedAddress = errors.New("webhook target address is not allowed")
+
+// Sender posts messages over HTTP.
+type Sender struct {
+	Client *http.Client
+	// AllowPrivate lets CheckURL accept internal hosts, for tests and local
+	// development only
+	AllowPrivate bool
+}
+
+// NewSender returns a sender for user-supplied URLs. Its client checks every
+// address it connects to after DNS resolution, so a host name cannot be used
+// to reach internal networks, and it does not follow redirects.
+func NewSender(timeout time.Duration) *Sender {
+	dialer := &net.Dialer{Timeout: timeout, Control: refuseInternal}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would make the dialer check the proxy instead of the target
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Sender{Client: &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// CheckURL rejects URLs that are not http(s) or whose host is an internal
+// address or a localhost name. Host names are resolved at send time.
+func (s *Sender) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrBlockedAddress
+	}
+	if s.AllowPrivate {
+		return nil
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrBlockedAddress
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && isInternal(addr) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// Send posts the message and returns the status code of the response. Any
+// status outside 2xx is reported as an error together with its code.
+func (s *Sender) Send(msg Message, now time.Time) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, msg.URL, bytes.NewReader(msg.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Dompet-Webhook/1.0")
+	req.Header.Set(HeaderEvent, msg.Event)
+	req.Header.Set(HeaderDelivery, msg.DeliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, "sha256="+Sign(msg.Secret, timestamp, msg.Body))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		// Do not tell which internal address the host resolved to
+		if errors.Is(err, ErrBlockedAddress) {
+			return 0, ErrBlockedAddress
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" under secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header value as sent by Sender.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	expected := "sha256=" + Sign(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// internalPrefixes are ranges not covered by the netip helpers that must not
+// be reachable either: shared address space (used by some cloud metadata
+// services), IETF protocol assignments, benchmarking and reserved ranges,
+// and NAT64 which can map to any of them.
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+func isInternal(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsMulticast() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// refuseInternal is a net.Dialer Control func that refuses connections to
+// internal addresses. It runs after resolution, for every address tried.
+func refuseInternal(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return ErrBlockedAddress
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || isInternal(addr) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
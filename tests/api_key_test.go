@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/middlewares"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyScopes(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	keys := testKeys(tokenTestSecret)
+	authService := newTestAuthService(db, keys, &recordingMailer{})
+	apiKeyService := services.NewAPIKeyService(repositories.NewAPIKeyRepository(db))
+
+	_, err := apiKeyService.CreateAPIKey(user.ID, &requests.CreateAPIKeyRequest{Name: "salah", Scopes: []string{"expenses:delete"}})
+	assert.EqualError(t, err, "invalid scope")
+
+	created, err := apiKeyService.CreateAPIKey(user.ID, &requests.CreateAPIKeyRequest{
+		Name:   "Skrip laporan",
+		Scopes: []string{models.ScopeBudgetsRead, models.ScopeExpensesRead, models.ScopeBudgetsRead},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, "dpk_"))
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+	assert.Equal(t, []string{models.ScopeBudgetsRead, models.ScopeExpensesRead}, created.Scopes)
+
+	var stored models.APIKey
+	require.NoError(t, db.First(&stored, "id = ?", created.ID).Error)
+	assert.Equal(t, utils.HashToken(created.Key), stored.KeyHash, "kunci hanya disimpan sebagai hash")
+
+	e := echo.New()
+	ok := func(c echo.Context) error {
+		assert.Equal(t, user.ID, c.Get("user_id"))
+		return c.NoContent(http.StatusOK)
+	}
+	g := e.Group("", middlewares.AuthMiddleware(keys, authService, apiKeyService))
+	g.GET("/budgets", ok, middlewares.RequireScope(models.ScopeBudgetsRead))
+	g.POST("/budgets", ok, middlewares.RequireScope(models.ScopeBudgetsWrite))
+	g.GET("/me", ok, middlewares.RequireSession())
+
+	call := func(method, path, credential string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+credential)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/budgets", created.Key))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/budgets", created.Key), "scope budgets:write tidak diberikan")
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/me", created.Key), "kunci API tidak bisa mengelola akun")
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/budgets", created.Key+"x"))
+
+	// JWT biasa tidak dibatasi scope
+	token, err := utils.GenerateToken(user.ID, user.Email, keys, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, "/budgets", token))
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/me", token))
+
+	// Token mfa tidak berlaku sebagai sesi
+	mfaToken, err := utils.GenerateMFAToken(user.ID, user.Email, keys, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/budgets", mfaToken))
+
+	listed, err := apiKeyService.GetAPIKeys(user.ID)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.NotNil(t, listed[0].LastUsedAt)
+
+	assert.EqualError(t, apiKeyService.RevokeAPIKey(uuid.New(), created.ID), "api key not found", "hanya pemilik yang bisa mencabut")
+	require.NoError(t, apiKeyService.RevokeAPIKey(user.ID, created.ID))
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/budgets", created.Key))
+
+	expiring, err := apiKeyService.CreateAPIKey(user.ID, &requests.CreateAPIKeyRequest{
+		Name:      "Sementara",
+		Scopes:    []string{models.ScopeBudgetsRead},
+		ExpiresAt: func() *time.Time { at := time.Now().Add(time.Hour); return &at }(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/budgets", expiring.Key))
+	require.NoError(t, db.Model(&models.APIKey{}).Where("id = ?", expiring.ID).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/budgets", expiring.Key), "kunci kedaluwarsa ditolak")
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/controllers"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/mailer"
+	"github.com/Alvarras/dompet-g0/internal/middlewares"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const tokenTestSecret = "token-test-secret"
+
+// testKeys membuat key set HS256 untuk tes.
+func testKeys(secret string) *utils.KeySet {
+	keys, err := utils.NewHMACKeySet(secret)
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}
+
+// testIP adalah alamat klien untuk login di tes layanan.
+const testIP = "192.0.2.10"
+
+// testLoginPolicy mengunci akun setelah 5 kegagalan tanpa jeda antar percobaan.
+var testLoginPolicy = services.LoginPolicy{MaxFailures: 5, LockoutDuration: 15 * time.Minute, Window: 15 * time.Minute}
+
+// newTestAuthService membuat AuthService dengan access token 15 menit dan refresh token 1 jam.
+func newTestAuthService(db *gorm.DB, keys *utils.KeySet, mail mailer.Mailer) *services.AuthService {
+	return newTestAuthServiceWithPolicy(db, keys, mail, services.UnverifiedAllow)
+}
+
+func newTestAuthServiceWithPolicy(db *gorm.DB, keys *utils.KeySet, mail mailer.Mailer, policy services.UnverifiedPolicy) *services.AuthService {
+	return newTestAuthServiceWithLoginPolicy(db, keys, mail, policy, testLoginPolicy)
+}
+
+func newTestAuthServiceWithLoginPolicy(db *gorm.DB, keys *utils.KeySet, mail mailer.Mailer, policy services.UnverifiedPolicy, login services.LoginPolicy) *services.AuthService {
+	return services.NewAuthService(db, repositories.NewUserRepository(db), repositories.NewRefreshTokenRepository(db),
+		repositories.NewRevokedTokenRepository(db), repositories.NewUserTokenRepository(db), repositories.NewRecoveryCodeRepository(db),
+		repositories.NewLoginAttemptRepository(db), mail, keys, 15*time.Minute, time.Hour, "https://app.example.com/", policy, login)
+}
+
+func setupAuthServer(t *testing.T) (*echo.Echo, string) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password", string(hashed)).Error)
+
+	keys := testKeys(tokenTestSecret)
+	authService := newTestAuthService(db, keys, mailer.LogMailer{})
+	authController := controllers.NewAuthController(authService)
+
+	e := echo.New()
+	e.POST("/login", authController.Login)
+	e.POST("/token/refresh", authController.Refresh)
+	protected := e.Group("", middlewares.AuthMiddleware(keys, authService, services.NewAPIKeyService(repositories.NewAPIKeyRepository(db))))
+	protected.POST("/logout", authController.Logout)
+	protected.GET("/ping", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	return e, user.Email
+}
+
+func postJSON(e *echo.Echo, path, token string, body any) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(payload))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func ping(e *echo.Echo, token string) int {
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+// authTokens membaca token dari respons login atau refresh.
+func authTokens(t *testing.T, rec *httptest.ResponseRecorder) responses.AuthResponse {
+	t.Helper()
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body struct {
+		Data responses.AuthResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.NotEmpty(t, body.Data.Token)
+	require.NotEmpty(t, body.Data.RefreshToken)
+	return body.Data
+}
+
+func TestRefreshTokenRotationAndReuse(t *testing.T) {
+	e, email := setupAuthServer(t)
+
+	login := authTokens(t, postJSON(e, "/login", "", map[string]string{"email": email, "password": "password123"}))
+	assert.Equal(t, int64(900), login.ExpiresIn)
+	assert.Equal(t, http.StatusOK, ping(e, login.Token))
+
+	rotated := authTokens(t, postJSON(e, "/token/refresh", "", map[string]string{"refresh_token": login.RefreshToken}))
+	assert.NotEqual(t, login.RefreshToken, rotated.RefreshToken)
+	assert.Equal(t, http.StatusOK, ping(e, rotated.Token))
+
+	// Refresh token lama dipakai ulang: seluruh keluarga token dicabut
+	rec := postJSON(e, "/token/refresh", "", map[string]string{"refresh_token": login.RefreshToken})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "AUTH_010")
+
+	rec = postJSON(e, "/token/refresh", "", map[string]string{"refresh_token": rotated.RefreshToken})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "token terbaru ikut dicabut")
+
+	rec = postJSON(e, "/token/refresh", "", map[string]string{"refresh_token": "bukan-token"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "AUTH_009")
+}
+
+func TestLogoutRevokesAccessAndRefreshToken(t *testing.T) {
+	e, email := setupAuthServer(t)
+
+	login := authTokens(t, postJSON(e, "/login", "", map[string]string{"email": email, "password": "password123"}))
+	other := authTokens(t, postJSON(e, "/login", "", map[string]string{"email": email, "password": "password123"}))
+
+	rec := postJSON(e, "/logout", login.Token, map[string]string{"refresh_token": login.RefreshToken})
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	assert.Equal(t, http.StatusUnauthorized, ping(e, login.Token), "jti masuk denylist")
+	rec = postJSON(e, "/token/refresh", "", map[string]string{"refresh_token": login.RefreshToken})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Sesi lain tidak terpengaruh
+	assert.Equal(t, http.StatusOK, ping(e, other.Token))
+	authTokens(t, postJSON(e, "/token/refresh", "", map[string]string{"refresh_token": other.RefreshToken}))
+}
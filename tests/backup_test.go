@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/backup"
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupRestoreIntoAnotherAccount(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	source := createTestUser(t, db)
+	target := createTestUser(t, db)
+	require.NoError(t, db.Model(source).Update("name", "Sumber").Error)
+	budget := createTestBudget(t, db, source.ID, 1000*models.MoneyScale)
+
+	categoryRepo := repositories.NewCategoryRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
+	categoryService := services.NewCategoryService(db, categoryRepo, expenseRepo, tagRepo)
+	backupService := services.NewBackupService(db, repositories.NewUserRepository(db), budgetRepo, expenseRepo,
+		repositories.NewBudgetHistoryRepository(db), categoryRepo, tagRepo, repositories.NewIncomeRepository(db),
+		repositories.NewTransferRepository(db), repositories.NewImportRuleRepository(db), repositories.NewBudgetAlertRepository(db))
+
+	food, err := categoryService.CreateCategory(source.ID, &requests.CreateCategoryRequest{Name: "Makanan"})
+	require.NoError(t, err)
+	coffee, err := categoryService.CreateCategory(source.ID, &requests.CreateCategoryRequest{Name: "Kopi", ParentID: &food.ID})
+	require.NoError(t, err)
+
+	kept, err := expenseService.CreateExpense(source.ID, &requests.CreateExpenseRequest{
+		BudgetID: budget.ID, CategoryID: &coffee.ID, Amount: 25 * models.MoneyScale, Tags: []string{"pagi"},
+	})
+	require.NoError(t, err)
+	removed, err := expenseService.CreateExpense(source.ID, &requests.CreateExpenseRequest{
+		BudgetID: budget.ID, Amount: 10 * models.MoneyScale,
+	})
+	require.NoError(t, err)
+	require.NoError(t, expenseService.DeleteExpense(source.ID, removed.ID))
+
+	bundle, err := backupService.Backup(source.ID)
+	require.NoError(t, err)
+	assert.Equal(t, backup.Version, bundle.Version)
+	require.Len(t, bundle.Expenses, 2, "pengeluaran yang dihapus ikut dicadangkan")
+
+	// Bundle harus selamat melewati JSON
+	data, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	var decoded backup.Bundle
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	result, err := backupService.Restore(target.ID, &decoded, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Budgets)
+	assert.Equal(t, 2, result.Categories)
+	assert.Equal(t, 2, result.Expenses)
+
+	restoredUser, err := repositories.NewUserRepository(db).FindByID(target.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target.Name, restoredUser.Name, "profil akun tujuan tidak ditimpa")
+
+	budgets, err := budgetRepo.FindByUserID(target.ID)
+	require.NoError(t, err)
+	require.Len(t, budgets, 1)
+	assert.NotEqual(t, budget.ID, budgets[0].ID, "restore selalu memberi ID baru")
+	restored := assertBudgetConsistent(t, budgetRepo, expenseRepo, budgets[0].ID)
+	assert.Equal(t, models.Money(25*models.MoneyScale), restored.Spent)
+
+	all, err := expenseRepo.FindAllByUserID(target.ID)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	var deleted int
+	var restoredCategory *models.Category
+	for _, expense := range all {
+		assert.NotEqual(t, kept.ID, expense.ID)
+		if expense.DeletedAt.Valid {
+			deleted++
+			continue
+		}
+		require.NotNil(t, expense.CategoryID)
+		assert.NotEqual(t, coffee.ID, *expense.CategoryID)
+		restoredCategory, err = categoryRepo.FindByID(*expense.CategoryID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pagi"}, tagNamesOf(expense.Tags))
+	}
+	assert.Equal(t, 1, deleted, "status terhapus dipertahankan")
+
+	require.NotNil(t, restoredCategory)
+	assert.Equal(t, "Kopi", restoredCategory.Name)
+	require.NotNil(t, restoredCategory.ParentID, "hierarki kategori dipertahankan")
+	assert.NotEqual(t, food.ID, *restoredCategory.ParentID)
+}
+
+func TestRestoreBudgetNameConflicts(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	user := createTestUser(t, db)
+	budget := createTestBudget(t, db, user.ID, 1000*models.MoneyScale)
+
+	categoryRepo := repositories.NewCategoryRepository(db)
+	backupService := services.NewBackupService(db, repositories.NewUserRepository(db), budgetRepo, expenseRepo,
+		repositories.NewBudgetHistoryRepository(db), categoryRepo, repositories.NewTagRepository(db), repositories.NewIncomeRepository(db),
+		repositories.NewTransferRepository(db), repositories.NewImportRuleRepository(db), repositories.NewBudgetAlertRepository(db))
+
+	_, err := expenseService.CreateExpense(user.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: 40 * models.MoneyScale})
+	require.NoError(t, err)
+
+	bundle, err := backupService.Backup(user.ID)
+	require.NoError(t, err)
+
+	result, err := backupService.Restore(user.ID, bundle, services.RestoreConflictSkip)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.SkippedBudgets)
+	assert.Equal(t, 0, result.Expenses, "pengeluaran budget yang dilewati ikut dilewati")
+
+	// Top-up dan transfer tidak mengubah alokasi budget yang di-merge
+	other := uuid.New()
+	withAllocations := *bundle
+	withAllocations.Budgets = append(append([]backup.Budget(nil), bundle.Budgets...), backup.Budget{ID: other, Name: "Tabungan", Amount: 500 * models.MoneyScale})
+	withAllocations.Incomes = []backup.Income{{BudgetID: &budget.ID, TopUpBudget: true, Amount: 300 * models.MoneyScale, Date: time.Now()}}
+	withAllocations.Transfers = []backup.Transfer{{FromBudgetID: other, ToBudgetID: budget.ID, Amount: 100 * models.MoneyScale, Date: time.Now()}}
+
+	result, err = backupService.Restore(user.ID, &withAllocations, services.RestoreConflictMerge)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.MergedBudgets)
+	assert.Equal(t, 1, result.Expenses)
+	assert.Equal(t, 1, result.Incomes)
+	assert.Equal(t, 0, result.Transfers)
+	merged := assertBudgetConsistent(t, budgetRepo, expenseRepo, budget.ID)
+	assert.Equal(t, models.Money(80*models.MoneyScale), merged.Spent)
+	assert.Equal(t, budget.Amount, merged.Amount)
+	incomes, err := repositories.NewIncomeRepository(db).FindAllByUserID(user.ID)
+	require.NoError(t, err)
+	require.Len(t, incomes, 1)
+	assert.False(t, incomes[0].TopUpBudget)
+	require.NoError(t, db.Delete(&models.Budget{}, "name = ? AND user_id = ?", "Tabungan", user.ID).Error)
+
+	result, err = backupService.Restore(user.ID, bundle, services.RestoreConflictRename)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Budgets)
+	budgets, err := budgetRepo.FindByUserID(user.ID)
+	require.NoError(t, err)
+	require.Len(t, budgets, 2)
+	names := []string{budgets[0].Name, budgets[1].Name}
+	assert.Contains(t, names, budget.Name+" (restored)")
+
+	_, err = backupService.Restore(user.ID, bundle, "replace")
+	assert.EqualError(t, err, "invalid restore conflict")
+
+	// Bundle yang tidak valid ditolak utuh, tanpa membuat server panik
+	start := time.Now()
+	broken := *bundle
+	broken.Budgets = []backup.Budget{{ID: uuid.New(), Name: "Rusak", Amount: 100 * models.MoneyScale, Period: models.BudgetPeriodMonthly, PeriodAnchor: &start, PeriodStart: &start}}
+	_, err = backupService.Restore(user.ID, &broken, services.RestoreConflictRename)
+	assert.EqualError(t, err, "invalid budget period")
+	broken.Budgets = []backup.Budget{{ID: uuid.New(), Name: "Rusak", Amount: 100 * models.MoneyScale, RolloverPolicy: "keep"}}
+	_, err = backupService.Restore(user.ID, &broken, services.RestoreConflictRename)
+	assert.EqualError(t, err, "invalid rollover policy")
+	broken = *bundle
+	broken.Expenses = append([]backup.Expense(nil), bundle.Expenses...)
+	broken.Expenses[0].Status = "paid"
+	_, err = backupService.Restore(user.ID, &broken, services.RestoreConflictRename)
+	assert.EqualError(t, err, "invalid expense status")
+	budgets, err = budgetRepo.FindByUserID(user.ID)
+	require.NoError(t, err)
+	assert.Len(t, budgets, 2)
+
+	bundle.Version = backup.Version + 1
+	_, err = backupService.Restore(user.ID, bundle, "")
+	assert.EqualError(t, err, "unsupported backup version")
+}
+
+func tagNamesOf(tags []models.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
@@ -0,0 +1,312 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/Alvarras/dompet-g0/internal/webhook"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedWebhook struct {
+	Event     string
+	Threshold int
+	Spent     models.Money
+	Valid     bool
+}
+
+// webhookReceiver mencatat webhook yang masuk dan memeriksa tanda tangannya
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secrets  map[string]string
+	received []receivedWebhook
+}
+
+func (r *webhookReceiver) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		timestamp, err := strconv.ParseInt(req.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+
+		var payload struct {
+			AlertID   string `json:"alert_id"`
+			Threshold int    `json:"threshold"`
+			Budget    struct {
+				Spent models.Money `json:"spent"`
+			} `json:"budget"`
+		}
+		require.NoError(t, json.Unmarshal(body, &payload))
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.received = append(r.received, receivedWebhook{
+			Event:     req.Header.Get(webhook.HeaderEvent),
+			Threshold: payload.Threshold,
+			Spent:     payload.Budget.Spent,
+			Valid:     webhook.Verify(r.secrets[payload.AlertID], timestamp, body, req.Header.Get(webhook.HeaderSignature)),
+		})
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (r *webhookReceiver) all() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.received...)
+}
+
+func TestBudgetAlertWebhooks(t *testing.T) {
+	db, expenseService, budgetRepo, _ := setupExpenseService(t)
+	receiver := &webhookReceiver{secrets: map[string]string{}}
+	server := httptest.NewServer(receiver.handler(t))
+	defer server.Close()
+
+	// Penerima uji berjalan di loopback, yang ditolak oleh NewSender
+	alertRepo := repositories.NewBudgetAlertRepository(db)
+	policy := services.WebhookPolicy{MaxAttempts: 3, BackoffBase: time.Minute, BackoffMax: time.Hour, Timeout: 5 * time.Second}
+	sender := &webhook.Sender{Client: server.Client(), AllowPrivate: true}
+	alertService := services.NewBudgetAlertService(db, alertRepo, budgetRepo, repositories.NewBudgetMemberRepository(db), sender, policy)
+
+	owner := createTestUser(t, db)
+	outsider := createTestUser(t, db)
+	personal := services.WorkspaceScope{ID: owner.ID, Role: models.WorkspaceRoleOwner}
+	budget := &models.Budget{ID: uuid.New(), WorkspaceID: owner.ID, UserID: owner.ID, Name: "Belanja", Amount: 1000 * models.MoneyScale}
+	require.NoError(t, db.Create(budget).Error)
+	scoped := alertService.InWorkspace(personal)
+	expenses := expenseService.InWorkspace(personal)
+
+	alertIDs := map[int]uuid.UUID{}
+	for _, threshold := range []int{50, 80, 100} {
+		created, err := scoped.CreateAlert(owner.ID, budget.ID, &requests.CreateBudgetAlertRequest{Threshold: threshold, URL: server.URL + "/hook"})
+		require.NoError(t, err)
+		assert.True(t, created.Active)
+		assert.NotEmpty(t, created.Secret)
+		receiver.secrets[created.ID.String()] = created.Secret
+		alertIDs[threshold] = created.ID
+	}
+	down, err := scoped.CreateAlert(owner.ID, budget.ID, &requests.CreateBudgetAlertRequest{Threshold: 50, URL: server.URL + "/down"})
+	require.NoError(t, err)
+
+	_, err = alertService.InWorkspace(services.WorkspaceScope{ID: outsider.ID, Role: models.WorkspaceRoleOwner}).GetAlerts(outsider.ID, budget.ID)
+	assert.EqualError(t, err, "budget not found")
+
+	spend := func(amount models.Money) uuid.UUID {
+		t.Helper()
+		expense, err := expenses.CreateExpense(owner.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: amount * models.MoneyScale})
+		require.NoError(t, err)
+		return expense.ID
+	}
+	dispatch := func(at time.Time) int {
+		t.Helper()
+		delivered, err := alertService.DeliverDueWebhooks(at)
+		require.NoError(t, err)
+		return delivered
+	}
+
+	// Di bawah semua ambang batas belum ada webhook
+	spend(400)
+	assert.Equal(t, 0, dispatch(time.Now()))
+
+	// Melewati 50% mengirim satu webhook per alert, sekali saja
+	spend(150)
+	spend(10)
+	assert.Equal(t, 1, dispatch(time.Now()))
+	received := receiver.all()
+	require.Len(t, received, 1)
+	assert.Equal(t, models.WebhookEventThresholdReached, received[0].Event)
+	assert.Equal(t, 50, received[0].Threshold)
+	assert.Equal(t, models.Money(550*models.MoneyScale), received[0].Spent)
+	assert.True(t, received[0].Valid)
+
+	// Turun di bawah ambang batas lalu melewatinya lagi memicu ulang
+	groceries := spend(300)
+	require.NoError(t, expenses.DeleteExpense(owner.ID, groceries))
+	alerts, err := scoped.GetAlerts(owner.ID, budget.ID)
+	require.NoError(t, err)
+	for _, alert := range alerts.Alerts {
+		assert.Equal(t, alert.Threshold == 50, alert.Triggered, "threshold %d", alert.Threshold)
+	}
+	spend(300)
+	assert.Equal(t, 2, dispatch(time.Now()))
+	received = receiver.all()
+	require.Len(t, received, 3)
+	assert.Equal(t, 80, received[1].Threshold)
+	assert.Equal(t, 80, received[2].Threshold)
+
+	// Penerima yang gagal dicoba ulang dengan jeda yang terus berlipat
+	history, err := scoped.GetDeliveries(owner.ID, budget.ID, down.ID, &requests.WebhookDeliveryListQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, history.Total)
+	failing := history.Deliveries[0]
+	assert.Equal(t, models.WebhookPending, failing.Status)
+	assert.Equal(t, 1, failing.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, failing.StatusCode)
+	require.NotNil(t, failing.NextAttemptAt)
+	firstRetry := *failing.NextAttemptAt
+	assert.WithinDuration(t, time.Now().Add(time.Minute), firstRetry, 10*time.Second)
+
+	assert.Equal(t, 0, dispatch(time.Now()))
+	dispatch(firstRetry)
+	stored, err := alertRepo.FindDeliveryByID(failing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.Attempts)
+	require.NotNil(t, stored.NextAttemptAt)
+	assert.WithinDuration(t, firstRetry.Add(2*time.Minute), *stored.NextAttemptAt, time.Second)
+
+	dispatch(*stored.NextAttemptAt)
+	stored, err = alertRepo.FindDeliveryByID(failing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookFailed, stored.Status)
+	assert.Equal(t, 3, stored.Attempts)
+	assert.Nil(t, stored.NextAttemptAt)
+
+	// Webhook uji dikirim langsung dan tercatat di log pengiriman
+	tested, err := scoped.TestAlert(owner.ID, budget.ID, alertIDs[100])
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDelivered, tested.Status)
+	assert.Equal(t, http.StatusNoContent, tested.StatusCode)
+	received = receiver.all()
+	require.Len(t, received, 4)
+	assert.Equal(t, models.WebhookEventTest, received[3].Event)
+	assert.True(t, received[3].Valid)
+
+	history, err = scoped.GetDeliveries(owner.ID, budget.ID, alertIDs[100], &requests.WebhookDeliveryListQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, history.Total)
+	assert.Equal(t, tested.ID, history.Deliveries[0].ID)
+
+	// Alert yang dihapus tidak lagi memicu webhook
+	require.NoError(t, scoped.DeleteAlert(owner.ID, budget.ID, alertIDs[100]))
+	_, err = scoped.GetDeliveries(owner.ID, budget.ID, alertIDs[100], &requests.WebhookDeliveryListQuery{})
+	assert.EqualError(t, err, "alert not found")
+	spend(140)
+	assert.Equal(t, 0, dispatch(time.Now()))
+	assert.Len(t, receiver.all(), 4)
+}
+
+func TestWebhookSenderRefusesInternalTargets(t *testing.T) {
+	hits := 0
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer internal.Close()
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	sender := webhook.NewSender(time.Second)
+	for _, target := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://10.0.0.5/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://100.100.100.200/",
+		"http://[::1]/hook",
+		"http://[::ffff:192.168.1.1]/hook",
+		"ftp://example.com/hook",
+	} {
+		assert.ErrorIs(t, sender.CheckURL(target), webhook.ErrBlockedAddress, target)
+	}
+	assert.NoError(t, sender.CheckURL("https://hooks.example.com/dompet"))
+
+	// Alamat internal juga ditolak saat koneksi dibuka, tanpa membocorkan status target
+	status, err := sender.Send(webhook.Message{URL: internal.URL, Body: []byte("{}")}, time.Now())
+	assert.ErrorIs(t, err, webhook.ErrBlockedAddress)
+	assert.Equal(t, 0, status)
+	assert.Zero(t, hits)
+
+	// Redirect tidak diikuti; transport bawaan dipakai agar server uji terjangkau
+	sender.Client.Transport = nil
+	status, err = sender.Send(webhook.Message{URL: redirect.URL, Body: []byte("{}")}, time.Now())
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Zero(t, hits)
+}
+
+func TestBudgetAlertRejectsInternalURL(t *testing.T) {
+	db, _, budgetRepo, _ := setupExpenseService(t)
+	policy := services.WebhookPolicy{MaxAttempts: 3, BackoffBase: time.Minute, Timeout: time.Second}
+	alertService := services.NewBudgetAlertService(db, repositories.NewBudgetAlertRepository(db), budgetRepo, repositories.NewBudgetMemberRepository(db), webhook.NewSender(policy.Timeout), policy)
+
+	owner := createTestUser(t, db)
+	budget := &models.Budget{ID: uuid.New(), WorkspaceID: owner.ID, UserID: owner.ID, Name: "Internal", Amount: 100 * models.MoneyScale}
+	require.NoError(t, db.Create(budget).Error)
+	scoped := alertService.InWorkspace(services.WorkspaceScope{ID: owner.ID, Role: models.WorkspaceRoleOwner})
+
+	_, err := scoped.CreateAlert(owner.ID, budget.ID, &requests.CreateBudgetAlertRequest{Threshold: 50, URL: "http://169.254.169.254/latest/meta-data"})
+	assert.EqualError(t, err, "webhook url not allowed")
+
+	created, err := scoped.CreateAlert(owner.ID, budget.ID, &requests.CreateBudgetAlertRequest{Threshold: 50, URL: "https://hooks.example.com/dompet"})
+	require.NoError(t, err)
+	_, err = scoped.UpdateAlert(owner.ID, budget.ID, created.ID, &requests.UpdateBudgetAlertRequest{Threshold: 50, URL: "http://localhost:6379/"})
+	assert.EqualError(t, err, "webhook url not allowed")
+}
+
+func TestBudgetAlertIgnoresInPlaceEdit(t *testing.T) {
+	db, expenseService, budgetRepo, _ := setupExpenseService(t)
+	alertRepo := repositories.NewBudgetAlertRepository(db)
+	policy := services.WebhookPolicy{MaxAttempts: 3, BackoffBase: time.Minute, Timeout: time.Second}
+	alertService := services.NewBudgetAlertService(db, alertRepo, budgetRepo, repositories.NewBudgetMemberRepository(db), &webhook.Sender{Client: http.DefaultClient, AllowPrivate: true}, policy)
+
+	owner := createTestUser(t, db)
+	personal := services.WorkspaceScope{ID: owner.ID, Role: models.WorkspaceRoleOwner}
+	budget := &models.Budget{ID: uuid.New(), WorkspaceID: owner.ID, UserID: owner.ID, Name: "Dapur", Amount: 1000 * models.MoneyScale}
+	require.NoError(t, db.Create(budget).Error)
+	other := &models.Budget{ID: uuid.New(), WorkspaceID: owner.ID, UserID: owner.ID, Name: "Lain", Amount: 1000 * models.MoneyScale}
+	require.NoError(t, db.Create(other).Error)
+	expenses := expenseService.InWorkspace(personal)
+
+	alert, err := alertService.InWorkspace(personal).CreateAlert(owner.ID, budget.ID, &requests.CreateBudgetAlertRequest{Threshold: 50, URL: "http://127.0.0.1/hook"})
+	require.NoError(t, err)
+	deliveries := func() int {
+		t.Helper()
+		found, err := alertRepo.FindDeliveriesByAlertID(alert.ID, 100)
+		require.NoError(t, err)
+		return len(found)
+	}
+
+	expense, err := expenses.CreateExpense(owner.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: 600 * models.MoneyScale, Description: "Belanja"})
+	require.NoError(t, err)
+	require.Equal(t, 1, deliveries())
+
+	// Mengubah keterangan atau nominal yang tetap di atas ambang batas tidak memicu ulang
+	update := &requests.UpdateExpenseRequest{BudgetID: budget.ID, Amount: 600 * models.MoneyScale, Description: "Belanja bulanan"}
+	_, err = expenses.UpdateExpense(owner.ID, expense.ID, update)
+	require.NoError(t, err)
+	update.Amount = 700 * models.MoneyScale
+	_, err = expenses.UpdateExpense(owner.ID, expense.ID, update)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deliveries())
+
+	// Memindahkan pengeluaran ke budget lain mengaktifkan kembali peringatannya
+	update.BudgetID = other.ID
+	_, err = expenses.UpdateExpense(owner.ID, expense.ID, update)
+	require.NoError(t, err)
+	stored, err := alertRepo.FindByID(alert.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Triggered)
+	update.BudgetID = budget.ID
+	_, err = expenses.UpdateExpense(owner.ID, expense.ID, update)
+	require.NoError(t, err)
+	assert.Equal(t, 2, deliveries())
+}
@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedBudgetsInDefaultListing(t *testing.T) {
+	db, _, budgetRepo, expenseRepo := setupExpenseService(t)
+	memberRepo := repositories.NewBudgetMemberRepository(db)
+	workspaceService := services.NewWorkspaceService(db, repositories.NewWorkspaceRepository(db), repositories.NewWorkspaceMemberRepository(db), memberRepo, repositories.NewUserRepository(db))
+	budgetService := services.NewBudgetService(db, budgetRepo, expenseRepo, repositories.NewBudgetHistoryRepository(db), memberRepo, repositories.NewBudgetAlertRepository(db))
+
+	owner := createTestUser(t, db)
+	member := createTestUser(t, db)
+	shared := &models.Budget{ID: uuid.New(), WorkspaceID: owner.ID, UserID: owner.ID, Name: "Liburan", Amount: 1000 * models.MoneyScale}
+	require.NoError(t, db.Create(shared).Error)
+	own := &models.Budget{ID: uuid.New(), WorkspaceID: member.ID, UserID: member.ID, Name: "Pribadi", Amount: 100 * models.MoneyScale}
+	require.NoError(t, db.Create(own).Error)
+	require.NoError(t, memberRepo.Create(&models.BudgetMember{ID: uuid.New(), WorkspaceID: owner.ID, BudgetID: shared.ID, UserID: member.ID, Role: models.BudgetRoleEditor}))
+
+	// Tanpa header workspace, budget yang dibagikan tetap tampil seperti sebelum ada workspace
+	workspaceID, role, err := workspaceService.ResolveWorkspace(member.ID, uuid.Nil)
+	require.NoError(t, err)
+	listed, err := budgetService.InWorkspace(services.WorkspaceScope{ID: workspaceID, Role: role}).GetBudgets(member.ID)
+	require.NoError(t, err)
+	require.Len(t, listed.Budgets, 2)
+	assert.Equal(t, own.ID, listed.Budgets[0].ID)
+	assert.Equal(t, models.BudgetRoleOwner, listed.Budgets[0].Role)
+	assert.Equal(t, shared.ID, listed.Budgets[1].ID)
+	assert.Equal(t, models.BudgetRoleEditor, listed.Budgets[1].Role)
+	assert.Equal(t, owner.ID, listed.Budgets[1].WorkspaceID, "klien memakai workspace ini untuk membuka budget bersama")
+
+	// Pemilik tidak melihat budget pribadi anggota
+	workspaceID, role, err = workspaceService.ResolveWorkspace(owner.ID, uuid.Nil)
+	require.NoError(t, err)
+	listed, err = budgetService.InWorkspace(services.WorkspaceScope{ID: workspaceID, Role: role}).GetBudgets(owner.ID)
+	require.NoError(t, err)
+	require.Len(t, listed.Budgets, 1)
+	assert.Equal(t, shared.ID, listed.Budgets[0].ID)
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBudgetPeriodContaining(t *testing.T) {
+	anchor := date(2025, time.January, 31)
+	monthly := &models.Budget{Period: models.BudgetPeriodMonthly, PeriodAnchor: &anchor}
+
+	// Bulan yang lebih pendek dimulai di hari terakhirnya
+	start, end := monthly.PeriodContaining(date(2025, time.March, 1))
+	assert.Equal(t, date(2025, time.February, 28), start)
+	assert.Equal(t, date(2025, time.March, 31), end)
+
+	start, end = monthly.PeriodContaining(date(2025, time.January, 31))
+	assert.Equal(t, date(2025, time.January, 31), start)
+	assert.Equal(t, date(2025, time.February, 28), end)
+
+	weekAnchor := date(2025, time.June, 2) // Senin
+	weekly := &models.Budget{Period: models.BudgetPeriodWeekly, PeriodAnchor: &weekAnchor}
+	start, end = weekly.PeriodContaining(date(2025, time.June, 18))
+	assert.Equal(t, date(2025, time.June, 16), start)
+	assert.Equal(t, date(2025, time.June, 23), end)
+
+	customAnchor := date(2025, time.January, 1)
+	custom := &models.Budget{Period: models.BudgetPeriodCustom, PeriodAnchor: &customAnchor, PeriodDays: 14}
+	start, end = custom.PeriodContaining(date(2025, time.January, 29))
+	assert.Equal(t, date(2025, time.January, 29), start)
+	assert.Equal(t, date(2025, time.February, 12), end)
+
+	start, end = monthly.NextPeriod(date(2025, time.February, 28))
+	assert.Equal(t, date(2025, time.March, 31), start)
+	assert.Equal(t, date(2025, time.April, 30), end)
+
+	none := &models.Budget{Period: models.BudgetPeriodNone}
+	assert.True(t, none.InCurrentPeriod(date(1999, time.January, 1)))
+}
+
+func TestBudgetRolloverCarriesPerPolicy(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+
+	budgetRepo := repositories.NewBudgetRepository(db)
+	expenseRepo := repositories.NewExpenseRepository(db)
+	historyRepo := repositories.NewBudgetHistoryRepository(db)
+	budgetService := services.NewBudgetService(db, budgetRepo, expenseRepo, historyRepo, repositories.NewBudgetMemberRepository(db), repositories.NewBudgetAlertRepository(db))
+	expenseService := services.NewExpenseService(db, expenseRepo, budgetRepo, historyRepo, repositories.NewCategoryRepository(db), repositories.NewTagRepository(db), repositories.NewBudgetMemberRepository(db), repositories.NewExpenseApprovalRepository(db), repositories.NewBudgetAlertRepository(db))
+
+	now := time.Now().UTC()
+	anchor := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -2, 0)
+	// Sisa bulan pertama 400 dan bulan kedua kosong (1000), jadi surplus total 1400
+	cases := map[models.RolloverPolicy]models.Money{
+		models.RolloverReset:        0,
+		models.RolloverCarrySurplus: 1400 * models.MoneyScale,
+		models.RolloverCarryDeficit: 0,
+	}
+
+	for policy, expectedCarry := range cases {
+		t.Run(string(policy), func(t *testing.T) {
+			start, end := anchor, anchor.AddDate(0, 1, 0)
+			budget := &models.Budget{
+				ID:             uuid.New(),
+				UserID:         user.ID,
+				Name:           "Monthly " + string(policy),
+				Amount:         1000 * models.MoneyScale,
+				Period:         models.BudgetPeriodMonthly,
+				PeriodAnchor:   &anchor,
+				PeriodStart:    &start,
+				PeriodEnd:      &end,
+				RolloverPolicy: policy,
+			}
+			require.NoError(t, budgetRepo.Create(budget))
+
+			// Pengeluaran di periode lama; periode berikutnya kosong
+			require.NoError(t, expenseRepo.Create(&models.Expense{
+				ID: uuid.New(), UserID: user.ID, BudgetID: budget.ID,
+				Amount: 600 * models.MoneyScale, Date: start.AddDate(0, 0, 3),
+			}))
+
+			_, err := budgetService.RolloverDueBudgets(now)
+			require.NoError(t, err)
+
+			rolled, err := budgetRepo.FindByID(budget.ID)
+			require.NoError(t, err)
+			assert.False(t, rolled.RolloverDue(now))
+			assert.Equal(t, models.Money(0), rolled.Spent)
+
+			histories, err := historyRepo.FindByBudgetIDs([]uuid.UUID{budget.ID})
+			require.NoError(t, err)
+			require.Len(t, histories, 2)
+			oldest := histories[1]
+			assert.Equal(t, models.Money(600*models.MoneyScale), oldest.Spent)
+
+			assert.Equal(t, expectedCarry, rolled.CarryOver)
+
+			// Pengeluaran mundur tanggal masuk ke histori, bukan Spent saat ini
+			_, err = expenseService.CreateExpense(user.ID, &requests.CreateExpenseRequest{
+				BudgetID: budget.ID,
+				Amount:   50 * models.MoneyScale,
+				Date:     start.AddDate(0, 0, 5),
+			})
+			require.NoError(t, err)
+
+			rolled, err = budgetRepo.FindByID(budget.ID)
+			require.NoError(t, err)
+			assert.Equal(t, models.Money(0), rolled.Spent)
+
+			histories, err = historyRepo.FindByBudgetIDs([]uuid.UUID{budget.ID})
+			require.NoError(t, err)
+			assert.Equal(t, models.Money(650*models.MoneyScale), histories[1].Spent)
+		})
+	}
+}
+
+func TestGetBudgetsShowsCurrentPeriodWithoutRollingOver(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+
+	budgetRepo := repositories.NewBudgetRepository(db)
+	expenseRepo := repositories.NewExpenseRepository(db)
+	historyRepo := repositories.NewBudgetHistoryRepository(db)
+	budgetService := services.NewBudgetService(db, budgetRepo, expenseRepo, historyRepo, repositories.NewBudgetMemberRepository(db), repositories.NewBudgetAlertRepository(db))
+
+	now := time.Now().UTC()
+	anchor := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	start, end := anchor, anchor.AddDate(0, 1, 0)
+	budget := &models.Budget{
+		ID:             uuid.New(),
+		UserID:         user.ID,
+		Name:           "Bulanan",
+		Amount:         1000 * models.MoneyScale,
+		Spent:          600 * models.MoneyScale,
+		Period:         models.BudgetPeriodMonthly,
+		PeriodAnchor:   &anchor,
+		PeriodStart:    &start,
+		PeriodEnd:      &end,
+		RolloverPolicy: models.RolloverCarrySurplus,
+	}
+	require.NoError(t, budgetRepo.Create(budget))
+	require.NoError(t, expenseRepo.Create(&models.Expense{
+		ID: uuid.New(), UserID: user.ID, BudgetID: budget.ID,
+		Amount: 600 * models.MoneyScale, Date: start.AddDate(0, 0, 3),
+	}))
+
+	listed, err := budgetService.GetBudgets(user.ID)
+	require.NoError(t, err)
+	require.Len(t, listed.Budgets, 1)
+	shown := listed.Budgets[0]
+	assert.Equal(t, models.Money(0), shown.Spent)
+	assert.Equal(t, models.Money(400*models.MoneyScale), shown.CarryOver)
+	require.Len(t, shown.History, 1)
+	assert.Equal(t, models.Money(600*models.MoneyScale), shown.History[0].Spent)
+
+	// GET tidak menyimpan pergantian periode; itu tugas scheduler
+	stored, err := budgetRepo.FindByID(budget.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.RolloverDue(now))
+	histories, err := historyRepo.FindByBudgetIDs([]uuid.UUID{budget.ID})
+	require.NoError(t, err)
+	assert.Empty(t, histories)
+}
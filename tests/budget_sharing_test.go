@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedBudgetRoles(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	memberRepo := repositories.NewBudgetMemberRepository(db)
+	mail := &recordingMailer{}
+	budgetService := services.NewBudgetService(db, budgetRepo, expenseRepo, repositories.NewBudgetHistoryRepository(db), memberRepo, repositories.NewBudgetAlertRepository(db))
+	memberService := services.NewBudgetMemberService(db, budgetRepo, memberRepo, repositories.NewBudgetInvitationRepository(db),
+		repositories.NewUserRepository(db), mail, "https://app.example.com/")
+
+	// Pemilik dibuat lebih dulu agar datanya dihapus paling akhir
+	owner := createTestUser(t, db)
+	editor := createTestUser(t, db)
+	viewer := createTestUser(t, db)
+	outsider := createTestUser(t, db)
+	budget := createTestBudget(t, db, owner.ID, 1000*models.MoneyScale)
+
+	join := func(user *models.User, role models.BudgetRole) {
+		t.Helper()
+		_, err := memberService.InviteMember(owner.ID, budget.ID, &requests.InviteBudgetMemberRequest{Email: user.Email, Role: role})
+		require.NoError(t, err)
+		message := mail.last(t)
+		assert.Equal(t, user.Email, message.To)
+		assert.Contains(t, message.Body, "https://app.example.com/invitations/accept?token=")
+		token := linkToken(t, message.Body)
+
+		_, err = memberService.AcceptInvitation(outsider.ID, &requests.AcceptInvitationRequest{Token: token})
+		assert.EqualError(t, err, "invitation was sent to another email")
+
+		joined, err := memberService.AcceptInvitation(user.ID, &requests.AcceptInvitationRequest{Token: token})
+		require.NoError(t, err)
+		assert.Equal(t, role, joined.Role)
+		assert.Equal(t, owner.ID, joined.OwnerID)
+
+		_, err = memberService.AcceptInvitation(user.ID, &requests.AcceptInvitationRequest{Token: token})
+		assert.EqualError(t, err, "invalid or expired token", "undangan hanya bisa dipakai sekali")
+	}
+	join(editor, models.BudgetRoleEditor)
+	join(viewer, models.BudgetRoleViewer)
+
+	_, err := memberService.InviteMember(editor.ID, budget.ID, &requests.InviteBudgetMemberRequest{Email: outsider.Email, Role: models.BudgetRoleViewer})
+	assert.EqualError(t, err, "unauthorized", "hanya pemilik yang bisa mengundang")
+	_, err = memberService.InviteMember(owner.ID, budget.ID, &requests.InviteBudgetMemberRequest{Email: editor.Email, Role: models.BudgetRoleViewer})
+	assert.EqualError(t, err, "already a member")
+
+	// Budget bersama ikut tampil di daftar budget anggota
+	listed, err := budgetService.GetBudgets(viewer.ID)
+	require.NoError(t, err)
+	require.Len(t, listed.Budgets, 1)
+	assert.Equal(t, budget.ID, listed.Budgets[0].ID)
+	assert.Equal(t, models.BudgetRoleViewer, listed.Budgets[0].Role)
+
+	spent, err := expenseService.CreateExpense(editor.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: 100 * models.MoneyScale})
+	require.NoError(t, err)
+	assert.Equal(t, editor.ID, spent.SpentBy)
+	_, err = expenseService.CreateExpense(viewer.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: models.MoneyScale})
+	assert.EqualError(t, err, "unauthorized", "viewer hanya bisa membaca")
+	_, err = expenseService.CreateExpense(outsider.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: models.MoneyScale})
+	assert.EqualError(t, err, "unauthorized")
+	ownExpense, err := expenseService.CreateExpense(owner.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: 50 * models.MoneyScale})
+	require.NoError(t, err)
+
+	// Pengeluaran anggota tercatat atas nama anggota tersebut
+	expenses, err := expenseService.GetExpenses(owner.ID, &requests.ExpenseListQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 2, expenses.Total)
+	spentBy := map[uuid.UUID]string{}
+	for _, expense := range expenses.Expenses {
+		spentBy[expense.SpentBy] = expense.SpentByName
+	}
+	assert.Equal(t, map[uuid.UUID]string{owner.ID: owner.Name, editor.ID: editor.Name}, spentBy)
+
+	byBudget, err := expenseService.GetExpensesByBudget(viewer.ID, budget.ID, &requests.ExpenseListQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, byBudget.Total)
+	_, err = expenseService.GetExpensesByBudget(outsider.ID, budget.ID, &requests.ExpenseListQuery{})
+	assert.EqualError(t, err, "unauthorized")
+
+	// Editor hanya boleh mengubah pengeluarannya sendiri
+	assert.EqualError(t, expenseService.DeleteExpense(editor.ID, ownExpense.ID), "unauthorized")
+	_, err = expenseService.UpdateExpense(editor.ID, spent.ID, &requests.UpdateExpenseRequest{BudgetID: budget.ID, Amount: 120 * models.MoneyScale})
+	require.NoError(t, err)
+	require.NoError(t, expenseService.DeleteExpense(owner.ID, spent.ID))
+	consistent := assertBudgetConsistent(t, budgetRepo, expenseRepo, budget.ID)
+	assert.Equal(t, models.Money(50*models.MoneyScale), consistent.Spent)
+
+	_, err = budgetService.UpdateBudget(editor.ID, budget.ID, &requests.UpdateBudgetRequest{Name: "Diubah", Amount: models.MoneyScale})
+	assert.EqualError(t, err, "unauthorized", "hanya pemilik yang bisa mengubah budget")
+	assert.EqualError(t, budgetService.DeleteBudget(editor.ID, budget.ID), "unauthorized")
+
+	members, err := memberService.GetMembers(viewer.ID, budget.ID)
+	require.NoError(t, err)
+	require.Len(t, members.Members, 3)
+	assert.Equal(t, models.BudgetRoleOwner, members.Members[0].Role)
+	assert.Empty(t, members.Invitations, "undangan hanya terlihat oleh pemilik")
+
+	_, err = memberService.UpdateMemberRole(editor.ID, budget.ID, viewer.ID, &requests.UpdateBudgetMemberRequest{Role: models.BudgetRoleEditor})
+	assert.EqualError(t, err, "unauthorized")
+	promoted, err := memberService.UpdateMemberRole(owner.ID, budget.ID, viewer.ID, &requests.UpdateBudgetMemberRequest{Role: models.BudgetRoleEditor})
+	require.NoError(t, err)
+	assert.Equal(t, models.BudgetRoleEditor, promoted.Role)
+
+	assert.EqualError(t, memberService.RemoveMember(editor.ID, budget.ID, owner.ID), "unauthorized")
+	assert.EqualError(t, memberService.RemoveMember(owner.ID, budget.ID, owner.ID), "cannot remove the owner")
+	require.NoError(t, memberService.RemoveMember(viewer.ID, budget.ID, viewer.ID), "anggota boleh keluar sendiri")
+
+	listed, err = budgetService.GetBudgets(viewer.ID)
+	require.NoError(t, err)
+	assert.Empty(t, listed.Budgets)
+}
+
+func TestDeleteAccountKeepsExpensesInSharedBudgets(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	memberRepo := repositories.NewBudgetMemberRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+
+	owner := createTestUser(t, db)
+	member := createTestUser(t, db)
+	budget := createTestBudget(t, db, owner.ID, 1000*models.MoneyScale)
+	require.NoError(t, memberRepo.Create(&models.BudgetMember{ID: uuid.New(), BudgetID: budget.ID, UserID: member.ID, Role: models.BudgetRoleEditor}))
+
+	spent, err := expenseService.CreateExpense(member.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: 200 * models.MoneyScale, Tags: []string{"makan"}})
+	require.NoError(t, err)
+
+	require.NoError(t, userRepo.DeleteWithData(member.ID))
+
+	// Pengeluaran tetap ada agar total budget pemilik tidak berubah
+	kept, err := expenseRepo.FindByID(spent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, owner.ID, kept.UserID)
+	assert.Empty(t, kept.Tags)
+	consistent := assertBudgetConsistent(t, budgetRepo, expenseRepo, budget.ID)
+	assert.Equal(t, models.Money(200*models.MoneyScale), consistent.Spent)
+
+	_, err = memberRepo.Find(budget.ID, member.ID)
+	assert.Error(t, err)
+}
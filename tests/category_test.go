@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterExpensesByCategoryTreeAndTag(t *testing.T) {
+	db, expenseService, _, _ := setupExpenseService(t)
+	user := createTestUser(t, db)
+	budget := createTestBudget(t, db, user.ID, 1000*models.MoneyScale)
+
+	categoryService := services.NewCategoryService(db, repositories.NewCategoryRepository(db), repositories.NewExpenseRepository(db), repositories.NewTagRepository(db))
+	food, err := categoryService.CreateCategory(user.ID, &requests.CreateCategoryRequest{Name: "Makanan"})
+	require.NoError(t, err)
+	coffee, err := categoryService.CreateCategory(user.ID, &requests.CreateCategoryRequest{Name: "Kopi", ParentID: &food.ID})
+	require.NoError(t, err)
+	transport, err := categoryService.CreateCategory(user.ID, &requests.CreateCategoryRequest{Name: "Transport"})
+	require.NoError(t, err)
+
+	for _, req := range []requests.CreateExpenseRequest{
+		{BudgetID: budget.ID, CategoryID: &food.ID, Amount: 10 * models.MoneyScale, Tags: []string{"Kantor"}},
+		{BudgetID: budget.ID, CategoryID: &coffee.ID, Amount: 5 * models.MoneyScale, Tags: []string{"kantor ", "pagi"}},
+		{BudgetID: budget.ID, CategoryID: &transport.ID, Amount: 7 * models.MoneyScale},
+	} {
+		_, err := expenseService.CreateExpense(user.ID, &req)
+		require.NoError(t, err)
+	}
+
+	byFood, err := expenseService.GetExpenses(user.ID, &requests.ExpenseListQuery{CategoryID: &food.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 2, byFood.Total, "kategori induk ikut menyertakan subkategori")
+
+	byTag, err := expenseService.GetExpenses(user.ID, &requests.ExpenseListQuery{Tag: "KANTOR"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, byTag.Total)
+
+	tree, err := categoryService.GetCategories(user.ID)
+	require.NoError(t, err)
+	require.Len(t, tree.Categories, 2)
+
+	// Menghapus induk memindahkan anak ke atas dan mengosongkan kategori pengeluaran
+	require.NoError(t, categoryService.DeleteCategory(user.ID, food.ID))
+	byCoffee, err := expenseService.GetExpenses(user.ID, &requests.ExpenseListQuery{CategoryID: &coffee.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, byCoffee.Total)
+
+	tree, err = categoryService.GetCategories(user.ID)
+	require.NoError(t, err)
+	assert.Len(t, tree.Categories, 2)
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/middlewares"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// registerTestUser mendaftarkan pengguna baru lewat AuthService dan menghapusnya setelah tes.
+func registerTestUser(t *testing.T, db *gorm.DB, authService *services.AuthService) (*responses.AuthResponse, string) {
+	t.Helper()
+	email := "daftar-" + uuid.NewString() + "@example.com"
+	response, err := authService.Register(&requests.RegisterRequest{Email: email, Password: "password123", Name: "Pengguna Baru"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		repositories.NewUserRepository(db).DeleteWithData(response.User.ID)
+	})
+	return response, email
+}
+
+func TestRegisterNormalizesEmail(t *testing.T) {
+	db := openTestDB(t)
+	authService := newTestAuthService(db, testKeys(tokenTestSecret), &recordingMailer{})
+	email := "daftar-" + uuid.NewString() + "@example.com"
+
+	response, err := authService.Register(&requests.RegisterRequest{Email: " " + strings.ToUpper(email) + " ", Password: "password123", Name: "Pengguna Baru"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		repositories.NewUserRepository(db).DeleteWithData(response.User.ID)
+	})
+	assert.Equal(t, email, response.User.Email)
+
+	_, err = authService.Register(&requests.RegisterRequest{Email: email, Password: "password123", Name: "Pengguna Lain"})
+	assert.EqualError(t, err, "user already exists")
+	_, err = authService.Login(&requests.LoginRequest{Email: email, Password: "password123"}, testIP, "")
+	assert.NoError(t, err)
+}
+
+func TestRegisterLimitsUnverifiedAccount(t *testing.T) {
+	db := openTestDB(t)
+	mail := &recordingMailer{}
+	keys := testKeys(tokenTestSecret)
+	authService := newTestAuthServiceWithPolicy(db, keys, mail, services.UnverifiedLimit)
+
+	session, email := registerTestUser(t, db, authService)
+	assert.False(t, session.User.EmailVerified)
+	claims, err := utils.ValidateToken(session.Token, keys)
+	require.NoError(t, err)
+	assert.True(t, claims.Limited, "token akun belum terverifikasi hanya bisa membaca")
+
+	message := mail.last(t)
+	assert.Equal(t, email, message.To)
+	assert.Contains(t, message.Body, "https://app.example.com/verify-email?token=")
+	token := linkToken(t, message.Body)
+
+	_, err = authService.VerifyEmail(&requests.VerifyEmailRequest{Token: "salah"})
+	assert.EqualError(t, err, "invalid or expired token")
+
+	user, err := authService.VerifyEmail(&requests.VerifyEmailRequest{Token: token})
+	require.NoError(t, err)
+	assert.True(t, user.EmailVerified)
+
+	_, err = authService.VerifyEmail(&requests.VerifyEmailRequest{Token: token})
+	assert.EqualError(t, err, "invalid or expired token", "token hanya bisa dipakai sekali")
+
+	refreshed, err := authService.Refresh(&requests.RefreshTokenRequest{RefreshToken: session.RefreshToken})
+	require.NoError(t, err)
+	claims, err = utils.ValidateToken(refreshed.Token, keys)
+	require.NoError(t, err)
+	assert.False(t, claims.Limited, "token baru setelah verifikasi tidak dibatasi")
+
+	// Akun yang sudah terverifikasi tidak dikirimi tautan lagi
+	sent := len(mail.messages)
+	require.NoError(t, authService.ResendVerification(&requests.ResendVerificationRequest{Email: email}))
+	assert.Len(t, mail.messages, sent)
+}
+
+func TestBlockPolicyRejectsUnverifiedLogin(t *testing.T) {
+	db := openTestDB(t)
+	mail := &recordingMailer{}
+	authService := newTestAuthServiceWithPolicy(db, testKeys(tokenTestSecret), mail, services.UnverifiedBlock)
+
+	registered, email := registerTestUser(t, db, authService)
+	assert.Empty(t, registered.Token, "registrasi tidak memberi token sebelum verifikasi")
+	assert.Empty(t, registered.RefreshToken)
+	first := linkToken(t, mail.last(t).Body)
+
+	_, err := authService.Login(&requests.LoginRequest{Email: email, Password: "password123"}, testIP, "")
+	assert.EqualError(t, err, "email not verified")
+
+	// Alamat yang tidak terdaftar tidak dibedakan dari yang terdaftar
+	require.NoError(t, authService.ResendVerification(&requests.ResendVerificationRequest{Email: "tidak-ada@example.com"}))
+	require.NoError(t, authService.ResendVerification(&requests.ResendVerificationRequest{Email: email}))
+	token := linkToken(t, mail.last(t).Body)
+
+	_, err = authService.VerifyEmail(&requests.VerifyEmailRequest{Token: first})
+	assert.EqualError(t, err, "invalid or expired token", "hanya tautan terakhir yang berlaku")
+
+	_, err = authService.VerifyEmail(&requests.VerifyEmailRequest{Token: token})
+	require.NoError(t, err)
+
+	session, err := authService.Login(&requests.LoginRequest{Email: email, Password: "password123"}, testIP, "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, session.Token)
+	assert.True(t, session.User.EmailVerified)
+}
+
+func TestLimitUnverifiedAllowsOnlyReads(t *testing.T) {
+	e := echo.New()
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	g := e.Group("/api/v1", func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("limited", c.Request().Header.Get("X-Limited") == "1")
+			return next(c)
+		}
+	}, middlewares.LimitUnverified("/api/v1/logout"))
+	g.GET("/budgets", ok)
+	g.POST("/budgets", ok)
+	g.POST("/logout", ok)
+
+	cases := []struct {
+		method, path, limited string
+		want                  int
+	}{
+		{http.MethodGet, "/api/v1/budgets", "1", http.StatusOK},
+		{http.MethodPost, "/api/v1/budgets", "1", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/logout", "1", http.StatusOK},
+		{http.MethodPost, "/api/v1/budgets", "0", http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("X-Limited", tc.limited)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, tc.want, rec.Code, "%s %s limited=%s", tc.method, tc.path, tc.limited)
+	}
+}
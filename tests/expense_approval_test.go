@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpenseApprovalWorkflow(t *testing.T) {
+	db, expenseService, budgetRepo, _ := setupExpenseService(t)
+	memberRepo := repositories.NewBudgetMemberRepository(db)
+
+	owner := createTestUser(t, db)
+	editor := createTestUser(t, db)
+	budget := createTestBudget(t, db, owner.ID, 1000*models.MoneyScale)
+	budget.ApprovalThreshold = 100 * models.MoneyScale
+	require.NoError(t, budgetRepo.Update(budget))
+	require.NoError(t, memberRepo.Create(&models.BudgetMember{ID: uuid.New(), BudgetID: budget.ID, UserID: editor.ID, Role: models.BudgetRoleEditor}))
+
+	spent := func() models.Money {
+		t.Helper()
+		stored, err := budgetRepo.FindByID(budget.ID)
+		require.NoError(t, err)
+		return stored.Spent
+	}
+
+	// Di bawah batas langsung tercatat
+	small, err := expenseService.CreateExpense(editor.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: 50 * models.MoneyScale})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExpenseStatusApproved, small.Status)
+
+	// Di atas batas menunggu persetujuan dan belum mengurangi budget
+	large, err := expenseService.CreateExpense(editor.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: 200 * models.MoneyScale})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExpenseStatusPending, large.Status)
+	assert.Equal(t, models.Money(50*models.MoneyScale), spent())
+
+	// Pemilik budget tidak perlu persetujuan
+	own, err := expenseService.CreateExpense(owner.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: 300 * models.MoneyScale})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExpenseStatusApproved, own.Status)
+	assert.Equal(t, models.Money(350*models.MoneyScale), spent())
+
+	pending, err := expenseService.GetPendingApprovals(owner.ID, &requests.ExpenseListQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, pending.Total)
+	assert.Equal(t, large.ID, pending.Expenses[0].ID)
+	pending, err = expenseService.GetPendingApprovals(editor.ID, &requests.ExpenseListQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, pending.Total, "editor tidak bisa menyetujui")
+
+	_, err = expenseService.ApproveExpense(editor.ID, large.ID, &requests.ApproveExpenseRequest{})
+	assert.EqualError(t, err, "unauthorized")
+	approved, err := expenseService.ApproveExpense(owner.ID, large.ID, &requests.ApproveExpenseRequest{Comment: "Sesuai nota"})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExpenseApprovalApproved, approved.Action)
+	assert.Equal(t, models.Money(550*models.MoneyScale), spent())
+	_, err = expenseService.ApproveExpense(owner.ID, large.ID, &requests.ApproveExpenseRequest{})
+	assert.EqualError(t, err, "expense is not pending")
+
+	// Pengeluaran yang ditolak tetap tersimpan tanpa mengurangi budget
+	rejected, err := expenseService.CreateExpense(editor.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: 250 * models.MoneyScale})
+	require.NoError(t, err)
+	_, err = expenseService.RejectExpense(owner.ID, rejected.ID, &requests.RejectExpenseRequest{Comment: "Tidak ada nota"})
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(550*models.MoneyScale), spent())
+
+	history, err := expenseService.GetApprovalHistory(editor.ID, rejected.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExpenseStatusRejected, history.Status)
+	require.Len(t, history.Approvals, 2)
+	assert.Equal(t, models.ExpenseApprovalSubmitted, history.Approvals[0].Action)
+	assert.Equal(t, editor.ID, history.Approvals[0].UserID)
+	assert.Equal(t, models.ExpenseApprovalRejected, history.Approvals[1].Action)
+	assert.Equal(t, "Tidak ada nota", history.Approvals[1].Comment)
+	assert.Equal(t, owner.Name, history.Approvals[1].UserName)
+
+	// Setelah diubah di bawah batas, pengeluaran kembali tercatat
+	updated, err := expenseService.UpdateExpense(editor.ID, rejected.ID, &requests.UpdateExpenseRequest{BudgetID: budget.ID, Amount: 80 * models.MoneyScale})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExpenseStatusApproved, updated.Status)
+	assert.Equal(t, models.Money(630*models.MoneyScale), spent())
+
+	// Menaikkan nominal melewati batas mengembalikannya ke antrean
+	updated, err = expenseService.UpdateExpense(editor.ID, small.ID, &requests.UpdateExpenseRequest{BudgetID: budget.ID, Amount: 120 * models.MoneyScale})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExpenseStatusPending, updated.Status)
+	assert.Equal(t, models.Money(580*models.MoneyScale), spent())
+
+	// Menghapus pengeluaran yang menunggu tidak mengembalikan apa pun
+	require.NoError(t, expenseService.DeleteExpense(editor.ID, small.ID))
+	assert.Equal(t, models.Money(580*models.MoneyScale), spent())
+
+	// Sisa budget diperiksa saat disetujui
+	tooLarge, err := expenseService.CreateExpense(editor.ID, &requests.CreateExpenseRequest{BudgetID: budget.ID, Amount: 500 * models.MoneyScale})
+	require.NoError(t, err)
+	_, err = expenseService.ApproveExpense(owner.ID, tooLarge.ID, &requests.ApproveExpenseRequest{})
+	assert.EqualError(t, err, "insufficient budget")
+
+	listed, err := expenseService.GetExpenses(owner.ID, &requests.ExpenseListQuery{Status: models.ExpenseStatusPending})
+	require.NoError(t, err)
+	require.Equal(t, 1, listed.Total)
+	assert.Equal(t, tooLarge.ID, listed.Expenses[0].ID)
+}
+
+func TestPendingApprovalsArePaged(t *testing.T) {
+	db, expenseService, budgetRepo, _ := setupExpenseService(t)
+	memberRepo := repositories.NewBudgetMemberRepository(db)
+
+	owner := createTestUser(t, db)
+	editor := createTestUser(t, db)
+	budget := createTestBudget(t, db, owner.ID, 1000*models.MoneyScale)
+	budget.ApprovalThreshold = 10 * models.MoneyScale
+	require.NoError(t, budgetRepo.Update(budget))
+	require.NoError(t, memberRepo.Create(&models.BudgetMember{ID: uuid.New(), BudgetID: budget.ID, UserID: editor.ID, Role: models.BudgetRoleEditor}))
+
+	start := time.Now().AddDate(0, 0, -3)
+	var created []uuid.UUID
+	for i := range 3 {
+		expense, err := expenseService.CreateExpense(editor.ID, &requests.CreateExpenseRequest{
+			BudgetID: budget.ID, Amount: 20 * models.MoneyScale, Date: start.AddDate(0, 0, i),
+		})
+		require.NoError(t, err)
+		require.Equal(t, models.ExpenseStatusPending, expense.Status)
+		created = append(created, expense.ID)
+	}
+
+	// Yang terlama lebih dulu, dipaging seperti daftar pengeluaran
+	first, err := expenseService.GetPendingApprovals(owner.ID, &requests.ExpenseListQuery{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, first.Total)
+	require.Len(t, first.Expenses, 2)
+	assert.Equal(t, created[0], first.Expenses[0].ID)
+	assert.Equal(t, created[1], first.Expenses[1].ID)
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := expenseService.GetPendingApprovals(owner.ID, &requests.ExpenseListQuery{Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, second.Expenses, 1)
+	assert.Equal(t, created[2], second.Expenses[0].ID)
+	assert.Empty(t, second.NextCursor)
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupExpenseService(t *testing.T) (*gorm.DB, *services.ExpenseService, *repositories.BudgetRepository, *repositories.ExpenseRepository) {
+	db := openTestDB(t)
+	budgetRepo := repositories.NewBudgetRepository(db)
+	expenseRepo := repositories.NewExpenseRepository(db)
+	historyRepo := repositories.NewBudgetHistoryRepository(db)
+	return db, services.NewExpenseService(db, expenseRepo, budgetRepo, historyRepo, repositories.NewCategoryRepository(db), repositories.NewTagRepository(db), repositories.NewBudgetMemberRepository(db), repositories.NewExpenseApprovalRepository(db), repositories.NewBudgetAlertRepository(db)), budgetRepo, expenseRepo
+}
+
+func createTestBudget(t *testing.T, db *gorm.DB, userID uuid.UUID, amount models.Money) *models.Budget {
+	t.Helper()
+
+	budget := &models.Budget{
+		ID:     uuid.New(),
+		UserID: userID,
+		Name:   "Concurrency Budget",
+		Amount: amount,
+	}
+	require.NoError(t, db.Create(budget).Error)
+	return budget
+}
+
+// assertBudgetConsistent memastikan Spent sama dengan jumlah pengeluaran yang masih aktif.
+func assertBudgetConsistent(t *testing.T, budgetRepo *repositories.BudgetRepository, expenseRepo *repositories.ExpenseRepository, budgetID uuid.UUID) *models.Budget {
+	t.Helper()
+
+	budget, err := budgetRepo.FindByID(budgetID)
+	require.NoError(t, err)
+
+	total, err := expenseRepo.SumByBudgetID(budgetID)
+	require.NoError(t, err)
+
+	assert.Equal(t, total, budget.Spent, "spent harus sama dengan jumlah pengeluaran aktif")
+	assert.LessOrEqual(t, budget.Spent, budget.Amount, "budget tidak boleh overspend")
+	return budget
+}
+
+func TestConcurrentCreateExpenseNeverOverspends(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	user := createTestUser(t, db)
+	budget := createTestBudget(t, db, user.ID, 1000*models.MoneyScale)
+
+	const workers = 50
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := expenseService.CreateExpense(user.ID, &requests.CreateExpenseRequest{
+				BudgetID: budget.ID,
+				Amount:   30 * models.MoneyScale,
+			})
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+				return
+			}
+			assert.Equal(t, "insufficient budget", err.Error())
+		}()
+	}
+	wg.Wait()
+
+	// 1000 / 30 = 33 pengeluaran yang muat dalam budget
+	assert.Equal(t, 33, succeeded)
+	final := assertBudgetConsistent(t, budgetRepo, expenseRepo, budget.ID)
+	assert.Equal(t, models.Money(99000), final.Spent)
+}
+
+func TestConcurrentMixedExpenseMutationsKeepSpentConsistent(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	user := createTestUser(t, db)
+	budget := createTestBudget(t, db, user.ID, 5000*models.MoneyScale)
+
+	// Seed beberapa pengeluaran untuk diubah dan dihapus secara paralel
+	var seeded []uuid.UUID
+	for i := 0; i < 20; i++ {
+		resp, err := expenseService.CreateExpense(user.ID, &requests.CreateExpenseRequest{
+			BudgetID: budget.ID,
+			Amount:   50 * models.MoneyScale,
+		})
+		require.NoError(t, err)
+		seeded = append(seeded, resp.ID)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target := seeded[i%len(seeded)]
+			switch i % 3 {
+			case 0:
+				expenseService.CreateExpense(user.ID, &requests.CreateExpenseRequest{
+					BudgetID: budget.ID,
+					Amount:   models.Money(rand.Intn(200)+1) * models.MoneyScale,
+				})
+			case 1:
+				expenseService.UpdateExpense(user.ID, target, &requests.UpdateExpenseRequest{
+					BudgetID: budget.ID,
+					Amount:   models.Money(rand.Intn(300)+1) * models.MoneyScale,
+				})
+			case 2:
+				expenseService.DeleteExpense(user.ID, target)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assertBudgetConsistent(t, budgetRepo, expenseRepo, budget.ID)
+}
+
+func TestConcurrentDeleteRefundsOnce(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	user := createTestUser(t, db)
+	budget := createTestBudget(t, db, user.ID, 1000*models.MoneyScale)
+
+	resp, err := expenseService.CreateExpense(user.ID, &requests.CreateExpenseRequest{
+		BudgetID: budget.ID,
+		Amount:   400 * models.MoneyScale,
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			expenseService.DeleteExpense(user.ID, resp.ID)
+		}()
+	}
+	wg.Wait()
+
+	final := assertBudgetConsistent(t, budgetRepo, expenseRepo, budget.ID)
+	assert.Equal(t, models.Money(0), final.Spent)
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpenseListingPaginatesWithCursor(t *testing.T) {
+	db, expenseService, _, _ := setupExpenseService(t)
+	user := createTestUser(t, db)
+	budget := createTestBudget(t, db, user.ID, 1000*models.MoneyScale)
+
+	base := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	for i, description := range []string{"Kopi pagi", "Makan siang", "Kopi sore", "Bensin", "Parkir"} {
+		_, err := expenseService.CreateExpense(user.ID, &requests.CreateExpenseRequest{
+			BudgetID:    budget.ID,
+			Amount:      models.Money((i + 1) * 10 * models.MoneyScale),
+			Description: description,
+			Date:        base.Add(time.Duration(i) * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	// Menelusuri semua halaman lewat next_cursor
+	var amounts []models.Money
+	query := &requests.ExpenseListQuery{Limit: 2}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5)
+		list, err := expenseService.GetExpenses(user.ID, query)
+		require.NoError(t, err)
+		assert.Equal(t, 5, list.Total)
+		for _, expense := range list.Expenses {
+			amounts = append(amounts, expense.Amount)
+		}
+		if list.NextCursor == "" {
+			break
+		}
+		query.Cursor = list.NextCursor
+	}
+	assert.Equal(t, []models.Money{5000, 4000, 3000, 2000, 1000}, amounts, "default urutan tanggal terbaru dulu")
+
+	minAmount := models.Money(20 * models.MoneyScale)
+	filtered, err := expenseService.GetExpenses(user.ID, &requests.ExpenseListQuery{Search: "kopi", MinAmount: &minAmount, Sort: "amount"})
+	require.NoError(t, err)
+	require.Equal(t, 1, filtered.Total)
+	assert.Equal(t, "Kopi sore", filtered.Expenses[0].Description)
+
+	byBudget, err := expenseService.GetExpensesByBudget(user.ID, budget.ID, &requests.ExpenseListQuery{Sort: "-amount", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 5, byBudget.Total)
+	assert.Equal(t, models.Money(5000), byBudget.Expenses[0].Amount)
+
+	// Cursor dari urutan lain ditolak
+	_, err = expenseService.GetExpenses(user.ID, &requests.ExpenseListQuery{Sort: "amount", Cursor: byBudget.NextCursor})
+	assert.EqualError(t, err, "invalid cursor")
+}
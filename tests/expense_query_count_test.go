@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// countQueries menghitung setiap query SELECT yang dijalankan lewat db.
+func countQueries(t testing.TB, db *gorm.DB) *atomic.Int64 {
+	t.Helper()
+
+	var count atomic.Int64
+	increment := func(*gorm.DB) { count.Add(1) }
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("tests:count_query", increment))
+	require.NoError(t, db.Callback().Row().After("gorm:row").Register("tests:count_row", increment))
+	return &count
+}
+
+func seedExpenses(t testing.TB, db *gorm.DB, userID uuid.UUID, budgets, perBudget int) {
+	t.Helper()
+
+	for i := 0; i < budgets; i++ {
+		budget := &models.Budget{ID: uuid.New(), UserID: userID, Name: "Budget", Amount: 1000 * models.MoneyScale}
+		require.NoError(t, db.Create(budget).Error)
+		for j := 0; j < perBudget; j++ {
+			require.NoError(t, db.Create(&models.Expense{
+				ID:       uuid.New(),
+				UserID:   userID,
+				BudgetID: budget.ID,
+				Amount:   models.MoneyScale,
+			}).Error)
+		}
+	}
+}
+
+func TestGetExpensesQueryCountIsConstant(t *testing.T) {
+	db, expenseService, _, _ := setupExpenseService(t)
+	counter := countQueries(t, db)
+
+	queriesFor := func(budgets, perBudget int) int64 {
+		user := createTestUser(t, db)
+		seedExpenses(t, db, user.ID, budgets, perBudget)
+
+		counter.Store(0)
+		list, err := expenseService.GetExpenses(user.ID, &requests.ExpenseListQuery{Limit: 100})
+		require.NoError(t, err)
+		require.Len(t, list.Expenses, budgets*perBudget)
+		return counter.Load()
+	}
+
+	small := queriesFor(1, 1)
+	large := queriesFor(10, 5)
+	assert.Equal(t, small, large, "jumlah query tidak boleh bergantung pada jumlah pengeluaran")
+}
+
+func BenchmarkGetExpenses(b *testing.B) {
+	db := openTestDB(b)
+	expenseService := services.NewExpenseService(db, repositories.NewExpenseRepository(db), repositories.NewBudgetRepository(db),
+		repositories.NewBudgetHistoryRepository(db), repositories.NewCategoryRepository(db), repositories.NewTagRepository(db), repositories.NewBudgetMemberRepository(db), repositories.NewExpenseApprovalRepository(db), repositories.NewBudgetAlertRepository(db))
+	user := createTestUser(b, db)
+	seedExpenses(b, db, user.ID, 10, 10)
+	counter := countQueries(b, db)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := expenseService.GetExpenses(user.ID, &requests.ExpenseListQuery{Limit: 100}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(counter.Load())/float64(b.N), "queries/op")
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateExpenseReassignsBetweenBudgets(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	user := createTestUser(t, db)
+	oldBudget := createTestBudget(t, db, user.ID, 1000*models.MoneyScale)
+	newBudget := createTestBudget(t, db, user.ID, 500*models.MoneyScale)
+
+	created, err := expenseService.CreateExpense(user.ID, &requests.CreateExpenseRequest{
+		BudgetID: oldBudget.ID,
+		Amount:   300 * models.MoneyScale,
+	})
+	require.NoError(t, err)
+
+	_, err = expenseService.UpdateExpense(user.ID, created.ID, &requests.UpdateExpenseRequest{
+		BudgetID: newBudget.ID,
+		Amount:   350 * models.MoneyScale,
+	})
+	require.NoError(t, err)
+
+	old := assertBudgetConsistent(t, budgetRepo, expenseRepo, oldBudget.ID)
+	assert.Equal(t, models.Money(0), old.Spent)
+
+	moved := assertBudgetConsistent(t, budgetRepo, expenseRepo, newBudget.ID)
+	assert.Equal(t, models.Money(350*models.MoneyScale), moved.Spent)
+}
+
+func TestUpdateExpenseReassignChecksFullAmount(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	user := createTestUser(t, db)
+	oldBudget := createTestBudget(t, db, user.ID, 1000*models.MoneyScale)
+	newBudget := createTestBudget(t, db, user.ID, 200*models.MoneyScale)
+
+	created, err := expenseService.CreateExpense(user.ID, &requests.CreateExpenseRequest{
+		BudgetID: oldBudget.ID,
+		Amount:   300 * models.MoneyScale,
+	})
+	require.NoError(t, err)
+
+	// Selisihnya nol, tetapi 300 tidak muat di budget baru yang hanya 200
+	_, err = expenseService.UpdateExpense(user.ID, created.ID, &requests.UpdateExpenseRequest{
+		BudgetID: newBudget.ID,
+		Amount:   300 * models.MoneyScale,
+	})
+	require.EqualError(t, err, "insufficient budget")
+
+	old := assertBudgetConsistent(t, budgetRepo, expenseRepo, oldBudget.ID)
+	assert.Equal(t, models.Money(300*models.MoneyScale), old.Spent)
+
+	untouched := assertBudgetConsistent(t, budgetRepo, expenseRepo, newBudget.ID)
+	assert.Equal(t, models.Money(0), untouched.Spent)
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/exporters"
+	"github.com/Alvarras/dompet-g0/internal/importers"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportFormats(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	user := createTestUser(t, db)
+	budget := createTestBudget(t, db, user.ID, 1000*models.MoneyScale)
+	exportService := services.NewExportService(budgetRepo, expenseRepo)
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i, description := range []string{"Makan, siang", "Kopi <besar>", "Parkir"} {
+		_, err := expenseService.CreateExpense(user.ID, &requests.CreateExpenseRequest{
+			BudgetID: budget.ID, Amount: models.Money(i+1) * 1050, Description: description,
+			Date: base.Add(time.Duration(i) * time.Minute), Tags: []string{"kantor"},
+		})
+		require.NoError(t, err)
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, exportService.Export(user.ID, exporters.FormatCSV, time.Time{}, time.Time{}, &out))
+	records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(out.Bytes(), []byte("\ufeff")))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5, "header, satu budget, tiga pengeluaran")
+	assert.Equal(t, "budget", records[1][0])
+	assert.Equal(t, []string{"expense", "Makan, siang", "10.50", "kantor"}, []string{records[2][0], records[2][6], records[2][7], records[2][9]})
+
+	out.Reset()
+	require.NoError(t, exportService.Export(user.ID, exporters.FormatJSON, base.Add(time.Minute), time.Time{}, &out))
+	var archive struct {
+		Budgets  []map[string]any `json:"budgets"`
+		Expenses []map[string]any `json:"expenses"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &archive))
+	assert.Len(t, archive.Budgets, 1)
+	assert.Len(t, archive.Expenses, 2, "filter from berlaku untuk pengeluaran")
+
+	// File OFX hasil ekspor bisa diimpor kembali
+	out.Reset()
+	require.NoError(t, exportService.Export(user.ID, exporters.FormatOFX, time.Time{}, time.Time{}, &out))
+	rows, err := importers.Parse(importers.FormatOFX, &out, importers.Options{})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, models.Money(3150), rows[2].Amount)
+}
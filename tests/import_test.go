@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/importers"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatements(t *testing.T) {
+	csv := "Tanggal;Nominal;Keterangan\n10/03/2025;-1.234,50;Supermarket\n11/03/2025;abc;Rusak\n12/03/2025;5.000,00;Gaji\n"
+	rows, err := importers.Parse(importers.FormatCSV, strings.NewReader(csv), importers.Options{
+		DateColumn: "tanggal", AmountColumn: "nominal", DescriptionColumn: "keterangan",
+		DateFormat: "02/01/2006", Delimiter: ';', DecimalComma: true, NegativeExpenses: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2, "kredit tidak diimpor")
+	assert.Equal(t, models.Money(123450), rows[0].Amount)
+	assert.Equal(t, time.March, rows[0].Date.Month())
+	assert.Equal(t, "invalid amount", rows[1].Error)
+
+	// Tanpa negative_expenses, nominal positif adalah pengeluaran dan negatif adalah kredit
+	csv = "date,amount,description\n2025-03-10,25.00,Pasar\n2025-03-11,-100.00,Refund\n"
+	rows, err = importers.Parse(importers.FormatCSV, strings.NewReader(csv), importers.Options{})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, models.Money(2500), rows[0].Amount)
+	assert.Equal(t, "Pasar", rows[0].Description)
+
+	ofx := `OFXHEADER:100
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20250310120000[-5:EST]<TRNAMT>-42.10<NAME>Coffee Shop</STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20250311<TRNAMT>1000.00<NAME>Salary</STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`
+	rows, err = importers.Parse(importers.FormatOFX, strings.NewReader(ofx), importers.Options{})
+	require.NoError(t, err)
+	require.Len(t, rows, 1, "kredit tidak diimpor")
+	assert.Equal(t, models.Money(4210), rows[0].Amount)
+	assert.Equal(t, "Coffee Shop", rows[0].Description)
+
+	qif := "!Type:Bank\nD3/10'25\nT-15.00\nPParkir\n^\nD3/11'25\nT200.00\nPRefund\n^\n"
+	rows, err = importers.Parse(importers.FormatQIF, strings.NewReader(qif), importers.Options{})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 10, rows[0].Date.Day())
+	assert.Equal(t, models.Money(1500), rows[0].Amount)
+}
+
+func TestImportPreviewAndAtomicCommit(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	user := createTestUser(t, db)
+	groceries := createTestBudget(t, db, user.ID, 100*models.MoneyScale)
+	coffee := createTestBudget(t, db, user.ID, 20*models.MoneyScale)
+
+	importService := services.NewImportService(expenseService, expenseRepo, budgetRepo,
+		repositories.NewCategoryRepository(db), repositories.NewImportRuleRepository(db), repositories.NewBudgetMemberRepository(db))
+	_, err := importService.CreateRule(user.ID, &requests.CreateImportRuleRequest{Pattern: "kopi", BudgetID: coffee.ID})
+	require.NoError(t, err)
+
+	date := time.Now().Truncate(24 * time.Hour)
+	_, err = expenseService.CreateExpense(user.ID, &requests.CreateExpenseRequest{
+		BudgetID: groceries.ID, Amount: 10 * models.MoneyScale, Description: "Pasar", Date: date,
+	})
+	require.NoError(t, err)
+
+	day := date.Format("2006-01-02")
+	csv := "date,amount,description\n" + day + ",10.00,pasar\n" + day + ",5.00,Kopi Susu\n" + day + ",30.00,Kopi Mahal\n"
+	preview, err := importService.PreviewImport(user.ID, strings.NewReader(csv), "bank.csv", &requests.ImportPreviewRequest{DefaultBudgetID: &groceries.ID})
+	require.NoError(t, err)
+	require.Equal(t, 3, preview.Total)
+	assert.True(t, preview.Rows[0].Duplicate)
+	assert.Equal(t, 1, preview.Duplicates)
+	assert.Equal(t, coffee.ID, *preview.Rows[1].BudgetID)
+
+	commit := &requests.ImportCommitRequest{SkipDuplicates: true}
+	for _, row := range preview.Rows {
+		commit.Rows = append(commit.Rows, requests.ImportRowRequest{
+			Date: row.Date, Amount: row.Amount, Description: row.Description, BudgetID: *row.BudgetID,
+		})
+	}
+
+	// Kopi Mahal melebihi budget kopi, jadi seluruh batch dibatalkan
+	_, err = importService.CommitImport(user.ID, commit)
+	require.EqualError(t, err, "row 3: insufficient budget")
+	spent, err := expenseRepo.SumByBudgetID(coffee.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(0), spent)
+
+	commit.Rows = commit.Rows[:2]
+	result, err := importService.CommitImport(user.ID, commit)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Imported)
+	assert.Equal(t, 1, result.Skipped)
+	assertBudgetConsistent(t, budgetRepo, expenseRepo, coffee.ID)
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyFiles menyimpan private key dan public key dalam format PEM.
+func writeKeyFiles(t *testing.T, name string, privateKey any, publicKey any) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	privatePath := filepath.Join(dir, name+".pem")
+	publicPath := filepath.Join(dir, name+".pub.pem")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600))
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644))
+	return privatePath, publicPath
+}
+
+func TestAsymmetricKeyRotation(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	oldPrivate, oldPublic := writeKeyFiles(t, "old", rsaKey, &rsaKey.PublicKey)
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	newPrivate, _ := writeKeyFiles(t, "new", edPrivate, edPublic)
+
+	oldKeys, err := utils.LoadKeySet(utils.AlgorithmRS256, oldPrivate, "", nil)
+	require.NoError(t, err)
+	userID := uuid.New()
+	oldToken, err := utils.GenerateToken(userID, "a@example.com", oldKeys, time.Minute)
+	require.NoError(t, err)
+
+	// Kunci baru menandatangani, kunci lama masih diterima selama rotasi
+	newKeys, err := utils.LoadKeySet(utils.AlgorithmEdDSA, newPrivate, "2025-01", []string{oldPublic})
+	require.NoError(t, err)
+	claims, err := utils.ValidateToken(oldToken, newKeys)
+	require.NoError(t, err)
+	assert.Equal(t, userID, claims.UserID)
+
+	newToken, err := utils.GenerateToken(userID, "a@example.com", newKeys, time.Minute)
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &utils.JWTClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01", parsed.Header["kid"])
+	assert.Equal(t, "EdDSA", parsed.Header["alg"])
+
+	_, err = utils.ValidateToken(newToken, oldKeys)
+	assert.Error(t, err, "kid tidak dikenal ditolak")
+
+	jwks := newKeys.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "2025-01", jwks.Keys[0].Kid, "kunci penanda tangan tampil pertama")
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+	assert.NotEmpty(t, jwks.Keys[1].N)
+
+	_, err = utils.LoadKeySet(utils.AlgorithmRS256, newPrivate, "", nil)
+	assert.Error(t, err, "algoritma harus cocok dengan jenis kunci")
+}
+
+func TestHMACKeySetRejectsOtherAlgorithms(t *testing.T) {
+	_, err := utils.NewHMACKeySet("")
+	assert.EqualError(t, err, "JWT_SECRET is required for HS256")
+
+	keys := testKeys("secret")
+	assert.Empty(t, keys.JWKS().Keys, "secret HMAC tidak pernah dipublikasikan")
+
+	token, err := utils.GenerateToken(uuid.New(), "a@example.com", keys, time.Minute)
+	require.NoError(t, err)
+	_, err = utils.ValidateToken(token, keys)
+	require.NoError(t, err)
+
+	// Token tanpa tanda tangan (alg none) harus ditolak
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, utils.JWTClaims{}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	_, err = utils.ValidateToken(unsigned, keys)
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/controllers"
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// randomTestIP membuat alamat unik agar percobaan login tes lain tidak ikut terhitung.
+func randomTestIP() string {
+	id := uuid.New()
+	return fmt.Sprintf("198.51.%d.%d", id[0], id[1])
+}
+
+// cleanupLoginAttempts menghapus percobaan login untuk email yang tidak terdaftar.
+func cleanupLoginAttempts(t *testing.T, db *gorm.DB, emails ...string) {
+	t.Cleanup(func() {
+		db.Where("email IN ?", emails).Delete(&models.LoginAttempt{})
+	})
+}
+
+func TestLoginBackoffPerEmailAndIP(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	hashed, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password", string(hashed)).Error)
+
+	policy := services.LoginPolicy{Window: 15 * time.Minute, BackoffBase: time.Hour, BackoffMax: 2 * time.Hour, IPThreshold: 1}
+	authService := newTestAuthServiceWithLoginPolicy(db, testKeys(tokenTestSecret), &recordingMailer{}, services.UnverifiedAllow, policy)
+	e := echo.New()
+	e.POST("/login", controllers.NewAuthController(authService).Login)
+
+	login := func(ip, email, password string) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(requests.LoginRequest{Email: email, Password: password})
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderXForwardedFor, ip)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	attacker, other := randomTestIP(), randomTestIP()
+	unknown := []string{"tidak-ada-" + uuid.NewString() + "@example.com", "tidak-ada-" + uuid.NewString() + "@example.com"}
+	cleanupLoginAttempts(t, db, unknown...)
+
+	assert.Equal(t, http.StatusUnauthorized, login(attacker, user.Email, "salah").Code)
+
+	// Setelah gagal, password yang benar pun harus menunggu
+	rec := login(other, user.Email, "password123")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour.Seconds(), retryAfter, 5)
+
+	// Satu kegagalan per IP masih di bawah ambang, kegagalan kedua tidak
+	assert.Equal(t, http.StatusUnauthorized, login(attacker, unknown[0], "salah").Code)
+	assert.Equal(t, http.StatusTooManyRequests, login(attacker, unknown[1], "salah").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(other, unknown[1], "salah").Code)
+
+	var throttled int64
+	db.Model(&models.LoginAttempt{}).Where("ip = ? AND result = ?", attacker, models.LoginThrottled).Count(&throttled)
+	assert.EqualValues(t, 1, throttled, "percobaan yang ditahan tetap dicatat")
+}
+
+func TestAccountLockoutAndSignInHistory(t *testing.T) {
+	db, user, _, _, _ := setupProfileService(t)
+	mail := &recordingMailer{}
+	policy := services.LoginPolicy{MaxFailures: 3, LockoutDuration: time.Hour, Window: 15 * time.Minute}
+	authService := newTestAuthServiceWithLoginPolicy(db, testKeys(tokenTestSecret), mail, services.UnverifiedAllow, policy)
+	profileService := newTestProfileService(db, authService, mail)
+	ip := randomTestIP()
+
+	_, err := authService.Login(&requests.LoginRequest{Email: user.Email, Password: "password123"}, ip, "Browser/1.0")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = authService.Login(&requests.LoginRequest{Email: user.Email, Password: "salah"}, ip, "Browser/1.0")
+		assert.EqualError(t, err, "invalid credentials")
+	}
+
+	_, err = authService.Login(&requests.LoginRequest{Email: user.Email, Password: "password123"}, ip, "Browser/1.0")
+	var blocked *services.LoginBlockedError
+	require.ErrorAs(t, err, &blocked)
+	assert.Equal(t, "account temporarily locked", blocked.Reason)
+	assert.InDelta(t, time.Hour.Seconds(), blocked.RetryAfter.Seconds(), 5)
+
+	// Reset password membuka kunci akun
+	require.NoError(t, authService.RequestPasswordReset(&requests.ForgotPasswordRequest{Email: user.Email}))
+	require.NoError(t, authService.ResetPassword(&requests.ResetPasswordRequest{Token: linkToken(t, mail.last(t).Body), NewPassword: "rahasia-baru"}))
+	_, err = authService.Login(&requests.LoginRequest{Email: user.Email, Password: "rahasia-baru"}, ip, "Browser/1.0")
+	require.NoError(t, err)
+
+	signIns, err := profileService.GetSignIns(user.ID, &requests.SignInListQuery{Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, signIns, 3)
+	assert.True(t, signIns[0].Success)
+	assert.Equal(t, "success", signIns[0].Result)
+	assert.Equal(t, ip, signIns[0].IP)
+	assert.Equal(t, "Browser/1.0", signIns[0].UserAgent)
+	assert.Equal(t, "locked", signIns[1].Result)
+	assert.Equal(t, "invalid_credentials", signIns[2].Result)
+}
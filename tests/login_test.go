@@ -13,6 +13,7 @@ import (
 	"github.com/Alvarras/dompet-g0/internal/controllers"
 	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
 	"github.com/Alvarras/dompet-g0/internal/dtos/responses"
+	"github.com/Alvarras/dompet-g0/internal/mailer"
 	"github.com/Alvarras/dompet-g0/internal/models"
 	"github.com/Alvarras/dompet-g0/internal/repositories"
 	"github.com/Alvarras/dompet-g0/internal/services"
@@ -142,7 +143,8 @@ func setupTestServer() (*echo.Echo, func()) {
 
 	userRepo := repositories.NewUserRepository(db)
 	jwtDuration, _ := time.ParseDuration(getEnv("JWT_EXPIRATION_TEST", "15m"))
-	authService := services.NewAuthService(userRepo, getEnv("JWT_SECRET_TEST", "test-secret-key-e2e"), jwtDuration)
+	authService := services.NewAuthService(db, userRepo, repositories.NewRefreshTokenRepository(db), repositories.NewRevokedTokenRepository(db),
+		repositories.NewUserTokenRepository(db), repositories.NewRecoveryCodeRepository(db), repositories.NewLoginAttemptRepository(db), mailer.LogMailer{}, testKeys(getEnv("JWT_SECRET_TEST", "test-secret-key-e2e")), jwtDuration, 24*time.Hour, "", services.UnverifiedAllow, services.LoginPolicy{})
 	authController := controllers.NewAuthController(authService)
 
 	e := echo.New()
@@ -150,6 +152,7 @@ func setupTestServer() (*echo.Echo, func()) {
 
 	cleanup := func() {
 		// Hapus pengguna tes untuk menjaga kebersihan antar test run.
+		db.Where("user_id = ?", testUserID).Delete(&models.RefreshToken{})
 		if err := db.Unscoped().Delete(&models.User{}, "id = ?", testUserID).Error; err != nil {
 			fmt.Printf("Peringatan: Gagal menghapus pengguna tes (ID: %s): %v\n", testUserID, err)
 		}
@@ -177,7 +180,7 @@ func setupTestDB() (*gorm.DB, uuid.UUID) {
 		panic(fmt.Sprintf("Kritis: Gagal terhubung ke database tes '%s': %v", dbName, err))
 	}
 
-	if err := db.AutoMigrate(&models.User{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.RefreshToken{}); err != nil {
 		panic(fmt.Sprintf("Kritis: Gagal AutoMigrate User: %v", err))
 	}
 
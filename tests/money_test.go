@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := map[string]models.Money{
+		"0":       0,
+		"12":      1200,
+		"12.5":    1250,
+		"12.05":   1205,
+		"-0.05":   -5,
+		"+3.10":   310,
+		".75":     75,
+		"1000000": 100000000,
+	}
+	for input, expected := range cases {
+		m, err := models.ParseMoney(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, m, input)
+	}
+
+	for _, input := range []string{"", "-", "12.", "1.005", "abc", "1e3", "1,5"} {
+		_, err := models.ParseMoney(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestMoneyUnmarshalText(t *testing.T) {
+	var m models.Money
+	require.NoError(t, m.UnmarshalText([]byte("10.50")))
+	assert.Equal(t, models.Money(1050), m)
+	assert.Error(t, m.UnmarshalText([]byte("10.505")))
+}
+
+func TestMoneyHasNoFloatDrift(t *testing.T) {
+	a, _ := models.ParseMoney("0.1")
+	b, _ := models.ParseMoney("0.2")
+	assert.Equal(t, "0.30", (a + b).String())
+
+	var total models.Money
+	for i := 0; i < 1000; i++ {
+		total += a
+	}
+	assert.Equal(t, "100.00", total.String())
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var payload struct {
+		Amount models.Money `json:"amount"`
+		Spent  models.Money `json:"spent"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": 150000.25, "spent": "0.10"}`), &payload))
+	assert.Equal(t, models.Money(15000025), payload.Amount)
+	assert.Equal(t, models.Money(10), payload.Spent)
+
+	out, err := json.Marshal(payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 150000.25, "spent": 0.10}`, string(out))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": 1.999}`), &payload))
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/config"
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordResetFlow(t *testing.T) {
+	_, user, authService, _, mail := setupProfileService(t)
+
+	// Alamat yang tidak terdaftar tidak dibedakan dari yang terdaftar
+	require.NoError(t, authService.RequestPasswordReset(&requests.ForgotPasswordRequest{Email: "tidak-ada@example.com"}))
+	assert.Empty(t, mail.messages)
+
+	session, err := authService.Login(&requests.LoginRequest{Email: user.Email, Password: "password123"}, testIP, "")
+	require.NoError(t, err)
+
+	require.NoError(t, authService.RequestPasswordReset(&requests.ForgotPasswordRequest{Email: user.Email}))
+	first := linkToken(t, mail.last(t).Body)
+	require.NoError(t, authService.RequestPasswordReset(&requests.ForgotPasswordRequest{Email: user.Email}))
+	message := mail.last(t)
+	assert.Equal(t, user.Email, message.To)
+	assert.Contains(t, message.Body, "https://app.example.com/reset-password?token=")
+	token := linkToken(t, message.Body)
+
+	err = authService.ResetPassword(&requests.ResetPasswordRequest{Token: first, NewPassword: "rahasia-baru"})
+	assert.EqualError(t, err, "invalid or expired token", "hanya tautan terakhir yang berlaku")
+
+	require.NoError(t, authService.ResetPassword(&requests.ResetPasswordRequest{Token: token, NewPassword: "rahasia-baru"}))
+	err = authService.ResetPassword(&requests.ResetPasswordRequest{Token: token, NewPassword: "lain-lagi"})
+	assert.EqualError(t, err, "invalid or expired token", "token hanya bisa dipakai sekali")
+
+	_, err = authService.Refresh(&requests.RefreshTokenRequest{RefreshToken: session.RefreshToken})
+	assert.Error(t, err, "sesi lama dicabut")
+
+	_, err = authService.Login(&requests.LoginRequest{Email: user.Email, Password: "rahasia-baru"}, testIP, "")
+	assert.NoError(t, err)
+}
+
+func TestFileMailerWritesEML(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender, err := mailer.New(config.MailConfig{Driver: "file", FileDir: dir, From: "Dompet <no-reply@example.com>"})
+	require.NoError(t, err)
+
+	require.NoError(t, sender.Send(mailer.Message{To: "a@example.com", Subject: "Reset password", Body: "Halo\nbaris dua\n"}))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, ".eml", filepath.Ext(files[0].Name()))
+
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "To: a@example.com\r\n")
+	assert.Contains(t, string(data), "\r\n\r\nHalo\r\nbaris dua\r\n")
+
+	_, err = mailer.New(config.MailConfig{Driver: "smtp"})
+	assert.Error(t, err, "driver smtp wajib punya host")
+}
+
+// fakeSMTPServer menerima satu email tanpa TLS dan auth, lalu mengirim isi DATA ke channel.
+func fakeSMTPServer(t *testing.T) (string, string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 ok")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	return host, port, received
+}
+
+func TestSMTPMailerDelivers(t *testing.T) {
+	host, port, received := fakeSMTPServer(t)
+	sender, err := mailer.New(config.MailConfig{Driver: "smtp", SMTPHost: host, SMTPPort: port, From: "Dompet <no-reply@example.com>"})
+	require.NoError(t, err)
+
+	require.NoError(t, sender.Send(mailer.Message{To: "a@example.com", Subject: "Konfirmasi", Body: "Halo\n"}))
+	data := <-received
+	assert.Contains(t, data, "From: Dompet <no-reply@example.com>\r\n")
+	assert.Contains(t, data, "Subject: Konfirmasi\r\n")
+	assert.True(t, strings.HasSuffix(data, "\r\n\r\nHalo\r\n"))
+}
@@ -0,0 +1,239 @@
+package tests
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/mailer"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// recordingMailer menyimpan email yang dikirim agar bisa diperiksa tes.
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *recordingMailer) Send(msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func (m *recordingMailer) last(t *testing.T) mailer.Message {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	require.NotEmpty(t, m.messages)
+	return m.messages[len(m.messages)-1]
+}
+
+// linkToken mengambil parameter token dari tautan di isi email.
+func linkToken(t *testing.T, body string) string {
+	t.Helper()
+	for _, field := range strings.Fields(body) {
+		if link, err := url.Parse(field); err == nil && link.Query().Get("token") != "" {
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("tidak ada tautan token di email: %s", body)
+	return ""
+}
+
+func setupProfileService(t *testing.T) (*gorm.DB, *models.User, *services.AuthService, *services.ProfileService, *recordingMailer) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password", string(hashed)).Error)
+
+	mail := &recordingMailer{}
+	authService := newTestAuthService(db, testKeys(tokenTestSecret), mail)
+	return db, user, authService, newTestProfileService(db, authService, mail), mail
+}
+
+func newTestProfileService(db *gorm.DB, authService *services.AuthService, mail mailer.Mailer) *services.ProfileService {
+	return services.NewProfileService(db, repositories.NewUserRepository(db), repositories.NewUserTokenRepository(db),
+		repositories.NewLoginAttemptRepository(db), repositories.NewWorkspaceRepository(db), repositories.NewWorkspaceMemberRepository(db),
+		authService, newTestTransactionService(db), mail, "https://app.example.com/")
+}
+
+func newTestTransactionService(db *gorm.DB) *services.TransactionService {
+	budgetRepo := repositories.NewBudgetRepository(db)
+	expenseRepo := repositories.NewExpenseRepository(db)
+	historyRepo := repositories.NewBudgetHistoryRepository(db)
+	memberRepo := repositories.NewBudgetMemberRepository(db)
+	alertRepo := repositories.NewBudgetAlertRepository(db)
+	expenseService := services.NewExpenseService(db, expenseRepo, budgetRepo, historyRepo, repositories.NewCategoryRepository(db), repositories.NewTagRepository(db), memberRepo, repositories.NewExpenseApprovalRepository(db), alertRepo)
+	return services.NewTransactionService(db, expenseService, expenseRepo, budgetRepo, historyRepo,
+		repositories.NewIncomeRepository(db), repositories.NewTransferRepository(db), memberRepo, alertRepo)
+}
+
+func TestChangePasswordRevokesExistingTokens(t *testing.T) {
+	_, user, authService, profileService, _ := setupProfileService(t)
+
+	session, err := authService.Login(&requests.LoginRequest{Email: user.Email, Password: "password123"}, testIP, "")
+	require.NoError(t, err)
+
+	_, err = profileService.ChangePassword(user.ID, &requests.ChangePasswordRequest{CurrentPassword: "salah", NewPassword: "rahasia-baru"}, testIP, "")
+	require.EqualError(t, err, "invalid password")
+
+	// Token lama ditolak walau terbit di detik yang sama dengan pencabutan
+	fresh, err := profileService.ChangePassword(user.ID, &requests.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "rahasia-baru"}, testIP, "")
+	require.NoError(t, err)
+
+	keys := testKeys(tokenTestSecret)
+	oldClaims, err := utils.ValidateToken(session.Token, keys)
+	require.NoError(t, err)
+	revoked, err := authService.IsTokenRevoked(oldClaims)
+	require.NoError(t, err)
+	assert.True(t, revoked, "access token lama tidak berlaku lagi")
+
+	newClaims, err := utils.ValidateToken(fresh.Token, keys)
+	require.NoError(t, err)
+	revoked, err = authService.IsTokenRevoked(newClaims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	_, err = authService.Refresh(&requests.RefreshTokenRequest{RefreshToken: session.RefreshToken})
+	assert.Error(t, err, "refresh token lama dicabut")
+
+	_, err = authService.Login(&requests.LoginRequest{Email: user.Email, Password: "rahasia-baru"}, testIP, "")
+	assert.NoError(t, err)
+}
+
+func TestEmailChangeNeedsConfirmation(t *testing.T) {
+	_, user, _, profileService, mail := setupProfileService(t)
+	oldEmail := user.Email
+	newEmail := "baru-" + user.Email
+
+	// Alamat baru disimpan dalam bentuk yang sama dengan yang dipakai login
+	err := profileService.RequestEmailChange(user.ID, &requests.ChangeEmailRequest{NewEmail: " " + strings.ToUpper(newEmail) + " ", Password: "password123"}, testIP, "")
+	require.NoError(t, err)
+	message := mail.last(t)
+	assert.Equal(t, newEmail, message.To)
+	assert.Contains(t, message.Body, "https://app.example.com/confirm-email?token=")
+
+	profile, err := profileService.GetProfile(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, oldEmail, profile.Email, "email belum berubah sebelum dikonfirmasi")
+
+	token := linkToken(t, message.Body)
+	profile, err = profileService.ConfirmEmailChange(&requests.ConfirmEmailRequest{Token: token})
+	require.NoError(t, err)
+	assert.Equal(t, newEmail, profile.Email)
+
+	err = profileService.RequestEmailChange(user.ID, &requests.ChangeEmailRequest{NewEmail: strings.ToUpper(newEmail), Password: "password123"}, testIP, "")
+	assert.EqualError(t, err, "email is unchanged", "beda huruf besar-kecil tetap alamat yang sama")
+	assert.Equal(t, oldEmail, mail.last(t).To, "alamat lama mendapat pemberitahuan")
+
+	_, err = profileService.ConfirmEmailChange(&requests.ConfirmEmailRequest{Token: token})
+	assert.EqualError(t, err, "invalid or expired token", "token hanya bisa dipakai sekali")
+}
+
+func TestDeleteAccountRemovesAllData(t *testing.T) {
+	db, user, _, profileService, _ := setupProfileService(t)
+	budget := createTestBudget(t, db, user.ID, 100*models.MoneyScale)
+	require.NoError(t, db.Create(&models.Expense{ID: uuid.New(), UserID: user.ID, BudgetID: budget.ID, Amount: 5 * models.MoneyScale, Date: time.Now()}).Error)
+
+	err := profileService.DeleteAccount(user.ID, &requests.DeleteAccountRequest{Password: "salah"}, testIP, "")
+	require.EqualError(t, err, "invalid password")
+
+	require.NoError(t, profileService.DeleteAccount(user.ID, &requests.DeleteAccountRequest{Password: "password123"}, testIP, ""))
+
+	var users, budgets, expenses int64
+	db.Unscoped().Model(&models.User{}).Where("id = ?", user.ID).Count(&users)
+	db.Unscoped().Model(&models.Budget{}).Where("user_id = ?", user.ID).Count(&budgets)
+	db.Unscoped().Model(&models.Expense{}).Where("user_id = ?", user.ID).Count(&expenses)
+	assert.Zero(t, users)
+	assert.Zero(t, budgets)
+	assert.Zero(t, expenses)
+}
+
+func TestDeleteAccountInSharedWorkspace(t *testing.T) {
+	db, user, _, profileService, _ := setupProfileService(t)
+	budgetRepo := repositories.NewBudgetRepository(db)
+	expenseRepo := repositories.NewExpenseRepository(db)
+	workspaceRepo := repositories.NewWorkspaceRepository(db)
+	workspaceService := services.NewWorkspaceService(db, workspaceRepo, repositories.NewWorkspaceMemberRepository(db), repositories.NewBudgetMemberRepository(db), repositories.NewUserRepository(db))
+	budgetService := services.NewBudgetService(db, budgetRepo, expenseRepo, repositories.NewBudgetHistoryRepository(db), repositories.NewBudgetMemberRepository(db), repositories.NewBudgetAlertRepository(db))
+	transactionService := newTestTransactionService(db)
+	partner := createTestUser(t, db)
+
+	team, err := workspaceService.CreateWorkspace(user.ID, &requests.CreateWorkspaceRequest{Name: "Rumah Tangga"})
+	require.NoError(t, err)
+	t.Cleanup(func() { workspaceRepo.WithTx(db).DeleteWithData(team.ID) })
+	solo, err := workspaceService.CreateWorkspace(user.ID, &requests.CreateWorkspaceRequest{Name: "Sampingan"})
+	require.NoError(t, err)
+	_, err = workspaceService.AddMember(user.ID, team.ID, &requests.AddWorkspaceMemberRequest{Email: partner.Email, Role: models.WorkspaceRoleEditor})
+	require.NoError(t, err)
+
+	// Pemilik terakhir tidak boleh menghapus akun selagi anggota lain masih ada
+	err = profileService.DeleteAccount(user.ID, &requests.DeleteAccountRequest{Password: "password123"}, testIP, "")
+	require.EqualError(t, err, "transfer workspace ownership first")
+
+	_, err = workspaceService.UpdateMemberRole(user.ID, team.ID, partner.ID, &requests.UpdateWorkspaceMemberRequest{Role: models.WorkspaceRoleOwner})
+	require.NoError(t, err)
+
+	teamScope := services.WorkspaceScope{ID: team.ID, Role: models.WorkspaceRoleOwner}
+	shared, err := budgetService.InWorkspace(teamScope).CreateBudget(partner.ID, &requests.CreateBudgetRequest{Name: "Dapur", Amount: 1000 * models.MoneyScale})
+	require.NoError(t, err)
+	own, err := budgetService.InWorkspace(teamScope).CreateBudget(user.ID, &requests.CreateBudgetRequest{Name: "Tabungan", Amount: 500 * models.MoneyScale})
+	require.NoError(t, err)
+	_, err = transactionService.InWorkspace(teamScope).CreateTransaction(user.ID, &requests.CreateTransactionRequest{
+		Type: models.TransactionIncome, BudgetID: &shared.ID, TopUpBudget: true, Amount: 300 * models.MoneyScale,
+	})
+	require.NoError(t, err)
+	_, err = transactionService.InWorkspace(teamScope).CreateTransaction(user.ID, &requests.CreateTransactionRequest{
+		Type: models.TransactionTransfer, BudgetID: &own.ID, ToBudgetID: &shared.ID, Amount: 100 * models.MoneyScale,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, profileService.DeleteAccount(user.ID, &requests.DeleteAccountRequest{Password: "password123"}, testIP, ""))
+
+	// Alokasi dari pemasukan dan transfer yang ikut terhapus dikembalikan
+	kept, err := budgetRepo.FindByID(shared.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(1000*models.MoneyScale), kept.Amount)
+	handed, err := budgetRepo.FindByID(own.ID)
+	require.NoError(t, err)
+	assert.Equal(t, partner.ID, handed.UserID)
+	assert.Equal(t, models.Money(500*models.MoneyScale), handed.Amount)
+
+	// Workspace yang hanya berisi pengguna ini ikut terhapus
+	_, err = workspaceRepo.FindByID(solo.ID)
+	assert.Error(t, err)
+}
+
+func TestProfilePasswordChecksShareLoginLockout(t *testing.T) {
+	_, user, authService, profileService, _ := setupProfileService(t)
+
+	// Menebak password lewat sesi yang dicuri dihitung seperti login gagal
+	for i := 0; i < testLoginPolicy.MaxFailures; i++ {
+		_, err := profileService.ChangePassword(user.ID, &requests.ChangePasswordRequest{CurrentPassword: "tebakan", NewPassword: "rahasia-baru"}, testIP, "")
+		require.EqualError(t, err, "invalid password")
+	}
+
+	var blocked *services.LoginBlockedError
+	err := profileService.DeleteAccount(user.ID, &requests.DeleteAccountRequest{Password: "password123"}, testIP, "")
+	require.ErrorAs(t, err, &blocked)
+	assert.Equal(t, "account temporarily locked", blocked.Reason)
+	err = profileService.RequestEmailChange(user.ID, &requests.ChangeEmailRequest{NewEmail: "baru@example.com", Password: "password123"}, testIP, "")
+	require.ErrorAs(t, err, &blocked)
+	_, err = authService.Login(&requests.LoginRequest{Email: user.Email, Password: "password123"}, testIP, "")
+	require.ErrorAs(t, err, &blocked)
+}
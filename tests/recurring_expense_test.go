@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurringExpenseScheduler(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	memberRepo := repositories.NewBudgetMemberRepository(db)
+	workspaceService := services.NewWorkspaceService(db, repositories.NewWorkspaceRepository(db), repositories.NewWorkspaceMemberRepository(db), memberRepo, repositories.NewUserRepository(db))
+	recurringRepo := repositories.NewRecurringExpenseRepository(db)
+	recurringService := services.NewRecurringExpenseService(db, recurringRepo, budgetRepo, repositories.NewCategoryRepository(db), memberRepo, expenseService, workspaceService)
+
+	owner := createTestUser(t, db)
+	outsider := createTestUser(t, db)
+	personal := services.WorkspaceScope{ID: owner.ID, Role: models.WorkspaceRoleOwner}
+	budget := &models.Budget{ID: uuid.New(), WorkspaceID: owner.ID, UserID: owner.ID, Name: "Rumah", Amount: 1000 * models.MoneyScale}
+	require.NoError(t, db.Create(budget).Error)
+	scoped := recurringService.InWorkspace(personal)
+
+	spent := func() models.Money {
+		t.Helper()
+		stored, err := budgetRepo.FindByID(budget.ID)
+		require.NoError(t, err)
+		return stored.Spent
+	}
+
+	// Sewa bulanan di akhir bulan, empat kali
+	start := time.Date(2026, 1, 31, 9, 0, 0, 0, time.Local)
+	rent, err := scoped.CreateRecurringExpense(owner.ID, &requests.CreateRecurringExpenseRequest{
+		BudgetID:    budget.ID,
+		Amount:      100 * models.MoneyScale,
+		Description: "Sewa",
+		Frequency:   models.RecurrenceMonthly,
+		StartDate:   start,
+		Count:       4,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;COUNT=4", rent.RRule)
+	require.NotNil(t, rent.NextDate)
+	assert.True(t, start.Equal(*rent.NextDate))
+
+	january := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+	upcoming, err := scoped.GetUpcomingOccurrences(owner.ID, &requests.UpcomingOccurrencesQuery{Days: 90}, january)
+	require.NoError(t, err)
+	require.Equal(t, 3, upcoming.Total)
+	// Bulan yang lebih pendek jatuh di hari terakhirnya
+	assert.Equal(t, time.February, upcoming.Occurrences[1].Date.Month())
+	assert.Equal(t, 28, upcoming.Occurrences[1].Date.Day())
+
+	// Lewati Februari dan ubah nominal Maret saja
+	_, err = scoped.SkipOccurrence(owner.ID, rent.ID, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	amount := models.Money(150 * models.MoneyScale)
+	edited, err := scoped.UpdateOccurrence(owner.ID, rent.ID, time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC), &requests.UpdateOccurrenceRequest{Amount: &amount})
+	require.NoError(t, err)
+	assert.True(t, edited.Edited)
+	_, err = scoped.SkipOccurrence(owner.ID, rent.ID, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.EqualError(t, err, "occurrence not found")
+	_, err = recurringService.InWorkspace(services.WorkspaceScope{ID: outsider.ID, Role: models.WorkspaceRoleOwner}).SkipOccurrence(outsider.ID, rent.ID, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC))
+	assert.Error(t, err)
+
+	upcoming, err = scoped.GetUpcomingOccurrences(owner.ID, &requests.UpcomingOccurrencesQuery{Days: 90}, january)
+	require.NoError(t, err)
+	require.Equal(t, 3, upcoming.Total)
+	assert.Equal(t, models.OccurrenceSkipped, upcoming.Occurrences[1].Status)
+	assert.Equal(t, amount, upcoming.Occurrences[2].Amount)
+
+	// Penjadwal membukukan yang sudah jatuh tempo, kecuali yang dilewati
+	april := time.Date(2026, 4, 15, 0, 0, 0, 0, time.Local)
+	booked, err := recurringService.BookDueOccurrences(april)
+	require.NoError(t, err)
+	assert.Equal(t, 2, booked)
+	assert.Equal(t, models.Money(250*models.MoneyScale), spent())
+
+	// Dijalankan ulang (misalnya setelah restart) tidak membukukan dua kali
+	booked, err = recurringService.BookDueOccurrences(april)
+	require.NoError(t, err)
+	assert.Equal(t, 0, booked)
+	assert.Equal(t, models.Money(250*models.MoneyScale), spent())
+	assertBudgetConsistent(t, budgetRepo, expenseRepo, budget.ID)
+
+	expenses, err := expenseService.InWorkspace(personal).GetExpenses(owner.ID, &requests.ExpenseListQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 2, expenses.Total)
+	assert.Equal(t, "Sewa", expenses.Expenses[0].Description)
+
+	// Setelah kejadian keempat jadwal selesai
+	booked, err = recurringService.BookDueOccurrences(time.Date(2026, 5, 1, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Equal(t, 1, booked)
+	list, err := scoped.GetRecurringExpenses(owner.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, list.Total)
+	assert.Nil(t, list.RecurringExpenses[0].NextDate)
+
+	// Kejadian yang melebihi sisa budget dicatat gagal dan tidak diulang
+	endDate := time.Date(2026, 6, 2, 23, 0, 0, 0, time.Local)
+	large, err := scoped.CreateRecurringExpense(owner.ID, &requests.CreateRecurringExpenseRequest{
+		BudgetID:  budget.ID,
+		Amount:    900 * models.MoneyScale,
+		Frequency: models.RecurrenceDaily,
+		StartDate: time.Date(2026, 6, 1, 9, 0, 0, 0, time.Local),
+		EndDate:   &endDate,
+	})
+	require.NoError(t, err)
+	booked, err = recurringService.BookDueOccurrences(time.Date(2026, 6, 10, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Equal(t, 0, booked)
+
+	var failed int64
+	require.NoError(t, db.Model(&models.RecurringOccurrence{}).Where("recurring_expense_id = ? AND status = ?", large.ID, models.OccurrenceFailed).Count(&failed).Error)
+	assert.Equal(t, int64(2), failed)
+	stored, err := recurringRepo.FindByID(large.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.NextDate)
+
+	_, err = scoped.CreateRecurringExpense(owner.ID, &requests.CreateRecurringExpenseRequest{
+		BudgetID:  budget.ID,
+		Amount:    10 * models.MoneyScale,
+		Frequency: models.RecurrenceWeekly,
+		StartDate: time.Date(2026, 6, 1, 9, 0, 0, 0, time.Local),
+		EndDate:   &start,
+	})
+	assert.EqualError(t, err, "end date is before start date")
+}
+
+func TestRecurringExpenseSchedulerContinuesAfterFailure(t *testing.T) {
+	db, expenseService, budgetRepo, _ := setupExpenseService(t)
+	memberRepo := repositories.NewBudgetMemberRepository(db)
+	workspaceService := services.NewWorkspaceService(db, repositories.NewWorkspaceRepository(db), repositories.NewWorkspaceMemberRepository(db), memberRepo, repositories.NewUserRepository(db))
+	recurringRepo := repositories.NewRecurringExpenseRepository(db)
+	recurringService := services.NewRecurringExpenseService(db, recurringRepo, budgetRepo, repositories.NewCategoryRepository(db), memberRepo, expenseService, workspaceService)
+
+	owner := createTestUser(t, db)
+	budget := &models.Budget{ID: uuid.New(), WorkspaceID: owner.ID, UserID: owner.ID, Name: "Rumah", Amount: 1000 * models.MoneyScale}
+	require.NoError(t, db.Create(budget).Error)
+
+	template := func(date time.Time, tags []string) *models.RecurringExpense {
+		t.Helper()
+		recurring := &models.RecurringExpense{
+			ID: uuid.New(), WorkspaceID: owner.ID, UserID: owner.ID, BudgetID: budget.ID,
+			Amount: 10 * models.MoneyScale, Tags: tags, Frequency: models.RecurrenceMonthly, Interval: 1, StartDate: date, Count: 1,
+		}
+		recurring.Advance(0)
+		require.NoError(t, recurringRepo.Create(recurring))
+		return recurring
+	}
+
+	// Tag terlalu panjang membuat penyimpanan gagal, bukan penolakan biasa
+	broken := template(time.Date(2026, 7, 1, 9, 0, 0, 0, time.Local), []string{strings.Repeat("x", 80)})
+	healthy := template(time.Date(2026, 7, 2, 9, 0, 0, 0, time.Local), nil)
+
+	booked, err := recurringService.BookDueOccurrences(time.Date(2026, 7, 10, 0, 0, 0, 0, time.Local))
+	assert.Error(t, err)
+	assert.Equal(t, 1, booked, "template lain tetap dibukukan")
+
+	stored, err := recurringRepo.FindByID(broken.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, stored.LastError)
+	require.NotNil(t, stored.LastFailedAt)
+	require.NotNil(t, stored.NextDate, "kejadian yang gagal diulang pada run berikutnya")
+	stored, err = recurringRepo.FindByID(healthy.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.NextDate)
+	assert.Empty(t, stored.LastError)
+}
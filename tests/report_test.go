@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportSummaryAggregatesInSQL(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	reportService := services.NewReportService(repositories.NewExpenseRepository(db))
+
+	food := createTestBudget(t, db, user.ID, 1000*models.MoneyScale)
+	transport := createTestBudget(t, db, user.ID, 1000*models.MoneyScale)
+
+	for _, expense := range []models.Expense{
+		{BudgetID: food.ID, Amount: 100 * models.MoneyScale, Date: time.Date(2025, 2, 10, 12, 0, 0, 0, time.Local)},
+		{BudgetID: food.ID, Amount: 50 * models.MoneyScale, Date: time.Date(2025, 3, 3, 12, 0, 0, 0, time.Local)},
+		{BudgetID: food.ID, Amount: 25 * models.MoneyScale, Date: time.Date(2025, 3, 4, 12, 0, 0, 0, time.Local)},
+		{BudgetID: transport.ID, Amount: 10 * models.MoneyScale, Date: time.Date(2025, 3, 20, 12, 0, 0, 0, time.Local)},
+	} {
+		expense.ID = uuid.New()
+		expense.UserID = user.ID
+		require.NoError(t, db.Create(&expense).Error)
+	}
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local)
+	summary, err := reportService.GetSummary(user.ID, &requests.ReportSummaryQuery{From: &from, To: &to, GroupBy: "week"}, time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, models.Money(85*models.MoneyScale), summary.Total)
+	assert.Equal(t, 3, summary.Count)
+	assert.Equal(t, models.Money(2833), summary.Average)
+	require.Len(t, summary.Groups, 2)
+	assert.Equal(t, "2025-03-03", summary.Groups[0].Key)
+	assert.Equal(t, models.Money(75*models.MoneyScale), summary.Groups[0].Total)
+
+	require.Len(t, summary.TopBudgets, 2)
+	assert.Equal(t, food.ID.String(), summary.TopBudgets[0].Key)
+
+	// Periode pembanding sama panjang (31 hari) tepat sebelum Maret
+	assert.Equal(t, models.Money(100*models.MoneyScale), summary.PreviousTotal)
+	assert.Equal(t, models.Money(-15*models.MoneyScale), summary.Change)
+	require.NotNil(t, summary.ChangePercent)
+	assert.Equal(t, -15.0, *summary.ChangePercent)
+
+	_, err = reportService.GetSummary(user.ID, &requests.ReportSummaryQuery{From: &to, To: &from}, time.Now())
+	assert.EqualError(t, err, "invalid date range")
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/migrations"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/google/uuid"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB menghubungkan ke database tes dan menjalankan migrasi seluruh model.
+// Tes dilewati (skip) jika database tes tidak tersedia.
+func openTestDB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		getEnv("DB_USER", "root"),
+		getEnv("DB_PASSWORD", "password"),
+		getEnv("DB_HOST", "localhost"),
+		getEnv("DB_PORT", "3306"),
+		getEnv("DB_NAME", "go_budget_test"),
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Skipf("database tes tidak tersedia: %v", err)
+	}
+
+	if err := repositories.RegisterWorkspaceScope(db); err != nil {
+		t.Fatalf("gagal memasang pembatas workspace: %v", err)
+	}
+
+	if err := migrations.Run(db); err != nil {
+		t.Fatalf("gagal migrasi database tes: %v", err)
+	}
+
+	return db
+}
+
+// createTestUser membuat pengguna tes baru dan mendaftarkan penghapusan datanya di akhir tes.
+func createTestUser(t testing.TB, db *gorm.DB) *models.User {
+	t.Helper()
+
+	user := &models.User{
+		ID:       uuid.New(),
+		Email:    fmt.Sprintf("test-%s@example.com", uuid.NewString()),
+		Password: "not-a-real-hash",
+		Name:     "Test User",
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("gagal membuat pengguna tes: %v", err)
+	}
+
+	t.Cleanup(func() {
+		budgetIDs := db.Unscoped().Model(&models.Budget{}).Select("id").Where("user_id = ?", user.ID)
+		expenseIDs := db.Unscoped().Model(&models.Expense{}).Select("id").Where("user_id = ?", user.ID)
+
+		db.Exec("DELETE FROM expense_tags WHERE expense_id IN (?)", expenseIDs)
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Expense{})
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Income{})
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Transfer{})
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.ImportRule{})
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Tag{})
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Category{})
+		db.Unscoped().Where("budget_id IN (?)", budgetIDs).Delete(&models.BudgetHistory{})
+		db.Where("user_id = ? OR budget_id IN (?)", user.ID, budgetIDs).Delete(&models.BudgetMember{})
+		db.Where("budget_id IN (?)", budgetIDs).Delete(&models.BudgetInvitation{})
+		recurringIDs := db.Model(&models.RecurringExpense{}).Select("id").Where("user_id = ? OR budget_id IN (?)", user.ID, budgetIDs)
+		db.Where("recurring_expense_id IN (?)", recurringIDs).Delete(&models.RecurringOccurrence{})
+		db.Where("user_id = ? OR budget_id IN (?)", user.ID, budgetIDs).Delete(&models.RecurringExpense{})
+		alertIDs := db.Model(&models.BudgetAlert{}).Select("id").Where("user_id = ? OR budget_id IN (?)", user.ID, budgetIDs)
+		db.Where("alert_id IN (?) OR budget_id IN (?)", alertIDs, budgetIDs).Delete(&models.WebhookDelivery{})
+		db.Where("user_id = ? OR budget_id IN (?)", user.ID, budgetIDs).Delete(&models.BudgetAlert{})
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Budget{})
+		db.Where("user_id = ?", user.ID).Delete(&models.RefreshToken{})
+		db.Where("user_id = ?", user.ID).Delete(&models.RevokedToken{})
+		db.Where("user_id = ?", user.ID).Delete(&models.UserToken{})
+		db.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{})
+		db.Where("email = ?", user.Email).Delete(&models.LoginAttempt{})
+		db.Where("user_id = ?", user.ID).Delete(&models.APIKey{})
+		db.Where("user_id = ? OR workspace_id = ?", user.ID, user.ID).Delete(&models.WorkspaceMember{})
+		db.Delete(&models.Workspace{}, "id = ?", user.ID)
+		db.Unscoped().Delete(&models.User{}, "id = ?", user.ID)
+	})
+
+	return user
+}
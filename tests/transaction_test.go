@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferAndIncomeMoveBudgetAllocation(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	user := createTestUser(t, db)
+	groceries := createTestBudget(t, db, user.ID, 100*models.MoneyScale)
+	savings := createTestBudget(t, db, user.ID, 50*models.MoneyScale)
+
+	transactionService := services.NewTransactionService(db, expenseService, expenseRepo, budgetRepo,
+		repositories.NewBudgetHistoryRepository(db), repositories.NewIncomeRepository(db), repositories.NewTransferRepository(db), repositories.NewBudgetMemberRepository(db), repositories.NewBudgetAlertRepository(db))
+
+	_, err := transactionService.CreateTransaction(user.ID, &requests.CreateTransactionRequest{
+		Type: models.TransactionExpense, BudgetID: &groceries.ID, Amount: 70 * models.MoneyScale,
+	})
+	require.NoError(t, err)
+
+	// Hanya sisa budget yang boleh dipindahkan
+	_, err = transactionService.CreateTransaction(user.ID, &requests.CreateTransactionRequest{
+		Type: models.TransactionTransfer, BudgetID: &groceries.ID, ToBudgetID: &savings.ID, Amount: 40 * models.MoneyScale,
+	})
+	require.EqualError(t, err, "insufficient budget")
+
+	transfer, err := transactionService.CreateTransaction(user.ID, &requests.CreateTransactionRequest{
+		Type: models.TransactionTransfer, BudgetID: &groceries.ID, ToBudgetID: &savings.ID, Amount: 30 * models.MoneyScale,
+	})
+	require.NoError(t, err)
+
+	from, err := budgetRepo.FindByID(groceries.ID)
+	require.NoError(t, err)
+	to, err := budgetRepo.FindByID(savings.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(70*models.MoneyScale), from.Amount)
+	assert.Equal(t, models.Money(80*models.MoneyScale), to.Amount)
+
+	_, err = transactionService.CreateTransaction(user.ID, &requests.CreateTransactionRequest{
+		Type: models.TransactionIncome, BudgetID: &savings.ID, TopUpBudget: true, Amount: 20 * models.MoneyScale,
+	})
+	require.NoError(t, err)
+	to, err = budgetRepo.FindByID(savings.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(100*models.MoneyScale), to.Amount)
+
+	ledger, err := transactionService.GetLedger(user.ID, &requests.TransactionListQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, ledger.Total)
+	assert.Equal(t, models.Money(20*models.MoneyScale), ledger.TotalIncome)
+	assert.Equal(t, models.Money(70*models.MoneyScale), ledger.TotalExpense)
+	assert.Equal(t, models.Money(-50*models.MoneyScale), ledger.NetCashFlow)
+
+	// Halaman ledger memakai cursor; total tetap menghitung semua transaksi
+	first, err := transactionService.GetLedger(user.ID, &requests.TransactionListQuery{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.Transactions, 2)
+	assert.Equal(t, 3, first.Total)
+	assert.Equal(t, models.Money(20*models.MoneyScale), first.TotalIncome)
+	assert.Equal(t, models.Money(70*models.MoneyScale), first.TotalExpense)
+	require.NotEmpty(t, first.NextCursor)
+	second, err := transactionService.GetLedger(user.ID, &requests.TransactionListQuery{Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, second.Transactions, 1)
+	assert.Empty(t, second.NextCursor)
+	seen := map[uuid.UUID]bool{}
+	for _, transaction := range append(first.Transactions, second.Transactions...) {
+		seen[transaction.ID] = true
+	}
+	assert.Len(t, seen, 3, "setiap transaksi muncul tepat sekali")
+	_, err = transactionService.GetLedger(user.ID, &requests.TransactionListQuery{Cursor: "rusak"})
+	assert.EqualError(t, err, "invalid cursor")
+
+	// Menghapus transfer mengembalikan alokasi ke budget asal
+	require.NoError(t, transactionService.DeleteTransaction(user.ID, transfer.ID))
+	from, err = budgetRepo.FindByID(groceries.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(100*models.MoneyScale), from.Amount)
+}
+
+func TestDeleteTransactionNeedsBudgetRole(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	owner := createTestUser(t, db)
+	member := createTestUser(t, db)
+	budget := createTestBudget(t, db, owner.ID, 100*models.MoneyScale)
+
+	memberRepo := repositories.NewBudgetMemberRepository(db)
+	transactionService := services.NewTransactionService(db, expenseService, expenseRepo, budgetRepo,
+		repositories.NewBudgetHistoryRepository(db), repositories.NewIncomeRepository(db), repositories.NewTransferRepository(db), memberRepo, repositories.NewBudgetAlertRepository(db))
+
+	require.NoError(t, memberRepo.Create(&models.BudgetMember{ID: uuid.New(), WorkspaceID: budget.WorkspaceID, BudgetID: budget.ID, UserID: member.ID, Role: models.BudgetRoleEditor}))
+	income, err := transactionService.CreateTransaction(member.ID, &requests.CreateTransactionRequest{
+		Type: models.TransactionIncome, BudgetID: &budget.ID, Amount: 20 * models.MoneyScale,
+	})
+	require.NoError(t, err)
+
+	// Anggota yang diturunkan menjadi viewer tidak bisa lagi menghapus transaksinya di budget itu
+	require.NoError(t, db.Model(&models.BudgetMember{}).Where("budget_id = ? AND user_id = ?", budget.ID, member.ID).Update("role", models.BudgetRoleViewer).Error)
+	err = transactionService.DeleteTransaction(member.ID, income.ID)
+	assert.ErrorIs(t, err, services.ErrUnauthorized)
+
+	require.NoError(t, db.Model(&models.BudgetMember{}).Where("budget_id = ? AND user_id = ?", budget.ID, member.ID).Update("role", models.BudgetRoleEditor).Error)
+	require.NoError(t, transactionService.DeleteTransaction(member.ID, income.ID))
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/Alvarras/dompet-g0/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPMatchesRFC6238(t *testing.T) {
+	// Vektor uji RFC 6238 untuk SHA1, dipotong menjadi 6 digit
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "T=%d", unix)
+	}
+
+	now := time.Unix(1234567890, 0)
+	step, ok := utils.ValidateTOTP(secret, "005924", now, 0)
+	assert.True(t, ok)
+	_, ok = utils.ValidateTOTP(secret, "005924", now, step)
+	assert.False(t, ok, "kode yang sama tidak bisa dipakai dua kali")
+
+	uri := utils.TOTPURI("Dompet", "a@example.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Dompet:a@example.com?"), uri)
+	assert.Contains(t, uri, "secret="+secret)
+}
+
+func TestTwoFactorEnrollmentAndLogin(t *testing.T) {
+	db, user, authService, _, _ := setupProfileService(t)
+	twoFactorService := services.NewTwoFactorService(db, repositories.NewUserRepository(db), repositories.NewRecoveryCodeRepository(db), authService, "Dompet")
+
+	setup, err := twoFactorService.Setup(user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, setup.OTPAuthURI, "secret="+setup.Secret)
+
+	// Belum aktif sebelum kode pertama dikonfirmasi
+	session, err := authService.Login(&requests.LoginRequest{Email: user.Email, Password: "password123"}, testIP, "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, session.Token)
+
+	_, err = twoFactorService.Enable(user.ID, &requests.EnableTwoFactorRequest{Code: "000000"})
+	assert.EqualError(t, err, "invalid code")
+
+	step := utils.TOTPStep(time.Now())
+	code, err := utils.TOTPCode(setup.Secret, step)
+	require.NoError(t, err)
+	enabled, err := twoFactorService.Enable(user.ID, &requests.EnableTwoFactorRequest{Code: code})
+	require.NoError(t, err)
+	require.Len(t, enabled.RecoveryCodes, 10)
+
+	_, err = twoFactorService.Setup(user.ID)
+	assert.EqualError(t, err, "two-factor authentication already enabled")
+
+	pending, err := authService.Login(&requests.LoginRequest{Email: user.Email, Password: "password123"}, testIP, "")
+	require.NoError(t, err)
+	assert.True(t, pending.MFARequired)
+	assert.Empty(t, pending.Token, "token sesi baru terbit setelah faktor kedua")
+	// Token mfa punya audience sendiri dan tidak diterima sebagai access token
+	_, err = utils.ValidateToken(pending.MFAToken, testKeys(tokenTestSecret))
+	assert.Error(t, err)
+	claims, err := utils.ValidateMFAToken(pending.MFAToken, testKeys(tokenTestSecret))
+	require.NoError(t, err)
+	assert.Equal(t, []string{utils.MFATokenAudience}, []string(claims.Audience))
+
+	_, err = authService.LoginMFA(&requests.MFALoginRequest{MFAToken: pending.MFAToken, Code: code}, testIP, "")
+	assert.EqualError(t, err, "invalid code", "kode TOTP yang sudah dipakai ditolak")
+
+	// Kode pemulihan boleh diketik ulang dengan huruf besar
+	recovery := strings.ToUpper(enabled.RecoveryCodes[0])
+	session, err = authService.LoginMFA(&requests.MFALoginRequest{MFAToken: pending.MFAToken, Code: recovery}, testIP, "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, session.Token)
+	assert.NotEmpty(t, session.RefreshToken)
+
+	_, err = authService.LoginMFA(&requests.MFALoginRequest{MFAToken: pending.MFAToken, Code: recovery}, testIP, "")
+	assert.EqualError(t, err, "invalid mfa token", "token mfa hanya bisa dipakai sekali")
+
+	_, err = authService.LoginMFA(&requests.MFALoginRequest{MFAToken: session.Token, Code: recovery}, testIP, "")
+	assert.EqualError(t, err, "invalid mfa token", "access token biasa bukan token mfa")
+
+	pending, err = authService.Login(&requests.LoginRequest{Email: user.Email, Password: "password123"}, testIP, "")
+	require.NoError(t, err)
+	_, err = authService.LoginMFA(&requests.MFALoginRequest{MFAToken: pending.MFAToken, Code: recovery}, testIP, "")
+	assert.EqualError(t, err, "invalid code", "kode pemulihan hanya bisa dipakai sekali")
+
+	next, err := utils.TOTPCode(setup.Secret, step+1)
+	require.NoError(t, err)
+	_, err = authService.LoginMFA(&requests.MFALoginRequest{MFAToken: pending.MFAToken, Code: next}, testIP, "")
+	require.NoError(t, err)
+
+	status, err := twoFactorService.GetStatus(user.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.EqualValues(t, 9, status.RecoveryCodesLeft)
+
+	err = twoFactorService.Disable(user.ID, &requests.DisableTwoFactorRequest{Password: "salah", Code: enabled.RecoveryCodes[1]}, testIP, "")
+	assert.EqualError(t, err, "invalid password")
+	require.NoError(t, twoFactorService.Disable(user.ID, &requests.DisableTwoFactorRequest{Password: "password123", Code: enabled.RecoveryCodes[1]}, testIP, ""))
+
+	session, err = authService.Login(&requests.LoginRequest{Email: user.Email, Password: "password123"}, testIP, "")
+	require.NoError(t, err)
+	assert.False(t, session.MFARequired)
+	assert.NotEmpty(t, session.Token)
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Alvarras/dompet-g0/internal/dtos/requests"
+	"github.com/Alvarras/dompet-g0/internal/models"
+	"github.com/Alvarras/dompet-g0/internal/repositories"
+	"github.com/Alvarras/dompet-g0/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceIsolation(t *testing.T) {
+	db, expenseService, budgetRepo, expenseRepo := setupExpenseService(t)
+	workspaceRepo := repositories.NewWorkspaceRepository(db)
+	memberRepo := repositories.NewBudgetMemberRepository(db)
+	workspaceService := services.NewWorkspaceService(db, workspaceRepo, repositories.NewWorkspaceMemberRepository(db), memberRepo, repositories.NewUserRepository(db))
+	budgetService := services.NewBudgetService(db, budgetRepo, expenseRepo, repositories.NewBudgetHistoryRepository(db), memberRepo, repositories.NewBudgetAlertRepository(db))
+
+	owner := createTestUser(t, db)
+	editor := createTestUser(t, db)
+	viewer := createTestUser(t, db)
+	outsider := createTestUser(t, db)
+
+	scope := func(userID, workspaceID uuid.UUID) services.WorkspaceScope {
+		t.Helper()
+		id, role, err := workspaceService.ResolveWorkspace(userID, workspaceID)
+		require.NoError(t, err)
+		return services.WorkspaceScope{ID: id, Role: role}
+	}
+
+	// Tanpa header, permintaan bekerja di workspace pribadi
+	personal := scope(owner.ID, uuid.Nil)
+	assert.Equal(t, owner.ID, personal.ID)
+	assert.Equal(t, models.WorkspaceRoleOwner, personal.Role)
+
+	team, err := workspaceService.CreateWorkspace(owner.ID, &requests.CreateWorkspaceRequest{Name: "Keuangan"})
+	require.NoError(t, err)
+	t.Cleanup(func() { workspaceRepo.WithTx(db).DeleteWithData(team.ID) })
+
+	_, err = workspaceService.AddMember(owner.ID, team.ID, &requests.AddWorkspaceMemberRequest{Email: editor.Email, Role: models.WorkspaceRoleEditor})
+	require.NoError(t, err)
+	_, err = workspaceService.AddMember(owner.ID, team.ID, &requests.AddWorkspaceMemberRequest{Email: viewer.Email, Role: models.WorkspaceRoleViewer})
+	require.NoError(t, err)
+	_, err = workspaceService.AddMember(editor.ID, team.ID, &requests.AddWorkspaceMemberRequest{Email: outsider.Email, Role: models.WorkspaceRoleViewer})
+	assert.EqualError(t, err, "unauthorized", "hanya pemilik yang bisa menambah anggota")
+	_, err = workspaceService.AddMember(owner.ID, personal.ID, &requests.AddWorkspaceMemberRequest{Email: outsider.Email, Role: models.WorkspaceRoleViewer})
+	assert.EqualError(t, err, "personal workspaces cannot be shared")
+
+	_, _, err = workspaceService.ResolveWorkspace(outsider.ID, team.ID)
+	assert.EqualError(t, err, "workspace not found")
+
+	ownerTeam := scope(owner.ID, team.ID)
+	editorTeam := scope(editor.ID, team.ID)
+	viewerTeam := scope(viewer.ID, team.ID)
+
+	teamBudget, err := budgetService.InWorkspace(ownerTeam).CreateBudget(owner.ID, &requests.CreateBudgetRequest{Name: "Operasional", Amount: 1000 * models.MoneyScale})
+	require.NoError(t, err)
+	personalBudget, err := budgetService.InWorkspace(personal).CreateBudget(owner.ID, &requests.CreateBudgetRequest{Name: "Pribadi", Amount: 1000 * models.MoneyScale})
+	require.NoError(t, err)
+	_, err = budgetService.InWorkspace(viewerTeam).CreateBudget(viewer.ID, &requests.CreateBudgetRequest{Name: "Viewer", Amount: models.MoneyScale})
+	assert.EqualError(t, err, "unauthorized", "viewer tidak bisa membuat budget")
+
+	// Anggota workspace melihat semua budget workspace, dan hanya itu
+	listed, err := budgetService.InWorkspace(editorTeam).GetBudgets(editor.ID)
+	require.NoError(t, err)
+	require.Len(t, listed.Budgets, 1)
+	assert.Equal(t, teamBudget.ID, listed.Budgets[0].ID)
+	assert.Equal(t, models.BudgetRoleEditor, listed.Budgets[0].Role)
+	listed, err = budgetService.InWorkspace(personal).GetBudgets(owner.ID)
+	require.NoError(t, err)
+	require.Len(t, listed.Budgets, 1)
+	assert.Equal(t, personalBudget.ID, listed.Budgets[0].ID)
+
+	spent, err := expenseService.InWorkspace(editorTeam).CreateExpense(editor.ID, &requests.CreateExpenseRequest{BudgetID: teamBudget.ID, Amount: 100 * models.MoneyScale, Tags: []string{"kantor"}})
+	require.NoError(t, err)
+	_, err = expenseService.InWorkspace(viewerTeam).CreateExpense(viewer.ID, &requests.CreateExpenseRequest{BudgetID: teamBudget.ID, Amount: models.MoneyScale})
+	assert.EqualError(t, err, "unauthorized")
+
+	// Budget workspace lain tidak terlihat, bahkan oleh pemiliknya
+	_, err = expenseService.InWorkspace(ownerTeam).CreateExpense(owner.ID, &requests.CreateExpenseRequest{BudgetID: personalBudget.ID, Amount: models.MoneyScale})
+	assert.EqualError(t, err, "budget not found")
+	_, err = budgetRepo.InWorkspace(team.ID).FindByID(personalBudget.ID)
+	assert.Error(t, err)
+	_, err = expenseRepo.InWorkspace(personal.ID).FindByID(spent.ID)
+	assert.Error(t, err)
+	assert.Error(t, budgetService.InWorkspace(ownerTeam).DeleteBudget(owner.ID, personalBudget.ID))
+
+	expenses, err := expenseService.InWorkspace(viewerTeam).GetExpenses(viewer.ID, &requests.ExpenseListQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, expenses.Total)
+	assert.Equal(t, editor.ID, expenses.Expenses[0].SpentBy)
+	expenses, err = expenseService.InWorkspace(personal).GetExpenses(owner.ID, &requests.ExpenseListQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, expenses.Total)
+
+	// Tag dicatat di workspace tempat pengeluaran dibuat
+	stored, err := expenseRepo.FindByID(spent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, team.ID, stored.WorkspaceID)
+	require.Len(t, stored.Tags, 1)
+	assert.Equal(t, team.ID, stored.Tags[0].WorkspaceID)
+
+	// Pengguna yang diundang ke satu budget masuk sebagai tamu
+	require.NoError(t, memberRepo.Create(&models.BudgetMember{ID: uuid.New(), WorkspaceID: team.ID, BudgetID: teamBudget.ID, UserID: outsider.ID, Role: models.BudgetRoleViewer}))
+	guest := scope(outsider.ID, team.ID)
+	assert.Equal(t, models.WorkspaceRoleGuest, guest.Role)
+	workspaces, err := workspaceService.GetWorkspaces(outsider.ID)
+	require.NoError(t, err)
+	require.Len(t, workspaces.Workspaces, 2)
+	assert.True(t, workspaces.Workspaces[0].Personal)
+	assert.Equal(t, models.WorkspaceRoleGuest, workspaces.Workspaces[1].Role)
+
+	_, err = workspaceService.UpdateMemberRole(owner.ID, team.ID, owner.ID, &requests.UpdateWorkspaceMemberRequest{Role: models.WorkspaceRoleEditor})
+	assert.EqualError(t, err, "workspace needs an owner")
+	assert.EqualError(t, workspaceService.RemoveMember(editor.ID, team.ID, viewer.ID), "unauthorized")
+	require.NoError(t, workspaceService.RemoveMember(viewer.ID, team.ID, viewer.ID), "anggota boleh keluar sendiri")
+	_, _, err = workspaceService.ResolveWorkspace(viewer.ID, team.ID)
+	assert.EqualError(t, err, "workspace not found")
+
+	assert.EqualError(t, workspaceService.DeleteWorkspace(owner.ID, team.ID), "workspace is not empty")
+	assert.EqualError(t, workspaceService.DeleteWorkspace(owner.ID, personal.ID), "cannot delete a personal workspace")
+}